@@ -20,11 +20,18 @@ from queue import Queue
 import time
 import hmac
 import hashlib
+import math
+import getpass
+import urllib.request
 
 # Configuration
 HOST = "127.0.0.1"
 PORT = 22000
 BOOTSTRAP_TOKEN = "VIBE_BLENDER_BOOTSTRAP_SECRET" # Unique token for Blender
+ACTIVITY_URL = "http://127.0.0.1:8080/editor/activity"
+ACTIVITY_REFRESH = 2.0 # Re-report ongoing activity inside the 5s human lease
+ACTIVITY_IDLE = 2.0    # Quiet time after which the human's objects are released
+REMOTE_ECHO = 0.5      # Depsgraph updates this soon after a bridge mutation are its echo
 
 # State
 _session_token = ""
@@ -36,13 +43,341 @@ _path_whitelist = {
     "/health", "/handshake", "/metrics", "/object/lock", "/panic", 
     "/preflight/run", "/export", "/camera/set", "/camera/get",
     "/selection/set", "/material/update", "/mesh/mutate", "/state/get",
-    "/playback/control"
+    "/playback/control", "/batch/apply", "/locks/sync", "/hierarchy/get",
+    "/object/delete", "/transform/set", "/undo"
 }
 
+# Lock flags mirrored from the orchestrator's lock table. Each /locks/sync
+# replaces them whole; the digest is echoed on /health so the orchestrator can
+# tell when they are stale. Guarded by _state_lock. Flagged objects carry the
+# label in their "vibe_lock" custom property.
+_lock_flags = {}
+_lock_digest = ""
+LOCK_PROPERTY = "vibe_lock"
+
+# Undo journal: token -> callable that puts the scene back. Only touched on
+# the main thread.
+_undo_journal = {}
+_undo_seq = 0
+
 def compute_hmac(key, data):
     return hmac.new(key.encode(), data.encode(), hashlib.sha256).hexdigest()
 
+def _call_on_main_thread(fn, timeout=5.0):
+    """Runs fn from the timer queue and waits for its result. bpy must never be
+    touched from the listener thread."""
+    done = threading.Event()
+    box = {}
+    def run():
+        try:
+            box["result"] = fn()
+        except Exception as e:
+            box["error"] = str(e)
+        finally:
+            done.set()
+    _command_queue.put(run)
+    if not done.wait(timeout):
+        raise TimeoutError("Engine Busy: Main Thread Timeout")
+    if "error" in box:
+        raise RuntimeError(box["error"])
+    return box.get("result")
+
+def _scene_hash(objects):
+    # The canonical scene hash both adapters compute: sha256 over the sorted
+    # "uuid|parent" lines. Transforms are left out; their bases differ.
+    lines = sorted(f"{o.name}|{o.parent.name if o.parent else ''}\n" for o in objects)
+    return hashlib.sha256("".join(lines).encode()).hexdigest()
+
+def _collection_depth(coll, seen=()):
+    # 1 for a collection instance, one more per collection instance nested in it
+    if coll.name in seen:
+        return 1
+    inner = [_collection_depth(o.instance_collection, seen + (coll.name,)) for o in coll.all_objects
+             if o.instance_type == 'COLLECTION' and o.instance_collection is not None]
+    return 1 + max(inner, default=0)
+
+def _identity(obj):
+    # An object's identity as the orchestrator's ObjectKind, matching Unity's
+    # prefab identity: data linked from a library is the definition
+    # (PREFAB_DEF); library overrides and collection instances are
+    # PREFAB_INSTANCE at the depth of their source chain (1 for a plain
+    # instance, one more per nested level); anything else is OBJECT at depth 0.
+    if obj.library is not None:
+        return {"uuid": obj.name, "kind": "PREFAB_DEF", "prefab_depth": 0}
+    depth = 0
+    ref = obj.override_library.reference if obj.override_library else None
+    while ref is not None:
+        depth += 1
+        ref = ref.override_library.reference if ref.override_library else None
+    if obj.instance_type == 'COLLECTION' and obj.instance_collection is not None:
+        depth = max(depth, _collection_depth(obj.instance_collection))
+    if depth:
+        return {"uuid": obj.name, "kind": "PREFAB_INSTANCE", "prefab_depth": depth}
+    return {"uuid": obj.name, "kind": "OBJECT", "prefab_depth": 0}
+
+def _state():
+    objects = list(bpy.context.scene.objects)
+    return {"hash": _scene_hash(objects), "identities": [_identity(o) for o in objects]}
+
+def _scene_object(uuid):
+    obj = bpy.context.scene.objects.get(uuid) if uuid else None
+    if obj is None:
+        raise KeyError(f"Unknown UUID: {uuid}")
+    return obj
+
+# Each mutation applies its payload and returns the callable that reverses it.
+def _apply_transform(data):
+    obj = _scene_object(data.get("id"))
+    t = data.get("transform") or {}
+    before = (obj.location.copy(), obj.rotation_euler.copy(), obj.scale.copy())
+    if len(t.get("pos") or []) == 3:
+        obj.location = t["pos"]
+    if len(t.get("rot") or []) == 3:
+        obj.rotation_euler = [math.radians(v) for v in t["rot"]]
+    if len(t.get("sca") or []) == 3:
+        obj.scale = t["sca"]
+    def restore():
+        obj.location, obj.rotation_euler, obj.scale = before
+    return restore
+
+_material_inputs = {"color": "Base Color", "roughness": "Roughness", "metallic": "Metallic"}
+
+def _apply_material(data):
+    obj = _scene_object(data.get("id"))
+    mat = obj.active_material
+    node = None
+    if mat is not None and mat.use_nodes:
+        node = next((n for n in mat.node_tree.nodes if n.type == 'BSDF_PRINCIPLED'), None)
+    if node is None:
+        raise ValueError(f"No Principled BSDF material on {obj.name}")
+    props = data.get("properties") or {}
+    before = {}
+    for key, socket in _material_inputs.items():
+        if key not in props:
+            continue
+        inp = node.inputs[socket]
+        before[socket] = tuple(inp.default_value) if key == "color" else inp.default_value
+        value = props[key]
+        if key == "color" and len(value) == 3:
+            value = list(value) + [1.0]
+        inp.default_value = value
+    def restore():
+        for socket, value in before.items():
+            node.inputs[socket].default_value = value
+    return restore
+
+def _apply_batch(data):
+    # All-or-nothing: a failing op reverses the ones already applied.
+    applied = []
+    def rewind():
+        for restore in reversed(applied):
+            restore()
+    try:
+        for op in data.get("ops") or []:
+            path = "/" + (op.get("endpoint") or "").lstrip("/")
+            apply = _mutations.get(path)
+            if apply is None or path in ("/batch/apply", "/object/delete"):
+                raise ValueError(f"Not batchable: {op.get('endpoint')}")
+            applied.append(apply(op.get("payload") or {}))
+    except Exception:
+        rewind()
+        raise
+    return rewind
+
+def _dependent_stack(obj, kind):
+    return obj.constraints if kind == "constraint" else obj.modifiers
+
+def _object_targets(item):
+    # Objects a constraint or modifier points at through its RNA properties
+    for prop in item.bl_rna.properties:
+        if prop.type == 'POINTER':
+            value = getattr(item, prop.identifier, None)
+            if isinstance(value, bpy.types.Object):
+                yield value
+
+def _delete_dependent(owner, kind, item):
+    stack = _dependent_stack(owner, kind)
+    index = list(stack).index(item)
+    name, type_ = item.name, item.type
+    props = {p.identifier: getattr(item, p.identifier) for p in item.bl_rna.properties
+             if not p.is_readonly and p.identifier not in ("name", "type", "rna_type")}
+    stack.remove(item)
+    def restore():
+        # The owner is looked up again: it may itself have been relinked.
+        obj = bpy.data.objects[owner.name]
+        stack = _dependent_stack(obj, kind)
+        back = stack.new(type_) if kind == "constraint" else stack.new(name, type_)
+        back.name = name
+        for key, value in props.items():
+            try:
+                setattr(back, key, value)
+            except (AttributeError, TypeError, ValueError):
+                pass
+        if hasattr(stack, "move"):
+            stack.move(len(stack) - 1, index)
+    return restore
+
+def _delete_object(obj):
+    collections = list(obj.users_collection)
+    fake_user = obj.use_fake_user
+    for c in collections:
+        c.objects.unlink(obj)
+    obj.use_fake_user = True
+    def restore():
+        for c in collections:
+            if obj.name not in c.objects:
+                c.objects.link(obj)
+        obj.use_fake_user = fake_user
+    return restore
+
+def _apply_delete(data):
+    # Deletes a closure atomically (PATHOLOGICAL_EDGE_CASES.md §3). Every id is
+    # resolved before anything changes: objects by name, dependents as
+    # "<owner>/constraint|modifier/<name>". Dependents are removed and
+    # recreated from their RNA properties on undo; objects are unlinked from
+    # every collection and kept alive by a fake user, so undo relinks the same
+    # datablocks.
+    dependents, objects = [], []
+    for uuid in data.get("uuids") or []:
+        parts = uuid.split("/")
+        if len(parts) == 3 and parts[1] in ("constraint", "modifier"):
+            owner = _scene_object(parts[0])
+            item = _dependent_stack(owner, parts[1]).get(parts[2])
+            if item is None:
+                raise KeyError(f"Unknown dependent: {uuid}")
+            dependents.append((owner, parts[1], item))
+        else:
+            objects.append(_scene_object(uuid))
+    applied = []
+    def rewind():
+        for restore in reversed(applied):
+            restore()
+    try:
+        for dep in dependents:
+            applied.append(_delete_dependent(*dep))
+        for obj in objects:
+            applied.append(_delete_object(obj))
+    except Exception:
+        rewind()
+        raise
+    return rewind
+
+_mutations = {
+    "/transform/set": _apply_transform,
+    "/material/update": _apply_material,
+    "/batch/apply": _apply_batch,
+    "/object/delete": _apply_delete,
+}
+
+def _hierarchy():
+    # Parent edges for the orchestrator's central DAG mirror, covering the
+    # objects of the active scene (the same set the scene hash covers), with
+    # each object's identity and the constraints and modifiers elsewhere that target each object
+    # ("<owner>/constraint/<name>", "<owner>/modifier/<name>")
+    objects = list(bpy.context.scene.objects)
+    dependents = {}
+    for o in objects:
+        for kind in ("constraint", "modifier"):
+            for item in _dependent_stack(o, kind):
+                for target in _object_targets(item):
+                    if target != o:
+                        dependents.setdefault(target.name, set()).add(f"{o.name}/{kind}/{item.name}")
+    nodes = []
+    for o in objects:
+        node = _identity(o)
+        node["parent"] = o.parent.name if o.parent else ""
+        if o.name in dependents:
+            node["dependents"] = sorted(dependents[o.name])
+        nodes.append(node)
+    return {"nodes": nodes}
+
+def _mutated_ids(data):
+    ids = [data.get("id")] + list(data.get("uuids") or [])
+    for op in data.get("ops") or []:
+        ids.append((op.get("payload") or {}).get("id"))
+    return [i for i in ids if i]
+
+# Editor activity (CONFLICT_RESOLUTION_POLICY.md §3.D): what the human is doing
+# is reported to the orchestrator's control plane, which holds a 5s
+# HUMAN_ACTIVE lease per report. Ongoing activity is re-reported inside that
+# lease; once the human has been quiet for ACTIVITY_IDLE the objects are
+# reported idle. State is only touched on the main thread; reports go out in
+# order from one worker thread.
+_activity = {"kind": "idle", "uuids": [], "sent": 0.0, "seen": 0.0, "selection": []}
+_activity_outbox = Queue()
+_remote_touched = {} # uuid -> when the bridge itself last mutated it
+
+def _activity_worker():
+    while True:
+        kind, uuids = _activity_outbox.get()
+        with _state_lock:
+            token = _session_token if _session_token != "" else BOOTSTRAP_TOKEN
+        body = json.dumps({"engine": "blender", "kind": kind, "uuids": uuids, "user": getpass.getuser()}).encode()
+        req = urllib.request.Request(ACTIVITY_URL, data=body, method="POST", headers={"Content-Type": "application/json", "X-Vibe-Token": token})
+        try:
+            urllib.request.urlopen(req, timeout=2).close()
+        except Exception as e:
+            print(f"VibeSync: Editor activity not reported: {e}")
+
+def _note_activity(kind, uuids):
+    # Unchanged activity is re-reported only once per ACTIVITY_REFRESH; objects
+    # the human has moved away from are released.
+    now = time.time()
+    uuids = sorted(set(uuids))
+    a = _activity
+    left = [u for u in a["uuids"] if u not in uuids]
+    if a["kind"] != "idle" and left:
+        _activity_outbox.put(("idle", left))
+    if kind != a["kind"] or uuids != a["uuids"] or now - a["sent"] >= ACTIVITY_REFRESH:
+        _activity_outbox.put((kind, uuids))
+        a["sent"] = now
+    a["kind"], a["uuids"], a["seen"] = kind, uuids, now
+
+def _tick_activity():
+    a = _activity
+    if a["kind"] != "idle" and time.time() - a["seen"] >= ACTIVITY_IDLE:
+        _activity_outbox.put(("idle", a["uuids"]))
+        a["kind"], a["uuids"] = "idle", []
+
+@bpy.app.handlers.persistent
+def _on_depsgraph_update(scene, depsgraph):
+    selected = sorted(o.name for o in scene.objects if o.select_get())
+    if selected != _activity["selection"]:
+        _activity["selection"] = selected
+        if selected:
+            _note_activity("selection", selected)
+
+    # Transform updates are gizmo drags; geometry, shading and material
+    # updates are property edits. The bridge's own mutations are skipped.
+    now = time.time()
+    dragged, edited = set(), set()
+    for update in depsgraph.updates:
+        ident = getattr(update.id, "original", update.id)
+        if isinstance(ident, bpy.types.Material):
+            edited.update(o.name for o in scene.objects if any(s.material == ident for s in o.material_slots))
+        elif isinstance(ident, bpy.types.Object):
+            if update.is_updated_transform:
+                dragged.add(ident.name)
+            elif update.is_updated_geometry or update.is_updated_shading:
+                edited.add(ident.name)
+    dragged = {u for u in dragged if now - _remote_touched.get(u, 0) >= REMOTE_ECHO}
+    edited = {u for u in edited if now - _remote_touched.get(u, 0) >= REMOTE_ECHO}
+    if dragged or edited:
+        _note_activity("gizmo_drag" if dragged else "property_edit", dragged | edited)
+
 class VibeRequestHandler(http.server.BaseHTTPRequestHandler):
+    def _send_json(self, status, payload):
+        self.send_response(status)
+        self.send_header("Content-Type", "application/json")
+        self.end_headers()
+        self.wfile.write(json.dumps(payload).encode())
+
+    def _reply_from_main_thread(self, fn):
+        try:
+            self._send_json(200, _call_on_main_thread(fn))
+        except Exception as e:
+            self._send_json(409, {"error": str(e)})
+
     def do_GET(self):
         # Path Whitelist Check
         if self.path not in _path_whitelist:
@@ -55,8 +390,8 @@ class VibeRequestHandler(http.server.BaseHTTPRequestHandler):
             self.send_header("Content-Type", "application/json")
             self.end_headers()
             with _state_lock:
-                gen = _current_generation
-            self.wfile.write(json.dumps({"status": "ok", "generation": gen}).encode())
+                gen, digest = _current_generation, _lock_digest
+            self.wfile.write(json.dumps({"status": "ok", "generation": gen, "lock_digest": digest}).encode())
         elif self.path == "/camera/get":
             # Simple camera telemetry (mocked for this turn)
             response = {"status": "OK", "pos": [0, 5, -10], "rot": [0, 0, 0]}
@@ -64,10 +399,15 @@ class VibeRequestHandler(http.server.BaseHTTPRequestHandler):
             self.send_header("Content-Type", "application/json")
             self.end_headers()
             self.wfile.write(json.dumps(response).encode())
+        elif self.path == "/state/get":
+            self._reply_from_main_thread(_state)
+        elif self.path == "/hierarchy/get":
+            self._reply_from_main_thread(_hierarchy)
         else:
             self.send_error(404)
 
     def do_POST(self):
+        global _current_generation, _session_token, _lock_flags, _lock_digest
         # 1. Path Whitelist Check
         if self.path not in _path_whitelist:
             self.send_response(403)
@@ -139,7 +479,6 @@ class VibeRequestHandler(http.server.BaseHTTPRequestHandler):
 
         if is_handshake:
             with _state_lock:
-                global _current_generation, _session_token
                 _current_generation += 1
                 try:
                     data = json.loads(body)
@@ -195,14 +534,63 @@ class VibeRequestHandler(http.server.BaseHTTPRequestHandler):
             return
 
         if self.path == "/export":
-            response = {"status": "OK", "meta": {"exporter": "VibeSync"}}
-            self.send_response(200)
-            self.send_header("Content-Type", "application/json")
-            self.end_headers()
-            self.wfile.write(json.dumps(response).encode())
+            # The export hash covers the objects the export writes
+            self._reply_from_main_thread(lambda: {"status": "OK", "meta": {"exporter": "VibeSync"}, "hash": _scene_hash(bpy.context.scene.objects)})
+            return
+
+        if self.path == "/locks/sync":
+            data = json.loads(body or "{}")
+            flags = {}
+            for f in data.get("locks") or []:
+                # A direct lock outranks the same object covered through an ancestor
+                if f.get("uuid") not in flags or not f.get("via"):
+                    flags[f.get("uuid")] = f
+            digest = data.get("digest", "")
+            with _state_lock:
+                _lock_flags, _lock_digest = flags, digest
+            def apply_flags():
+                now = time.time()
+                for obj in bpy.data.objects:
+                    flag = flags.get(obj.name)
+                    label = f"{flag.get('label', '')} ({flag.get('owner', '')})" if flag else None
+                    if obj.get(LOCK_PROPERTY) == label:
+                        continue
+                    _remote_touched[obj.name] = now
+                    if label is None:
+                        del obj[LOCK_PROPERTY]
+                    else:
+                        obj[LOCK_PROPERTY] = label
+                return {"status": "ok", "digest": digest}
+            self._reply_from_main_thread(apply_flags)
+            return
+
+        if self.path == "/undo":
+            token = json.loads(body or "{}").get("undo_token", "")
+            def undo():
+                restore = _undo_journal.pop(token, None)
+                if restore is None:
+                    raise KeyError(f"Unknown undo token: {token}")
+                restore()
+                return {"status": "undone"}
+            self._reply_from_main_thread(undo)
+            return
+
+        if self.path in _mutations:
+            apply = _mutations[self.path]
+            data = json.loads(body or "{}")
+            def mutate():
+                global _undo_seq
+                for uuid in _mutated_ids(data):
+                    _remote_touched[uuid] = time.time()
+                restore = apply(data)
+                _undo_seq += 1
+                token = f"blender-{_undo_seq}"
+                _undo_journal[token] = restore
+                return {"status": "ok", "undo_token": token}
+            self._reply_from_main_thread(mutate)
             return
 
-        if self.path in ["/camera/set", "/selection/set", "/material/update"]:
+        if self.path in ["/camera/set", "/selection/set"]:
             _command_queue.put((self.path, body))
             self.send_response(200)
             self.end_headers()
@@ -228,23 +616,33 @@ def run_server():
 # Timer for main thread execution
 def process_queue():
     while not _command_queue.empty():
-        path, body = _command_queue.get()
+        item = _command_queue.get()
+        if callable(item):
+            item()
+            continue
+        path, body = item
         print(f"VibeSync Command received on Blender Main Thread: {path}")
         # TODO: Implement command dispatching to modules
+    _tick_activity()
     return 0.1 # Run every 0.1 seconds
 
 def register():
     # Start server in background thread
     thread = threading.Thread(target=run_server, daemon=True)
     thread.start()
+    threading.Thread(target=_activity_worker, daemon=True).start()
     
     # Register timer for queue processing
     if not bpy.app.timers.is_registered(process_queue):
         bpy.app.timers.register(process_queue)
+    if _on_depsgraph_update not in bpy.app.handlers.depsgraph_update_post:
+        bpy.app.handlers.depsgraph_update_post.append(_on_depsgraph_update)
 
 def unregister():
     if bpy.app.timers.is_registered(process_queue):
         bpy.app.timers.unregister(process_queue)
+    if _on_depsgraph_update in bpy.app.handlers.depsgraph_update_post:
+        bpy.app.handlers.depsgraph_update_post.remove(_on_depsgraph_update)
 
 if __name__ == "__main__":
     register()
@@ -0,0 +1,105 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Editor Activity (CONFLICT_RESOLUTION_POLICY.md §3.D)
+//
+// Adapters report what the human is doing in the editor (selecting, dragging
+// a gizmo, editing a property) to the control plane, and the orchestrator takes
+// HUMAN_ACTIVE locks on their behalf. Adapters keep reporting while the human
+// is active; each report refreshes the lease, so a lock lapses
+// humanActivityTTL after the human stops. Any provisional AI work on those
+// objects is rolled back and waits for the lock to clear.
+const humanActivityTTL = 5 * time.Second
+
+var editorActivityKinds = map[string]bool{"selection": true, "gizmo_drag": true, "property_edit": true, "idle": true}
+
+// humanOwner names the lock owner for a human in a given editor.
+func humanOwner(a EditorActivity) string {
+	if a.User != "" { return "human:" + a.Engine + ":" + a.User }
+	return "human:" + a.Engine
+}
+
+// reportEditorActivity applies one adapter report and returns what it did.
+func reportEditorActivity(ctx context.Context, a EditorActivity) (map[string]interface{}, error) {
+	stateMu.RLock(); _, known := engines[a.Engine]; stateMu.RUnlock()
+	if !known { return nil, fmt.Errorf("ACTIVITY_INVALID: unknown engine %q", a.Engine) }
+	if !editorActivityKinds[a.Kind] { return nil, fmt.Errorf("ACTIVITY_INVALID: unknown activity %q", a.Kind) }
+	owner := humanOwner(a)
+
+	if a.Kind == "idle" {
+		for _, id := range a.UUIDs {
+			lockMu.RLock(); l, ok := lockTable[id]; lease := ""; if ok && l.Owner == owner { lease = l.Lease }; lockMu.RUnlock()
+			if lease != "" { releaseLock(ReleaseLockArgs{UUID: id, Owner: owner, Lease: lease}) }
+		}
+		return map[string]interface{}{"status": "RELEASED", "uuids": a.UUIDs}, nil
+	}
+
+	var fresh, contested []string
+	var preempted []VibeLock
+	for _, id := range a.UUIDs {
+		l, p, err := acquireHumanLock(id, owner, humanActivityTTL)
+		if err != nil { log.Printf("✋ Human Supremacy: %s not locked for %s: %v", id, owner, err); contested = append(contested, id); continue }
+		preempted = append(preempted, p...)
+		if l.Renewals == 0 { fresh = append(fresh, id) }
+	}
+	rolled := rollbackClaimsOn(ctx, a.UUIDs)
+	dropped := dropBufferedIntents(a.UUIDs)
+
+	if len(fresh) > 0 || len(rolled) > 0 || len(dropped) > 0 || len(preempted) > 0 {
+		journalOperation(map[string]interface{}{
+			"type":        "human_activity",
+			"engine":      a.Engine,
+			"kind":        a.Kind,
+			"owner":       owner,
+			"locked":      fresh,
+			"preempted":   preempted,
+			"rolled_back": rolled,
+			"withdrawn":   len(dropped),
+		})
+	}
+	if len(rolled) > 0 || len(dropped) > 0 || len(preempted) > 0 {
+		log.Printf("✋ Human Supremacy: %s is active on %v; %d AI mutations rolled back, %d withdrawn", owner, a.UUIDs, len(rolled), len(dropped))
+		dispatchVibeEvent(LevelWarn, "human_active", "", string(PhaseWaitHuman), map[string]interface{}{"owner": owner, "uuids": a.UUIDs, "rolled_back": rolled, "withdrawn": len(dropped), "preempted": len(preempted)})
+	}
+	return map[string]interface{}{"status": "HUMAN_ACTIVE", "uuids": a.UUIDs, "expires_in_ms": humanActivityTTL.Milliseconds(), "rolled_back": rolled, "held_by_other_human": contested}, nil
+}
+
+// handleEditorActivity is the control plane endpoint adapters report to. The
+// adapter proves itself with its current session token.
+func handleEditorActivity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost { http.Error(w, "POST required", http.StatusMethodNotAllowed); return }
+	var a EditorActivity
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil { http.Error(w, err.Error(), http.StatusBadRequest); return }
+
+	stateMu.RLock(); e, ok := engines[a.Engine]; token := ""; if ok { token = e.Token }; stateMu.RUnlock()
+	if !ok || token == "" || r.Header.Get("X-Vibe-Token") != token {
+		http.Error(w, "UNAUTHORIZED: adapter token required", http.StatusUnauthorized)
+		return
+	}
+	res, err := reportEditorActivity(r.Context(), a)
+	if err != nil { http.Error(w, err.Error(), http.StatusBadRequest); return }
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,182 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Agent Key Registry
+//
+// Each Foreman and Operator of the 5-agent model signs its envelopes with its
+// own key. The signature covers the canonical envelope (including
+// InstructionHash and PlanHash), so provenance is proven rather than claimed.
+// Keys are provisioned by humans in AgentKeyFile; the file is re-read whenever
+// it changes, so a revocation takes effect on the next envelope. Humans hold
+// keys of their own (role Human) to sign approvals and overrides; those keys
+// cannot sign envelopes, and agent keys cannot sign human decisions.
+const AgentKeyFile = PersistenceDir + "/agent_keys.json"
+
+var (
+	agentKeys        = make(map[string]AgentKey) // agent ID -> key
+	agentKeysModTime time.Time
+	agentMu          sync.RWMutex
+)
+
+func loadAgentKeys() error {
+	info, err := os.Stat(AgentKeyFile)
+	if err != nil { return err }
+
+	agentMu.RLock()
+	unchanged := info.ModTime().Equal(agentKeysModTime)
+	agentMu.RUnlock()
+	if unchanged { return nil }
+
+	data, err := os.ReadFile(AgentKeyFile)
+	if err != nil { return err }
+	var list []AgentKey
+	if err := json.Unmarshal(data, &list); err != nil { return fmt.Errorf("AGENT_KEYS_PARSE_ERROR: %v", err) }
+	keys := make(map[string]AgentKey, len(list))
+	for _, k := range list {
+		if k.AgentID == "" || k.Secret == "" { return fmt.Errorf("AGENT_KEYS_INVALID: every agent requires an agent_id and secret") }
+		if k.Role != RoleForeman && k.Role != RoleOperator && k.Role != RoleHuman { return fmt.Errorf("AGENT_KEYS_INVALID: %s has unknown role %q", k.AgentID, k.Role) }
+		keys[k.AgentID] = k
+	}
+
+	agentMu.Lock()
+	agentKeys, agentKeysModTime = keys, info.ModTime()
+	agentMu.Unlock()
+
+	log.Printf("🔑 Agent Registry: Loaded %d agent key(s)", len(keys))
+	return nil
+}
+
+// canonicalEnvelope is the byte string an agent signs: the envelope's JSON
+// encoding with the signature itself blanked. encoding/json emits struct fields
+// in declaration order and map keys sorted, so the form is stable.
+func canonicalEnvelope(env IntentEnvelope) []byte {
+	env.Signature = ""
+	data, _ := json.Marshal(env)
+	return data
+}
+
+func signEnvelope(secret string, env IntentEnvelope) string {
+	return hmacHex(secret, canonicalEnvelope(env))
+}
+
+func hmacHex(secret string, data []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// activeAgentKey returns the registry entry for id if it exists and has not
+// been revoked.
+func activeAgentKey(id string) (AgentKey, error) {
+	agentMu.RLock()
+	key, ok := agentKeys[id]
+	agentMu.RUnlock()
+	if !ok { return AgentKey{}, fmt.Errorf("UNKNOWN_AGENT: %s is not in the agent key registry", id) }
+	if key.Revoked { return AgentKey{}, fmt.Errorf("AGENT_REVOKED: %s", id) }
+	return key, nil
+}
+
+// canonicalDecision is the byte string a human signs for a decision: the
+// tool arguments as JSON with the signature dropped and the action named.
+func canonicalDecision(action string, args interface{}) []byte {
+	var m map[string]interface{}
+	data, _ := json.Marshal(args)
+	json.Unmarshal(data, &m)
+	if m == nil { m = map[string]interface{}{} }
+	delete(m, "signature")
+	m["action"] = action
+	out, _ := json.Marshal(m)
+	return out
+}
+
+// verifyHuman authenticates the approver of a human decision: a registered,
+// unrevoked Human key whose HMAC over the decision is signature.
+func verifyHuman(approver, signature, action string, args interface{}) error {
+	if approver == "" { return fmt.Errorf("APPROVER_IDENTITY_REQUIRED") }
+	if err := loadAgentKeys(); err != nil && !os.IsNotExist(err) {
+		log.Printf("🚨 Agent Registry: %v (keeping previous keys)", err)
+	}
+	key, err := activeAgentKey(approver)
+	if err != nil { return err }
+	if key.Role != RoleHuman { return fmt.Errorf("APPROVER_NOT_HUMAN: %s holds a %s key", approver, key.Role) }
+	if signature == "" || !hmac.Equal([]byte(signature), []byte(hmacHex(key.Secret, canonicalDecision(action, args)))) {
+		return fmt.Errorf("APPROVER_SIGNATURE_INVALID: %s decision was not signed by %s", action, approver)
+	}
+	return nil
+}
+
+// verifyAgentSignature authenticates a request an agent signed with its own
+// key: agentID is registered and unrevoked, and signature is its HMAC over the
+// decision.
+func verifyAgentSignature(agentID, signature, action string, args interface{}) error {
+	if agentID == "" || signature == "" { return fmt.Errorf("SIGNATURE_REQUIRED: %s must carry agent_id and signature", action) }
+	key, err := activeAgentKey(agentID)
+	if err != nil { return err }
+	if !hmac.Equal([]byte(signature), []byte(hmacHex(key.Secret, canonicalDecision(action, args)))) {
+		return fmt.Errorf("SIGNATURE_INVALID: %s was not signed by %s", action, agentID)
+	}
+	return nil
+}
+
+// verifyEnvelope returns the identity of the agent that signed the envelope.
+func verifyEnvelope(env IntentEnvelope) (AgentIdentity, error) {
+	if err := loadAgentKeys(); err != nil && !os.IsNotExist(err) {
+		log.Printf("🚨 Agent Registry: %v (keeping previous keys)", err)
+	}
+	if env.AgentID == "" || env.Signature == "" { return AgentIdentity{}, fmt.Errorf("SIGNATURE_REQUIRED: Envelope must carry agent_id and signature") }
+
+	key, err := activeAgentKey(env.AgentID)
+	if err != nil { return AgentIdentity{}, err }
+	if key.Role == RoleHuman { return AgentIdentity{}, fmt.Errorf("SIGNATURE_INVALID: %s is a human key and cannot sign envelopes", env.AgentID) }
+
+	if !hmac.Equal([]byte(env.Signature), []byte(signEnvelope(key.Secret, env))) {
+		return AgentIdentity{}, fmt.Errorf("SIGNATURE_INVALID: Envelope was not signed by %s", env.AgentID)
+	}
+	return AgentIdentity{AgentID: key.AgentID, Role: key.Role, Engine: key.Engine}, nil
+}
+
+func checkSignature(env IntentEnvelope) error {
+	_, err := verifyEnvelope(env)
+	return err
+}
+
+func list_agents(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+	loadAgentKeys()
+	agentMu.RLock()
+	out := make([]map[string]interface{}, 0, len(agentKeys))
+	for _, k := range agentKeys {
+		out = append(out, map[string]interface{}{"agent_id": k.AgentID, "role": k.Role, "engine": k.Engine, "revoked": k.Revoked})
+	}
+	agentMu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i]["agent_id"].(string) < out[j]["agent_id"].(string) })
+	return wrapForensicResult(out), nil, nil
+}
@@ -0,0 +1,172 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Human Approval Queue
+//
+// An intent that policy holds for review waits here on its own; the engines
+// keep running and unrelated intents keep flowing. Each human decision is
+// signed with the approver's Human key, recorded with their identity and
+// reason, and journaled.
+const ApprovalFile = PersistenceDir + "/approvals.json"
+
+var (
+	approvalQueue = make(map[string]*ApprovalRequest) // intent ID -> request
+	approvalMu    sync.Mutex
+)
+
+func enqueueApproval(rec *IntentRecord, preview DryRunReport) *ApprovalRequest {
+	approvalMu.Lock()
+	defer approvalMu.Unlock()
+	if existing, ok := approvalQueue[rec.ID]; ok { return existing }
+
+	req := &ApprovalRequest{
+		IntentID:          rec.ID,
+		Intent:            rec.Envelope.Intent,
+		Rationale:         rec.Envelope.Rationale,
+		Scope:             rec.Envelope.Scope,
+		Confidence:        rec.Envelope.Confidence,
+		PolicyRule:        rec.Policy.RuleID,
+		PolicyReason:      rec.Policy.Reason,
+		RequiredApprovals: rec.Policy.RequiredApprovals,
+		Predicted:         preview,
+		Decisions:         []ApprovalDecision{},
+		CreatedAt:         time.Now(),
+	}
+	approvalQueue[rec.ID] = req
+	saveApprovalsLocked()
+
+	log.Printf("🙋 Approval Queue: Intent %s awaiting %d approval(s) (rule %s)", rec.ID, req.RequiredApprovals, req.PolicyRule)
+	dispatchVibeEvent(LevelWarn, "approval_required", rec.ID, "HUMAN_REVIEW", map[string]interface{}{"rule": req.PolicyRule, "required": req.RequiredApprovals, "scope": req.Scope})
+	return req
+}
+
+// decideApproval records one human decision. It returns the resulting intent
+// status: APPROVED once enough distinct approvers agreed, REJECTED on the
+// first rejection, or SUBMITTED while more approvals are still needed.
+func decideApproval(args IntentDecisionArgs, approve bool) (IntentStatus, error) {
+	if args.Reason == "" { return "", fmt.Errorf("APPROVAL_REASON_REQUIRED") }
+	action := "approve_intent"
+	if !approve { action = "reject_intent" }
+	if err := verifyHuman(args.Approver, args.Signature, action, args); err != nil { return "", err }
+
+	approvalMu.Lock()
+	req, ok := approvalQueue[args.IntentID]
+	if !ok { approvalMu.Unlock(); return "", fmt.Errorf("NO_PENDING_APPROVAL: Intent %s is not awaiting review", args.IntentID) }
+	for _, d := range req.Decisions {
+		if d.Approver == args.Approver { approvalMu.Unlock(); return "", fmt.Errorf("DUPLICATE_APPROVER: %s already decided on intent %s", args.Approver, args.IntentID) }
+	}
+	req.Decisions = append(req.Decisions, ApprovalDecision{Approver: args.Approver, Approved: approve, Reason: args.Reason, At: time.Now()})
+
+	approvals := 0
+	for _, d := range req.Decisions { if d.Approved { approvals++ } }
+	outcome := IntentSubmitted
+	if !approve {
+		outcome = IntentRejected
+	} else if approvals >= req.RequiredApprovals {
+		outcome = IntentApproved
+	}
+	if outcome != IntentSubmitted { delete(approvalQueue, args.IntentID) }
+	saveApprovalsLocked()
+	approvalMu.Unlock()
+
+	journalOperation(map[string]interface{}{
+		"type":      "approval_decision",
+		"intent":    args.IntentID,
+		"approver":  args.Approver,
+		"approved":  approve,
+		"reason":    args.Reason,
+		"approvals": approvals,
+		"required":  req.RequiredApprovals,
+		"outcome":   outcome,
+	})
+
+	if outcome != IntentSubmitted {
+		if err := transitionIntent(args.IntentID, outcome, fmt.Sprintf("%s by %s: %s", outcome, args.Approver, args.Reason)); err != nil { return "", err }
+	}
+	return outcome, nil
+}
+
+// dropApproval removes a request whose intent left the pipeline some other way
+// (expired, aborted), so reviewers are not asked about dead intents.
+func dropApproval(intentID string) {
+	approvalMu.Lock()
+	defer approvalMu.Unlock()
+	if _, ok := approvalQueue[intentID]; ok {
+		delete(approvalQueue, intentID)
+		saveApprovalsLocked()
+	}
+}
+
+func pendingApprovals() []ApprovalRequest {
+	approvalMu.Lock()
+	defer approvalMu.Unlock()
+	out := make([]ApprovalRequest, 0, len(approvalQueue))
+	for _, r := range approvalQueue { out = append(out, *r) }
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+func saveApprovalsLocked() {
+	data, err := json.MarshalIndent(approvalQueue, "", "  ")
+	if err != nil { return }
+	os.WriteFile(ApprovalFile, data, 0644)
+}
+
+func loadApprovals() {
+	data, err := os.ReadFile(ApprovalFile)
+	if err != nil { return }
+	approvalMu.Lock()
+	defer approvalMu.Unlock()
+	json.Unmarshal(data, &approvalQueue)
+}
+
+func list_pending_approvals(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+	return wrapForensicResult(pendingApprovals()), nil, nil
+}
+
+func decideIntent(args IntentDecisionArgs, approve bool) (*mcp.CallToolResult, any, error) {
+	// lookupIntent applies decay first, so a reviewer can never approve an
+	// intent whose basis has already moved.
+	rec, err := lookupIntent(args.IntentID)
+	if err != nil { return nil, nil, err }
+	txMu.Lock(); status, reason := rec.Status, rec.Reason; txMu.Unlock()
+	if isIntentTerminal(status) { return nil, nil, fmt.Errorf("INTENT_%s: %s", status, reason) }
+
+	outcome, err := decideApproval(args, approve)
+	if err != nil { return nil, nil, err }
+	return wrapForensicResult(outcome), nil, nil
+}
+
+func human_approve_intent(ctx context.Context, req *mcp.CallToolRequest, args IntentDecisionArgs) (*mcp.CallToolResult, any, error) {
+	return decideIntent(args, true)
+}
+
+func human_reject_intent(ctx context.Context, req *mcp.CallToolRequest, args IntentDecisionArgs) (*mcp.CallToolResult, any, error) {
+	return decideIntent(args, false)
+}
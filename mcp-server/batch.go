@@ -0,0 +1,320 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Intent Coalescing (SPECULATIVE_COMMIT_PROTOCOL.md §6)
+//
+// Batchable mutations are not sent when they are requested. They wait in
+// intentBuffer, keyed by UUID, for the batch window of the first one; a
+// mutation of the same UUID and endpoint inside the window is merged into the
+// pending one. Each op carries the conflict claim admitted for it, so an op
+// whose claim loses before the window closes is withdrawn unsent, and the
+// claims of a sent batch point at its undo record. When the window closes, every due mutation goes to each engine
+// as one batch/apply call, the batch is verified with a single combined state
+// hash, and its entries are promoted from PROVISIONAL to FINAL, or rewound
+// and marked ROLLED_BACK, which rejects the intents that submitted them. The
+// batch runs within the remaining time budget of every intent in it.
+const batchSendTimeout = 5 * time.Second
+
+// bufferedIntent is the pending work on one UUID and the provisional WAL
+// entry that describes it. Ops keep their arrival order; only consecutive
+// mutations of the same endpoint by the same claimant are merged.
+type bufferedIntent struct {
+	Entry   *WalEntry
+	Op      string
+	Ops     []EngineOp
+	Claims  []*conflictClaim // Claims[i] was admitted for Ops[i]; nil if the caller staked none
+	Targets []string
+	Merged  int // Later mutations folded into an earlier op
+}
+
+// mergePayload folds src into dst. Nested maps merge key by key; empty values
+// in src (an omitted rotation, say) leave what dst already has.
+func mergePayload(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if sm, ok := v.(map[string]interface{}); ok {
+			if dm, ok := dst[k].(map[string]interface{}); ok { mergePayload(dm, sm); continue }
+		}
+		if v == nil { continue }
+		if rv := reflect.ValueOf(v); (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Map) && rv.Len() == 0 { continue }
+		dst[k] = v
+	}
+}
+
+// bufferSpeculativeIntent queues a mutation for the next batch under the
+// claim already admitted for it. It returns false if the class is not
+// batched, in which case the caller must send the mutation itself.
+func bufferSpeculativeIntent(uuid, op, endpoint string, data map[string]interface{}, class IntentClass, c *conflictClaim) bool {
+	if classProfiles[class].BatchWindow == 0 { return false } // Batching disabled for this class
+	bufferMu.Lock()
+	defer bufferMu.Unlock()
+	if c != nil && !claimLive(c) { return true } // Lost a conflict since admission; the resolver settled it
+
+	b, ok := intentBuffer[uuid]
+	if !ok {
+		b = &bufferedIntent{
+			Entry: &WalEntry{
+				IntentID:  uint64(nextMonotonicID()),
+				Timestamp: time.Now().UnixNano(),
+				Engine:    "orchestrator",
+				Actor:     "ai",
+				Scope:     WalScope{UUIDs: []string{uuid}, Class: class},
+				Phase:     PhaseProvisional,
+			},
+			Op:      op,
+			Targets: []string{"unity", "blender"},
+		}
+		intentBuffer[uuid] = b
+	}
+	if n := len(b.Ops); n > 0 && b.Ops[n-1].Endpoint == endpoint && sameClaimant(b.Claims[n-1], c) {
+		mergePayload(b.Ops[n-1].Payload, data)
+		if c != nil { foldClaim(b.Claims[n-1], c) }
+		b.Merged++
+		return true
+	}
+	payload := map[string]interface{}{}
+	mergePayload(payload, data)
+	b.Ops = append(b.Ops, EngineOp{Endpoint: endpoint, Payload: payload})
+	b.Claims = append(b.Claims, c)
+	return true
+}
+
+// withdrawBufferedClaim drops the unsent op admitted under c. An entry left
+// without ops is marked ROLLED_BACK.
+func withdrawBufferedClaim(c *conflictClaim, reason string) {
+	bufferMu.Lock()
+	var emptied []*WalEntry
+	for uuid, b := range intentBuffer {
+		for i := 0; i < len(b.Claims); i++ {
+			if b.Claims[i] != c { continue }
+			b.Ops = append(b.Ops[:i:i], b.Ops[i+1:]...)
+			b.Claims = append(b.Claims[:i:i], b.Claims[i+1:]...)
+			i--
+		}
+		if len(b.Ops) == 0 {
+			delete(intentBuffer, uuid)
+			b.Entry.Phase = PhaseRolledBack
+			emptied = append(emptied, b.Entry)
+		}
+	}
+	bufferMu.Unlock()
+	if len(emptied) > 0 {
+		journalOperation(map[string]interface{}{"type": "batch", "phase": PhaseRolledBack, "reason": "conflict:" + reason, "entries": emptied})
+	}
+}
+
+// coalesceRun is held by the coalescing loop around each pass; whoever holds
+// it decides when the buffer is flushed.
+var coalesceRun sync.Mutex
+
+func startCoalescingLoop() {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	for range ticker.C {
+		coalesceRun.Lock()
+		flushIntentBuffer()
+		coalesceRun.Unlock()
+	}
+}
+
+// flushIntentBuffer sends every entry whose batch window has closed as one
+// batch and returns the entries with their final phase.
+func flushIntentBuffer() []*WalEntry {
+	bufferMu.Lock()
+	now := time.Now().UnixNano()
+	var due []*bufferedIntent
+	for uuid, b := range intentBuffer {
+		// Each entry waits out the batch window of its own class
+		if now-b.Entry.Timestamp < int64(classProfiles[b.Entry.Scope.Class].BatchWindow) { continue }
+		delete(intentBuffer, uuid)
+		due = append(due, b)
+	}
+	bufferMu.Unlock()
+	if len(due) == 0 { return nil }
+	sort.Slice(due, func(i, j int) bool { return due[i].Entry.IntentID < due[j].Entry.IntentID })
+	log.Printf("🌊 VibeSync Batching: Finalizing %d speculative intents", len(due))
+
+	ctx, cancel := context.WithTimeout(context.Background(), batchSendTimeout)
+	defer cancel()
+	ctx, finish, due, starved := budgetBatch(ctx, due)
+	var phase WalPhase
+	var hash, reason string
+	if len(due) > 0 { phase, hash, reason = commitBatch(ctx, due) }
+	finish()
+
+	entries := make([]*WalEntry, 0, len(due)+len(starved))
+	var claims []*conflictClaim
+	var intentIDs []string
+	merged := 0
+	for _, b := range due {
+		b.Entry.Phase = phase
+		b.Entry.Verify = WalVerify{ObservedHash: hash, VerifiedAt: time.Now().UnixNano()}
+		entries = append(entries, b.Entry)
+		merged += b.Merged
+		for _, c := range b.Claims {
+			if c == nil { continue }
+			claims = append(claims, c)
+			if c.IntentID != "" && !containsString(intentIDs, c.IntentID) { intentIDs = append(intentIDs, c.IntentID) }
+		}
+	}
+	if len(due) > 0 {
+		journalOperation(map[string]interface{}{
+			"type":          "batch",
+			"phase":         phase,
+			"observed_hash": hash,
+			"merged":        merged,
+			"reason":        reason,
+			"intents":       intentIDs,
+			"entries":       entries,
+		})
+	}
+	if phase == PhaseRolledBack {
+		retireClaims(claims)
+		for _, id := range intentIDs { transitionIntent(id, IntentRejected, "BATCH_ROLLED_BACK: "+reason) }
+		dispatchVibeEvent(LevelWarn, "batch_rolled_back", "", "RESUBMIT", map[string]interface{}{"entries": len(entries), "intents": intentIDs, "reason": reason})
+	}
+	if len(starved) > 0 {
+		for _, b := range starved { b.Entry.Phase = PhaseRolledBack; entries = append(entries, b.Entry) }
+		journalOperation(map[string]interface{}{"type": "batch", "phase": PhaseRolledBack, "reason": "BUDGET_EXHAUSTED", "entries": entries[len(due):]})
+	}
+	return entries
+}
+
+// budgetBatch bounds a batch by the tightest remaining budget of the intents
+// in it. Ops of intents with no budget left are dropped; buffered intents
+// that still have ops are returned as kept, the others as starved. finish charges the
+// batch's time to every intent.
+func budgetBatch(ctx context.Context, due []*bufferedIntent) (context.Context, func(), []*bufferedIntent, []*bufferedIntent) {
+	tightest := ctx
+	var finishes []func()
+	spent := map[string]bool{}
+	for _, b := range due {
+		for _, c := range b.Claims {
+			if c == nil || c.IntentID == "" { continue }
+			if _, seen := spent[c.IntentID]; seen { continue }
+			spent[c.IntentID] = false
+			bctx, finish, err := budgetContext(ctx, c.IntentID)
+			if err != nil { spent[c.IntentID] = true; continue }
+			finishes = append(finishes, finish)
+			// Each budget is derived from ctx, so only the intent that runs
+			// out is charged as exhausted.
+			if d, ok := bctx.Deadline(); ok {
+				if td, ok := tightest.Deadline(); !ok || d.Before(td) { tightest = bctx }
+			}
+		}
+	}
+
+	kept := due[:0]
+	var starved []*bufferedIntent
+	for _, b := range due {
+		var ops []EngineOp
+		var claims, dropped []*conflictClaim
+		for i, c := range b.Claims {
+			if c != nil && spent[c.IntentID] { dropped = append(dropped, c); continue }
+			ops, claims = append(ops, b.Ops[i]), append(claims, c)
+		}
+		retireClaims(dropped)
+		b.Ops, b.Claims = ops, claims
+		if len(b.Ops) == 0 { starved = append(starved, b); continue }
+		kept = append(kept, b)
+	}
+	return tightest, func() { for _, f := range finishes { f() } }, kept, starved
+}
+
+// commitBatch applies a batch on every engine it touches and verifies it. If
+// any engine refuses the batch or cannot report its state afterwards, the
+// engines that took it are rewound.
+func commitBatch(ctx context.Context, due []*bufferedIntent) (WalPhase, string, string) {
+	ops := map[string][]EngineOp{}
+	for _, b := range due {
+		for _, t := range b.Targets { ops[t] = append(ops[t], b.Ops...) }
+	}
+	targets := make([]string, 0, len(ops))
+	for t := range ops { targets = append(targets, t) }
+	sort.Strings(targets)
+
+	intentID, agent := batchOwner(due)
+	rec := newDetachedUndoRecord("batch", OpTransform, intentID, agent)
+	for _, b := range due {
+		for _, c := range b.Claims {
+			if c != nil { bindClaim(c, rec) }
+		}
+	}
+	for _, t := range targets {
+		if _, err := sendMutation(ctx, rec, t, "batch/apply", map[string]interface{}{"ops": ops[t]}); err != nil {
+			rewindRecord(context.WithoutCancel(ctx), rec)
+			return PhaseRolledBack, "", fmt.Sprintf("%s refused batch: %v", t, err)
+		}
+	}
+
+	// One verification for the whole batch: the engines' state hashes,
+	// folded into a single hash in target order.
+	h := sha256.New()
+	for _, t := range targets {
+		v, err := sendToEngine(ctx, t, "state/get", "GET", nil)
+		if err != nil || v == nil || v["hash"] == nil {
+			rewindRecord(context.WithoutCancel(ctx), rec)
+			return PhaseRolledBack, "", fmt.Sprintf("%s could not verify batch", t)
+		}
+		fmt.Fprintf(h, "%s:%v\n", t, v["hash"])
+	}
+	sealUndoRecord(rec)
+	return PhaseFinal, hex.EncodeToString(h.Sum(nil)), ""
+}
+
+// batchOwner names the intent and holder of a batch whose every claim shares
+// them, so its undo record can be replayed under that intent's grant. A batch
+// mixing intents belongs to none of them.
+func batchOwner(due []*bufferedIntent) (intentID, holder string) {
+	first := true
+	for _, b := range due {
+		for _, c := range b.Claims {
+			if c == nil { continue }
+			if first { intentID, holder, first = c.IntentID, c.Holder, false; continue }
+			if c.IntentID != intentID || c.Holder != holder { return "", "" }
+		}
+	}
+	return intentID, holder
+}
+
+// dropBufferedIntents withdraws pending mutations on uuids before they are
+// sent, marking their entries WAIT_HUMAN_LOCK.
+func dropBufferedIntents(uuids []string) []*WalEntry {
+	bufferMu.Lock()
+	var dropped []*WalEntry
+	for _, id := range uuids {
+		if b, ok := intentBuffer[id]; ok {
+			delete(intentBuffer, id)
+			b.Entry.Phase = PhaseWaitHuman
+			dropped = append(dropped, b.Entry)
+		}
+	}
+	bufferMu.Unlock()
+	if len(dropped) > 0 {
+		journalOperation(map[string]interface{}{"type": "batch", "phase": PhaseWaitHuman, "reason": ConflictHumanLock, "entries": dropped})
+	}
+	return dropped
+}
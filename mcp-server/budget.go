@@ -0,0 +1,121 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Intent Time Budgets
+//
+// IntentEnvelope.BudgetMS is the total wall-clock time an intent may spend in
+// engine calls across all of its tool calls. Each tool call acting for an
+// intent gets a context deadline of the budget that is left; sendToEngine
+// passes it to every attempt, and time actually spent is charged back to the
+// intent when the call ends. Plan step intents are issued with the plan's
+// remaining TotalBudget, so plans are bounded the same way.
+
+// budgetContext derives the context for one tool call from the remaining
+// budget of intentID, or of the intent owning the open transaction when
+// intentID is empty. Intents without a budget get parent unchanged. The
+// returned finish func must be called when the tool call ends.
+func budgetContext(parent context.Context, intentID string) (context.Context, func(), error) {
+	txMu.Lock()
+	if intentID == "" && activeTransaction != nil { intentID = activeTransaction.IntentID }
+	rec, ok := intents[intentID]
+	if !ok || rec.Envelope.BudgetMS <= 0 { txMu.Unlock(); return parent, func() {}, nil }
+	remaining := rec.Envelope.BudgetMS - rec.BudgetUsedMS
+	txMu.Unlock()
+
+	if remaining <= 0 {
+		exhaustBudget(intentID)
+		return nil, nil, fmt.Errorf("BUDGET_EXHAUSTED: Intent %s has no time budget left", intentID)
+	}
+
+	ctx, cancel := context.WithTimeout(parent, time.Duration(remaining)*time.Millisecond)
+	start := time.Now()
+	finish := func() {
+		exhausted := errors.Is(ctx.Err(), context.DeadlineExceeded)
+		cancel()
+		txMu.Lock()
+		rec.BudgetUsedMS += int(time.Since(start).Milliseconds())
+		saveIntentsLocked()
+		txMu.Unlock()
+		if exhausted { exhaustBudget(intentID) }
+	}
+	return ctx, finish, nil
+}
+
+// budgetError maps a context failure onto the error reported to the caller.
+func budgetError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) { return fmt.Errorf("BUDGET_EXHAUSTED: Intent time budget elapsed before the engine answered") }
+	return fmt.Errorf("CANCELLED: %v", ctx.Err())
+}
+
+// exhaustBudget aborts an intent whose budget ran out. An open transaction is
+// rolled back on both engines exactly like a transaction timeout.
+func exhaustBudget(intentID string) {
+	txMu.Lock()
+	defer txMu.Unlock()
+	rec, ok := intents[intentID]
+	if !ok || isIntentTerminal(rec.Status) { return }
+
+	log.Printf("⏱️ Intent Budget: %s exhausted (%dms of %dms)", intentID, rec.BudgetUsedMS, rec.Envelope.BudgetMS)
+	dispatchVibeEvent(LevelError, "budget_exhausted", intentID, "ABORT", map[string]interface{}{"budget_ms": rec.Envelope.BudgetMS, "used_ms": rec.BudgetUsedMS})
+	if tx, ok := transactions[intentID]; ok {
+		rollbackTransactionLocked(intentID, tx, "BUDGET_EXHAUSTED")
+		return
+	}
+	transitionIntentLocked(rec, IntentRejected, "BUDGET_EXHAUSTED")
+}
+
+// Tool results are often wrapped while txMu is still held, so the forensic
+// report reads a copy of the live budgets that is refreshed whenever the
+// intent table is saved.
+var (
+	budgetView []map[string]interface{}
+	budgetMu   sync.Mutex
+)
+
+// refreshBudgetViewLocked rebuilds budgetView from the intent table. txMu must
+// be held.
+func refreshBudgetViewLocked() {
+	view := []map[string]interface{}{}
+	for id, rec := range intents {
+		if rec.Envelope.BudgetMS <= 0 || isIntentTerminal(rec.Status) { continue }
+		view = append(view, map[string]interface{}{
+			"intent_id":    id,
+			"status":       rec.Status,
+			"budget_ms":    rec.Envelope.BudgetMS,
+			"used_ms":      rec.BudgetUsedMS,
+			"remaining_ms": rec.Envelope.BudgetMS - rec.BudgetUsedMS,
+		})
+	}
+	sort.Slice(view, func(i, j int) bool { return view[i]["intent_id"].(string) < view[j]["intent_id"].(string) })
+	budgetMu.Lock(); budgetView = view; budgetMu.Unlock()
+}
+
+// intentBudgets lists the remaining budget of every live intent that has one.
+func intentBudgets() []map[string]interface{} {
+	budgetMu.Lock()
+	defer budgetMu.Unlock()
+	return budgetView
+}
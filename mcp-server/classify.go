@@ -0,0 +1,124 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Hard Classification Rules (SPECULATIVE_COMMIT_PROTOCOL.md §5)
+//
+// The AI never chooses an intent class; it is derived from the opcode, the
+// adapter endpoint and the payload. Anything that cannot be shown to be
+// cosmetic is treated as structural so it takes the synchronous path.
+type classProfile struct {
+	BatchWindow      time.Duration // 0 disables batching
+	Verification     string        // "deferred", "synchronous" or "snapshot_gated"
+	SnapshotRequired bool
+}
+
+var classProfiles = map[IntentClass]classProfile{
+	ClassCosmetic:    {BatchWindow: 250 * time.Millisecond, Verification: "deferred"},
+	ClassStructural:  {BatchWindow: 100 * time.Millisecond, Verification: "synchronous"},
+	ClassDestructive: {BatchWindow: 0, Verification: "snapshot_gated", SnapshotRequired: true},
+}
+
+var (
+	destructiveMarkers = []string{"delete", "remove", "destroy", "overwrite", "replace", "purge"}
+	structuralMarkers  = []string{"parent", "hierarchy", "instantiate", "prefab", "create", "spawn", "duplicate", "modifier", "import", "reference", "link"}
+	cosmeticEndpoints  = []string{"transform/", "material/", "shader/", "visibility/", "camera/", "selection/", "light/"}
+)
+
+// classifyIntent derives the class of a single adapter call.
+func classifyIntent(op VibeOpcode, endpoint string, payload map[string]interface{}) IntentClass {
+	ep := strings.ToLower(endpoint)
+	for _, m := range destructiveMarkers {
+		if strings.Contains(ep, m) { return ClassDestructive }
+	}
+	if op == OpBake { return ClassDestructive } // Bakes overwrite mesh and texture data in place
+	if op == OpDelete { return ClassDestructive }
+	for k, v := range payload {
+		key := strings.ToLower(k)
+		for _, m := range destructiveMarkers {
+			if strings.Contains(key, m) && v != false && v != nil { return ClassDestructive }
+		}
+	}
+
+	if op == OpModifier || op == OpIO { return ClassStructural }
+	for _, m := range structuralMarkers {
+		if strings.Contains(ep, m) { return ClassStructural }
+	}
+	for k := range payload {
+		key := strings.ToLower(k)
+		for _, m := range structuralMarkers {
+			if strings.Contains(key, m) { return ClassStructural }
+		}
+	}
+
+	if op == OpTransform || op == OpMaterial || op == OpNode { return ClassCosmetic }
+	for _, p := range cosmeticEndpoints {
+		if strings.HasPrefix(ep, p) { return ClassCosmetic }
+	}
+	return ClassStructural
+}
+
+// classifyOpSpec classifies a governed op spec ({target, endpoint, payload}).
+func classifyOpSpec(op VibeOpcode, opSpec map[string]interface{}) IntentClass {
+	endpoint, _ := opSpec["endpoint"].(string)
+	payload, _ := splitOpSpec(opSpec)
+	return classifyIntent(op, endpoint, payload)
+}
+
+// safetySnapshot is the snapshot step destructive tools call; tests replace it
+// so they need no .git_safety repository.
+var safetySnapshot = takeSafetySnapshot
+
+// takeSafetySnapshot commits the working tree into .git_safety and returns the
+// snapshot commit, which becomes WalRoll.SnapshotRef for the mutation.
+func takeSafetySnapshot(label string) (string, error) {
+	if _, err := execCommand("python3 ../scripts/hardening/snap_commit.py " + label); err != nil {
+		return "", fmt.Errorf("SNAPSHOT_FAILED: %v", err)
+	}
+	ref, err := execCommand("git --git-dir=.git_safety rev-parse HEAD")
+	if err != nil { return "", fmt.Errorf("SNAPSHOT_FAILED: %v", err) }
+	ref = strings.TrimSpace(ref)
+	log.Printf("📸 Destructive Guard: Snapshot %s taken (%s)", ref, label)
+	return ref, nil
+}
+
+// verifyDeferred performs the read-back for a cosmetic mutation after the
+// caller has already been answered. The result is journaled either way.
+func verifyDeferred(target, recordID, intentID string, expected []ObjectIdentity) {
+	phase, observed := PhaseFinal, ""
+	v, err := sendToEngine(context.Background(), target, "state/get", "GET", nil)
+	if err != nil || v == nil || v["hash"] == nil {
+		phase = PhaseQuarantined
+		dispatchVibeEvent(LevelError, "deferred_verification_failed", "", "REVERIFY", map[string]interface{}{"engine": target, "record_id": recordID})
+	} else if observed, err = verifyAtDepth(v, expected); err != nil {
+		phase = PhaseQuarantined
+		quarantineTransaction(intentID, target, err)
+	}
+	journalOperation(map[string]interface{}{
+		"type":          "verification",
+		"engine":        target,
+		"record_id":     recordID,
+		"phase":         phase,
+		"observed_hash": observed,
+	})
+}
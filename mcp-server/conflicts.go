@@ -0,0 +1,296 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Conflict Resolution (CONFLICT_RESOLUTION_POLICY.md §2, §3)
+//
+// Every governed mutation stakes a claim on the UUIDs and properties it
+// touches before it reaches an engine. A claim stays live until it has had
+// time to settle (its class batch window, and at least conflictSettleWindow
+// for deferred verification). A new claim that overlaps a live one is resolved
+// by the policy matrix; losers that were already applied are rewound through
+// their undo records, and every resolution is journaled with its
+// ConflictMetadata. Claims are ordered by a monotonic sequence assigned on
+// admission, which is the tie-breaker for deterministic override.
+const conflictSettleWindow = time.Second
+
+const (
+	ResolutionOverride   = "DETERMINISTIC_OVERRIDE"
+	ResolutionMerge      = "MERGE"
+	ResolutionQuarantine = "QUARANTINE"
+	ResolutionHumanWait  = "HUMAN_SUPREMACY"
+)
+
+const (
+	ConflictPropertyOverlap = "PROPERTY_OVERLAP"
+	ConflictDisjoint        = "DISJOINT_PROPERTIES"
+	ConflictGraph           = "GRAPH_INVALIDITY"
+	ConflictDestructive     = "DESTRUCTIVE_DOMINANCE"
+	ConflictHumanLock       = "HUMAN_ACTIVE_LOCK"
+)
+
+// conflictClaim is one admitted mutation that has not settled yet.
+type conflictClaim struct {
+	Seq      uint64
+	IntentID string
+	Holder   string
+	Class    IntentClass
+	UUIDs    []string
+	Props    []string
+	RecordID string // Undo record the mutation is captured into; empty while it waits in a batch
+	Snapshot string
+	SettleAt time.Time
+}
+
+var (
+	conflictClaims []*conflictClaim
+	conflictSeq    uint64
+	conflictMu     sync.Mutex // Leaf lock: nothing else is taken while it is held
+)
+
+func newConflictClaim(intentID, holder string, class IntentClass, uuids, props []string, rec *UndoRecord) *conflictClaim {
+	settle := classProfiles[class].BatchWindow
+	if settle < conflictSettleWindow { settle = conflictSettleWindow }
+	c := &conflictClaim{IntentID: intentID, Holder: holder, Class: class, UUIDs: uuids, Props: props, SettleAt: time.Now().Add(settle)}
+	if rec != nil { undoMu.Lock(); c.RecordID, c.Snapshot = rec.ID, rec.SnapshotRef; recordClaimLocked(rec, class, uuids); undoMu.Unlock() }
+	return c
+}
+
+func overlaps(a, b []string) bool {
+	for _, x := range a {
+		if containsString(b, x) { return true }
+	}
+	return false
+}
+
+// resolveConflict applies the policy matrix to two overlapping claims, where
+// newer was admitted after older. It returns the metadata for the WAL and the
+// claims that lose.
+func resolveConflict(older, newer *conflictClaim) (ConflictMetadata, []*conflictClaim) {
+	switch {
+	case older.Class == ClassDestructive || newer.Class == ClassDestructive:
+		return ConflictMetadata{Type: string(ClassDestructive), Resolution: ResolutionQuarantine, Reason: ConflictDestructive}, []*conflictClaim{older, newer}
+	case older.Class == ClassStructural && newer.Class == ClassStructural:
+		return ConflictMetadata{Type: string(ClassStructural), Resolution: ResolutionQuarantine, Reason: ConflictGraph}, []*conflictClaim{older, newer}
+	case older.Class == ClassStructural:
+		return ConflictMetadata{Type: string(ClassStructural), Resolution: ResolutionOverride, WinnerIntentID: older.Seq, Reason: ConflictGraph}, []*conflictClaim{newer}
+	case newer.Class == ClassStructural:
+		return ConflictMetadata{Type: string(ClassStructural), Resolution: ResolutionOverride, WinnerIntentID: newer.Seq, Reason: ConflictGraph}, []*conflictClaim{older}
+	case overlaps(older.Props, newer.Props):
+		return ConflictMetadata{Type: string(ClassCosmetic), Resolution: ResolutionOverride, WinnerIntentID: newer.Seq, Reason: ConflictPropertyOverlap}, []*conflictClaim{older}
+	}
+	return ConflictMetadata{Type: string(ClassCosmetic), Resolution: ResolutionMerge, Reason: ConflictDisjoint}, nil
+}
+
+// admitMutation resolves c against every live claim it overlaps and, if c
+// survives, records it as live. Applied losers are rewound and their intents
+// rejected or quarantined. An error means c itself must not be applied.
+func admitMutation(ctx context.Context, c *conflictClaim) error {
+	for _, id := range c.UUIDs {
+		if err := checkHumanLock(id); err != nil { return yieldToHuman(ctx, c, id, err) }
+		if err := checkLease(c.Holder, id); err != nil { return err }
+	}
+
+	now := time.Now()
+	conflictMu.Lock()
+	conflictSeq++
+	c.Seq = conflictSeq
+	live := conflictClaims[:0]
+	for _, o := range conflictClaims {
+		if now.Before(o.SettleAt) { live = append(live, o) }
+	}
+	conflictClaims = live
+
+	type resolution struct {
+		meta    ConflictMetadata
+		against *conflictClaim
+		losers  []*conflictClaim
+	}
+	var outcomes []resolution
+	lost := map[*conflictClaim]bool{}
+	for _, o := range conflictClaims {
+		if !overlaps(o.UUIDs, c.UUIDs) || sameClaimant(o, c) { continue }
+		meta, losers := resolveConflict(o, c)
+		outcomes = append(outcomes, resolution{meta, o, losers})
+		for _, l := range losers { lost[l] = true }
+	}
+	kept := conflictClaims[:0]
+	for _, o := range conflictClaims {
+		if !lost[o] { kept = append(kept, o) }
+	}
+	conflictClaims = kept
+	if !lost[c] { conflictClaims = append(conflictClaims, c) }
+	conflictMu.Unlock()
+
+	var verdict error
+	for _, r := range outcomes {
+		settleConflict(ctx, r.meta, c, r.against, r.losers)
+		if containsClaim(r.losers, c) && verdict == nil {
+			verdict = fmt.Errorf("CONFLICT_%s: intent %s lost to %s on %v (%s)", r.meta.Resolution, c.IntentID, r.against.IntentID, c.UUIDs, r.meta.Reason)
+		}
+	}
+	return verdict
+}
+
+// sameClaimant reports whether two claims were staked by the same holder for
+// the same intent; such claims extend each other rather than conflict.
+func sameClaimant(a, b *conflictClaim) bool {
+	if a == nil || b == nil { return a == b }
+	return a.Holder == b.Holder && a.IntentID == b.IntentID
+}
+
+// claimLive reports whether c is still admitted.
+func claimLive(c *conflictClaim) bool {
+	conflictMu.Lock()
+	defer conflictMu.Unlock()
+	return containsClaim(conflictClaims, c)
+}
+
+// bindClaim points c at the undo record its mutation was finally captured
+// into, for claims admitted before they were sent (batched mutations).
+func bindClaim(c *conflictClaim, rec *UndoRecord) {
+	conflictMu.Lock(); class, uuids := c.Class, c.UUIDs; conflictMu.Unlock()
+	undoMu.Lock(); id, snap := rec.ID, rec.SnapshotRef; recordClaimLocked(rec, class, uuids); undoMu.Unlock()
+	conflictMu.Lock(); c.RecordID, c.Snapshot = id, snap; conflictMu.Unlock()
+}
+
+// foldClaim merges c into into, which already covers the same mutation; c
+// stops being a claim of its own.
+func foldClaim(into, c *conflictClaim) {
+	conflictMu.Lock()
+	defer conflictMu.Unlock()
+	for _, p := range c.Props {
+		if !containsString(into.Props, p) { into.Props = append(into.Props, p) }
+	}
+	if c.SettleAt.After(into.SettleAt) { into.SettleAt = c.SettleAt }
+	retireClaimsLocked([]*conflictClaim{c})
+}
+
+// retireClaims removes claims whose mutation was undone without a conflict
+// (a rolled back batch), so they cannot be rewound a second time.
+func retireClaims(cs []*conflictClaim) {
+	conflictMu.Lock()
+	defer conflictMu.Unlock()
+	retireClaimsLocked(cs)
+}
+
+func retireClaimsLocked(cs []*conflictClaim) {
+	kept := conflictClaims[:0]
+	for _, o := range conflictClaims {
+		if !containsClaim(cs, o) { kept = append(kept, o) }
+	}
+	conflictClaims = kept
+}
+
+func containsClaim(cs []*conflictClaim, c *conflictClaim) bool {
+	for _, x := range cs {
+		if x == c { return true }
+	}
+	return false
+}
+
+// settleConflict carries out one resolution: applied losers are rewound, their
+// intents leave the pipeline, and the conflict is journaled and announced.
+func settleConflict(ctx context.Context, meta ConflictMetadata, newer, older *conflictClaim, losers []*conflictClaim) {
+	phase, status := PhaseRolledBack, IntentRejected
+	if meta.Resolution == ResolutionQuarantine { phase, status = PhaseQuarantined, IntentQuarantined }
+	if meta.Resolution == ResolutionMerge { phase = PhaseProvisional }
+
+	snapshot := ""
+	for _, cl := range []*conflictClaim{older, newer} {
+		if cl.Snapshot != "" { snapshot = cl.Snapshot }
+	}
+	var loserIDs []string
+	for _, l := range losers {
+		loserIDs = append(loserIDs, l.IntentID)
+		conflictMu.Lock(); recordID := l.RecordID; conflictMu.Unlock()
+		if l != newer && recordID != "" {
+			if err := rewindUndoRecord(ctx, recordID, "conflict:"+meta.Reason); err != nil {
+				log.Printf("⚔️ Conflict Resolver: could not rewind %s: %v", l.IntentID, err)
+			}
+		} else if l != newer {
+			withdrawBufferedClaim(l, meta.Reason) // Not sent yet: drop it from the batch
+		}
+		if l.IntentID != "" { transitionIntent(l.IntentID, status, fmt.Sprintf("CONFLICT_%s: %s", meta.Resolution, meta.Reason)) }
+	}
+
+	journalOperation(map[string]interface{}{
+		"type":      "conflict",
+		"intent_id": newer.Seq,
+		"intents":   []string{older.IntentID, newer.IntentID},
+		"losers":    loserIDs,
+		"phase":     phase,
+		"scope":     WalScope{UUIDs: newer.UUIDs, Class: newer.Class},
+		"rollback":  WalRoll{SnapshotRef: snapshot},
+		"conflict":  meta,
+	})
+	level, action := LevelWarn, "AUTO_RESOLVED"
+	if meta.Resolution == ResolutionQuarantine { level, action = LevelError, "HUMAN_INTERVENTION_REQUIRED" }
+	if meta.Resolution == ResolutionMerge { level = LevelInfo }
+	log.Printf("⚔️ Conflict Resolver: %s vs %s -> %s (%s)", older.IntentID, newer.IntentID, meta.Resolution, meta.Reason)
+	dispatchVibeEvent(level, "conflict_event", newer.IntentID, action, map[string]interface{}{
+		"conflict": meta,
+		"snapshot": snapshot,
+		"intents":  []conflictClaim{*older, *newer},
+	})
+}
+
+// yieldToHuman defers an AI mutation on a UUID under a HUMAN_ACTIVE lock and
+// rewinds any provisional AI mutation that slipped onto it during the lock.
+func yieldToHuman(ctx context.Context, c *conflictClaim, uuid string, lockErr error) error {
+	rolled := rollbackClaimsOn(ctx, []string{uuid})
+	meta := ConflictMetadata{Type: string(c.Class), Resolution: ResolutionHumanWait, Reason: ConflictHumanLock}
+	journalOperation(map[string]interface{}{
+		"type":        "conflict",
+		"intents":     []string{c.IntentID},
+		"rolled_back": rolled,
+		"phase":       PhaseWaitHuman,
+		"scope":       WalScope{UUIDs: c.UUIDs, Class: c.Class},
+		"conflict":    meta,
+	})
+	dispatchVibeEvent(LevelWarn, "conflict_event", c.IntentID, string(PhaseWaitHuman), map[string]interface{}{"conflict": meta, "uuid": uuid, "rolled_back": rolled})
+	return lockErr
+}
+
+// rollbackClaimsOn rewinds every live AI claim touching uuids and parks its
+// intent in WAIT_HUMAN_LOCK. It returns the intents that were rolled back.
+func rollbackClaimsOn(ctx context.Context, uuids []string) []string {
+	conflictMu.Lock()
+	var applied []*conflictClaim
+	kept := conflictClaims[:0]
+	for _, o := range conflictClaims {
+		if overlaps(o.UUIDs, uuids) { applied = append(applied, o); continue }
+		kept = append(kept, o)
+	}
+	conflictClaims = kept
+	conflictMu.Unlock()
+
+	var rolled []string
+	for _, o := range applied {
+		conflictMu.Lock(); recordID := o.RecordID; conflictMu.Unlock()
+		if recordID != "" { rewindUndoRecord(ctx, recordID, "conflict:"+ConflictHumanLock) }
+		if o.IntentID != "" { transitionIntent(o.IntentID, IntentWaitHuman, "CONFLICT_"+ResolutionHumanWait+": "+ConflictHumanLock) }
+		rolled = append(rolled, o.IntentID)
+	}
+	return rolled
+}
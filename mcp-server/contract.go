@@ -42,6 +42,7 @@ const (
 	OpTransform   VibeOpcode = 0x03
 	OpModifier    VibeOpcode = 0x04
 	OpNode        VibeOpcode = 0x05
+	OpDelete      VibeOpcode = 0x06
 	OpMaterial    VibeOpcode = 0x09
 	OpBake        VibeOpcode = 0x0A
 	OpIO          VibeOpcode = 0x0B
@@ -84,12 +85,163 @@ type IntentEnvelope struct {
 	Provenance        string            `json:"provenance"`
 	BudgetMS          int               `json:"budget_ms"`
 	Signature         string            `json:"signature"`
+	AgentID           string            `json:"agent_id"`
 	BasedOnHashes     map[string]string `json:"based_on_hashes"`
 	Intent            IntentType        `json:"intent"`
 	Opcode            VibeOpcode        `json:"opcode,omitempty"`
 	DryRun            bool              `json:"dry_run,omitempty"`
 }
 
+type IntentStatus string
+
+const (
+	IntentSubmitted   IntentStatus = "SUBMITTED"
+	IntentValidated   IntentStatus = "VALIDATED"
+	IntentApproved    IntentStatus = "APPROVED"
+	IntentExecuting   IntentStatus = "EXECUTING"
+	IntentDone        IntentStatus = "DONE"
+	IntentExpired     IntentStatus = "EXPIRED"
+	IntentRejected    IntentStatus = "REJECTED"
+	IntentQuarantined IntentStatus = "QUARANTINED"
+	IntentWaitHuman   IntentStatus = "WAIT_HUMAN_LOCK"
+)
+
+type PolicyRule struct {
+	ID                string        `json:"id"`
+	Intent            IntentType    `json:"intent,omitempty"`
+	AllowedOpcodes    []VibeOpcode  `json:"allowed_opcodes"`
+	AllowedClasses    []IntentClass `json:"allowed_classes,omitempty"`
+	UUIDScopes        []string      `json:"uuid_scopes,omitempty"` // path.Match patterns
+	RequiredApprovals int           `json:"required_approvals"`
+	MinConfidence     float64       `json:"min_confidence"`
+}
+
+type GovernancePolicy struct {
+	Version string       `json:"version"`
+	Default PolicyRule   `json:"default"`
+	Rules   []PolicyRule `json:"rules"`
+}
+
+type PolicyDecision struct {
+	RuleID            string  `json:"rule_id"`
+	PolicyVersion     string  `json:"policy_version"`
+	Allowed           bool    `json:"allowed"`
+	RequiredApprovals int     `json:"required_approvals"`
+	MinConfidence     float64 `json:"min_confidence"`
+	Reason            string  `json:"reason,omitempty"`
+}
+
+type AgentRole string
+
+const (
+	RoleForeman  AgentRole = "Foreman"
+	RoleOperator AgentRole = "Operator"
+	RoleHuman    AgentRole = "Human" // Signs approvals and overrides; never an envelope
+)
+
+// AgentKey is one entry of the agent key registry. Secret is the HMAC key the
+// agent signs its envelopes with and is never returned by any tool.
+type AgentKey struct {
+	AgentID string    `json:"agent_id"`
+	Role    AgentRole `json:"role"`
+	Engine  string    `json:"engine"`
+	Secret  string    `json:"secret"`
+	Revoked bool      `json:"revoked,omitempty"`
+}
+
+// CapabilityGrant limits which UUIDs, opcodes and engines its holder may
+// touch. Grants are minted by the orchestrator from a validated intent, or
+// narrowed from a Foreman's grant for a single work order, and travel as an
+// opaque signed token.
+type CapabilityGrant struct {
+	ID          string       `json:"id"`
+	Holder      string       `json:"holder"`
+	IssuedBy    string       `json:"issued_by"`
+	ParentID    string       `json:"parent_id,omitempty"`
+	IntentID    string       `json:"intent_id,omitempty"`
+	WorkOrderID string       `json:"work_order_id,omitempty"`
+	UUIDs       []string     `json:"uuids"` // path.Match patterns
+	Opcodes     []VibeOpcode `json:"opcodes"`
+	Engines     []string     `json:"engines"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+	Signature   string       `json:"signature"`
+}
+
+// MintGrantArgs is signed over canonicalDecision("mint_capability_grant", args)
+// by the intent's own agent key, or by a Human key named as Approver.
+type MintGrantArgs struct {
+	IntentID   string   `json:"intent_id"`
+	Engines    []string `json:"engines,omitempty"`
+	TTLSeconds int      `json:"ttl_seconds,omitempty"`
+	Approver   string   `json:"approver,omitempty"` // A Human minting on the agent's behalf
+	Signature  string   `json:"signature"`
+}
+
+// DelegateGrantArgs is signed by the delegating Foreman with its agent key
+// over the canonical JSON form with Signature blanked.
+type DelegateGrantArgs struct {
+	Grant       string       `json:"grant"`
+	To          string       `json:"to"`
+	WorkOrderID string       `json:"work_order_id"`
+	UUIDs       []string     `json:"uuids,omitempty"`
+	Opcodes     []VibeOpcode `json:"opcodes,omitempty"`
+	Engines     []string     `json:"engines,omitempty"`
+	TTLSeconds  int          `json:"ttl_seconds,omitempty"`
+	Signature   string       `json:"signature"`
+}
+
+// AgentIdentity is the verified signer of an envelope.
+type AgentIdentity struct {
+	AgentID string    `json:"agent_id"`
+	Role    AgentRole `json:"role"`
+	Engine  string    `json:"engine"`
+}
+
+type IntentRecord struct {
+	ID           string                 `json:"id"`
+	Envelope     IntentEnvelope         `json:"envelope"`
+	Agent        AgentIdentity          `json:"agent"`
+	Class        IntentClass            `json:"intent_class"`
+	Status       IntentStatus           `json:"status"`
+	Policy       PolicyDecision         `json:"policy"`
+	OpSpec       map[string]interface{} `json:"op_spec,omitempty"`
+	Reason       string                 `json:"reason,omitempty"`
+	SubmittedAt  time.Time              `json:"submitted_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+	ExpiresAt    time.Time              `json:"expires_at"`
+	BudgetUsedMS int                    `json:"budget_used_ms,omitempty"`
+}
+
+type ApprovalDecision struct {
+	Approver string    `json:"approver"`
+	Approved bool      `json:"approved"`
+	Reason   string    `json:"reason"`
+	At       time.Time `json:"at"`
+}
+
+// ApprovalRequest is a single intent awaiting human review. It carries
+// everything a reviewer needs without re-querying the orchestrator.
+type ApprovalRequest struct {
+	IntentID          string             `json:"intent_id"`
+	Intent            IntentType         `json:"intent"`
+	Rationale         string             `json:"rationale"`
+	Scope             []string           `json:"scope"`
+	Confidence        float64            `json:"confidence"`
+	PolicyRule        string             `json:"policy_rule"`
+	PolicyReason      string             `json:"policy_reason,omitempty"`
+	RequiredApprovals int                `json:"required_approvals"`
+	Predicted         DryRunReport       `json:"predicted_effect"`
+	Decisions         []ApprovalDecision `json:"decisions"`
+	CreatedAt         time.Time          `json:"created_at"`
+}
+
+type IntentDecisionArgs struct {
+	IntentID  string `json:"intent_id"`
+	Approver  string `json:"approver"`
+	Reason    string `json:"reason"`
+	Signature string `json:"signature"` // Approver's Human key over the decision
+}
+
 type VibeState string
 
 const (
@@ -137,12 +289,36 @@ type ReadStateArgs struct {
 }
 
 type VerifyStateArgs struct {
-	Target       string `json:"target"`
-	ExpectedHash string `json:"expected_hash"`
+	Target       string           `json:"target"`
+	ExpectedHash string           `json:"expected_hash"`
+	Identities   []ObjectIdentity `json:"identities,omitempty"` // Depths the expected hash was taken at
 }
 
 type SubmitIntentArgs struct {
-	Envelope IntentEnvelope `json:"envelope"`
+	Envelope IntentEnvelope         `json:"envelope"`
+	OpSpec   map[string]interface{} `json:"op_spec,omitempty"` // Drives dry-run and approval previews
+}
+
+type DryRunCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type PredictedChange struct {
+	Engine       string   `json:"engine"`
+	UUIDs        []string `json:"uuids"`
+	Properties   []string `json:"properties"`
+	ExpectedHash string   `json:"expected_hash,omitempty"`
+}
+
+type DryRunReport struct {
+	Intent      IntentType        `json:"intent"`
+	Opcode      VibeOpcode        `json:"opcode"`
+	Class       IntentClass       `json:"intent_class"`
+	Predicted   []PredictedChange `json:"predicted_diff"`
+	Checks      []DryRunCheck     `json:"checks"`
+	WouldCommit bool              `json:"would_commit"`
 }
 
 type AtomicOpArgs struct {
@@ -151,19 +327,34 @@ type AtomicOpArgs struct {
 }
 
 type CommitAtomicOpArgs struct {
-	IntentID    string `json:"intent_id"`
-	ProofOfWork string `json:"proof_of_work"`
-	Reason      string `json:"reason,omitempty"`
+	IntentID    string      `json:"intent_id"`
+	ProofOfWork ProofOfWork `json:"proof_of_work"`
+	Reason      string      `json:"reason,omitempty"`
+}
+
+// EchoTriplet is the Cross-Layer Echo: one state observed by three layers.
+type EchoTriplet struct {
+	BlenderExportHash      string `json:"blender_export_hash"`
+	UnityImportHash        string `json:"unity_import_hash"`
+	BridgeVerificationHash string `json:"bridge_verification_hash"`
+}
+
+type ProofOfWork struct {
+	EchoTriplet
+	TransactionID string `json:"transaction_id"`
+	Binding       string `json:"binding"` // Issued by get_bridge_commit_requirements
 }
 
 type MutateArgs struct {
 	IntentID       string                 `json:"intent_id"`
 	IdempotencyKey string                 `json:"idempotency_key"`
 	OpSpec         map[string]interface{} `json:"op_spec"`
+	Grant          string                 `json:"grant"`
 }
 
 type SyncAssetAtomicArgs struct {
 	AssetPath string `json:"asset_path"`
+	Grant     string `json:"grant"`
 }
 
 type MultiplexCallArgs struct {
@@ -171,6 +362,7 @@ type MultiplexCallArgs struct {
 	Target   string                 `json:"target"`
 	Endpoint string                 `json:"endpoint"`
 	Payload  map[string]interface{} `json:"payload"`
+	Grant    string                 `json:"grant"`
 }
 
 type SyncTransformArgs struct {
@@ -178,26 +370,40 @@ type SyncTransformArgs struct {
 	Position []float64 `json:"position"`
 	Rotation []float64 `json:"rotation"`
 	Scale    []float64 `json:"scale"`
+	Grant    string    `json:"grant"`
 }
 
 type SyncCameraArgs struct {
 	Source string `json:"source"`
+	Grant  string `json:"grant"`
 }
 
 type SyncSelectionArgs struct {
 	Source string   `json:"source"`
 	IDs    []string `json:"ids"`
+	Grant  string   `json:"grant"`
 }
 
 type SyncMaterialArgs struct {
 	ObjectID string                 `json:"object_id"`
 	Props    map[string]interface{} `json:"properties"`
+	Grant    string                 `json:"grant"`
 }
 
 type LockObjectArgs struct {
 	Target   string `json:"target"`
 	ObjectID string `json:"object_id"`
 	Locked   bool   `json:"locked"`
+	Lease    string `json:"lease,omitempty"` // Required to renew or release
+	Grant    string `json:"grant"`
+}
+
+type PerimeterLockArgs struct {
+	Locked     bool   `json:"locked"`
+	Lease      string `json:"lease,omitempty"` // Required to renew or release
+	TTLSeconds int    `json:"ttl_seconds,omitempty"`
+	Approver   string `json:"approver,omitempty"` // Human who takes or renews the perimeter
+	Signature  string `json:"signature,omitempty"`
 }
 
 type MapVibeIDsArgs struct {
@@ -237,6 +443,7 @@ type BridgeHandshakeState struct {
 type BridgeWalState struct {
 	WalHead           int64  `json:"wal_head"`
 	WalHash           string `json:"wal_hash"`
+	StateHead         string `json:"state_head"` // Hash of the last state-changing entry; pin intents to this
 	LastCommittedOp   string `json:"last_committed_op"`
 	PendingOps        int    `json:"pending_ops"`
 	RollbackAvailable bool   `json:"rollback_available"`
@@ -258,10 +465,16 @@ type BridgeDeltaState struct {
 	Applied        bool     `json:"applied"`
 }
 
+type CommitRequirementsArgs struct {
+	IntentID string `json:"intent_id,omitempty"` // Issue a binding for this intent's open transaction
+}
+
 type BridgeCommitRequirements struct {
 	RequiredHashes    map[string]string `json:"required_hashes"`
 	RationaleRequired bool              `json:"rationale_required"`
 	CommitAllowed     bool              `json:"commit_allowed"`
+	TransactionID     string            `json:"transaction_id,omitempty"`
+	Binding           string            `json:"binding,omitempty"` // HMAC over tid|blender|unity|bridge under the orchestrator's key
 }
 
 type TechnicalRationaleCheck struct {
@@ -359,6 +572,63 @@ type ConflictMetadata struct {
 	Reason         string `json:"reason"`
 }
 
+// EditorActivity is what an engine adapter reports when a human touches
+// objects in its editor. Kind "idle" ends the activity early.
+type EditorActivity struct {
+	Engine string   `json:"engine"`
+	Kind   string   `json:"kind"` // selection | gizmo_drag | property_edit | idle
+	UUIDs  []string `json:"uuids"`
+	User   string   `json:"user,omitempty"`
+}
+
+// HierarchyNode is one parent edge as an adapter's hierarchy/get reports it.
+// Roots have an empty Parent. Dependents are the constraints and modifiers
+// elsewhere in the scene that target this node.
+type HierarchyNode struct {
+	UUID        string     `json:"uuid"`
+	Parent      string     `json:"parent"`
+	Kind        ObjectKind `json:"kind,omitempty"`
+	PrefabDepth int        `json:"prefab_depth,omitempty"`
+	Dependents  []string   `json:"dependents,omitempty"`
+}
+
+type HierarchyQueryArgs struct {
+	Engine  string `json:"engine"`
+	UUID    string `json:"uuid,omitempty"`
+	Refresh bool   `json:"refresh,omitempty"`
+}
+
+// WaitEdge is one edge of the wait-for graph: Waiter cannot proceed until
+// Holder gives up Resource (the escalation mutex, a lock, or a transaction).
+type WaitEdge struct {
+	Waiter    string    `json:"waiter"`
+	Holder    string    `json:"holder"`
+	Resource  string    `json:"resource"`
+	Since     time.Time `json:"since"`
+	WaitingMS int64     `json:"waiting_ms"`
+}
+
+type WaitGraphReport struct {
+	Edges   []WaitEdge   `json:"edges"`
+	Cycles  [][]WaitEdge `json:"cycles"`  // Deadlocks: each chain ends where it starts
+	Stalled []WaitEdge   `json:"stalled"` // Waits older than waitStallAfter
+}
+
+type BreakDeadlockArgs struct {
+	Victim    string `json:"victim"` // A waiter or holder on a reported chain
+	Approver  string `json:"approver"`
+	Reason    string `json:"reason"`
+	Signature string `json:"signature"`
+}
+
+type DeleteArgs struct {
+	IntentID       string   `json:"intent_id"`
+	IdempotencyKey string   `json:"idempotency_key"`
+	UUIDs          []string `json:"uuids"`
+	Targets        []string `json:"targets,omitempty"` // Defaults to both engines
+	Grant          string   `json:"grant"`
+}
+
 type ObjectKind string
 
 const (
@@ -385,17 +655,53 @@ type VibeLock struct {
 	UUID      string    `json:"uuid"`
 	Type      LockType  `json:"type"`
 	Actor     Actor     `json:"actor"`
+	Owner     string    `json:"owner"`
+	Lease     string    `json:"lease,omitempty"`
+	Covers    []string  `json:"covers,omitempty"` // Descendants covered by a hierarchical lock
+	Renewals  int       `json:"renewals"`
 	Timestamp time.Time `json:"timestamp"`
 	ExpiresAt time.Time `json:"expires_at"`
 }
 
+// MirroredLock is one lock flag as the editors see it. Descendants covered by a
+// hierarchical lock get their own flag, naming the locked root in Via.
+type MirroredLock struct {
+	UUID      string    `json:"uuid"`
+	Type      LockType  `json:"type"`
+	Owner     string    `json:"owner"`
+	Label     string    `json:"label"`
+	Via       string    `json:"via,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
 type ApplyLockArgs struct {
-	UUID     string   `json:"uuid"`
-	LockType LockType `json:"lock_type"`
+	UUID        string   `json:"uuid"`
+	LockType    LockType `json:"lock_type"`
+	Owner       string   `json:"owner"`
+	Actor       Actor    `json:"actor,omitempty"`
+	TTLSeconds  int      `json:"ttl_seconds,omitempty"`
+	Lease       string   `json:"lease,omitempty"` // Required to refresh a lock already held
+}
+
+type RenewLockArgs struct {
+	UUID       string `json:"uuid"`
+	Owner      string `json:"owner"`
+	Lease      string `json:"lease"`
+	TTLSeconds int    `json:"ttl_seconds,omitempty"`
 }
 
 type ReleaseLockArgs struct {
-	UUID string `json:"uuid"`
+	UUID     string `json:"uuid"`
+	Owner    string `json:"owner"`
+	Lease    string `json:"lease,omitempty"`
+	Force     bool   `json:"force,omitempty"`
+	Approver  string `json:"approver,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	Signature string `json:"signature,omitempty"` // Approver's Human key, required to force
+}
+
+type InspectLocksArgs struct {
+	UUID string `json:"uuid,omitempty"`
 }
 
 type WalScope struct {
@@ -416,6 +722,46 @@ type WalRoll struct {
 	SnapshotRef string `json:"snapshot_ref,omitempty"`
 }
 
+// EngineOp is a single adapter call, used to express inverse operations when
+// an engine cannot hand back an undo token.
+type EngineOp struct {
+	Endpoint string                 `json:"endpoint"`
+	Payload  map[string]interface{} `json:"payload"`
+}
+
+type UndoStep struct {
+	Engine    string           `json:"engine"`
+	Endpoint  string           `json:"endpoint"`
+	Payload   interface{}      `json:"payload"`
+	UndoToken string           `json:"undo_token,omitempty"`
+	Inverse   *EngineOp        `json:"inverse,omitempty"`
+	Targets   []ObjectIdentity `json:"targets,omitempty"` // Identities (and prefab depths) the mutation aimed at
+}
+
+type UndoRecord struct {
+	ID            string       `json:"id"`
+	Op            string       `json:"op"`
+	IntentID      string       `json:"intent_id,omitempty"`
+	Agent         string       `json:"agent,omitempty"`
+	TransactionID string       `json:"transaction_id,omitempty"`
+	Steps         []UndoStep   `json:"steps"`
+	SnapshotRef   string       `json:"snapshot_ref,omitempty"`
+	Opcodes       []VibeOpcode `json:"opcodes,omitempty"`      // What the captured mutations were granted for
+	Scope         []string     `json:"scope,omitempty"`        // UUIDs their conflict claims covered
+	Class         IntentClass  `json:"intent_class,omitempty"` // Strongest class among them
+	CommittedAt   time.Time    `json:"committed_at"`
+}
+
+// UndoArgs authorizes replaying the record on top of the undo (or redo)
+// stack: a grant minted for the record's intent, or a Human approver whose
+// signature names the record.
+type UndoArgs struct {
+	RecordID  string `json:"record_id,omitempty"` // If set, must be the record on top of the stack
+	Grant     string `json:"grant,omitempty"`
+	Approver  string `json:"approver,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
 type WorkOrder struct {
 	ID          string     `json:"id"`
 	MonotonicID int64      `json:"monotonic_id"`
@@ -424,6 +770,7 @@ type WorkOrder struct {
 	UUID        string     `json:"uuid"`
 	Description string     `json:"description"`
 	Context     map[string]interface{} `json:"context"` // Snapshot of relevant state
+	Grant       string     `json:"grant,omitempty"`  // Delegated capability grant for the Operator
 }
 
 type WorkResult struct {
@@ -449,6 +796,8 @@ type StrategicPlan struct {
 	Steps       []PlanStep   `json:"steps"`
 	Rationale   string       `json:"rationale"`
 	TotalBudget int          `json:"total_budget_ms"`
+	AgentID     string       `json:"agent_id"`  // Foreman that proposed the plan
+	Signature   string       `json:"signature"` // HMAC of the plan with signature blanked
 }
 
 type PlanStep struct {
@@ -459,6 +808,57 @@ type PlanStep struct {
 	UUID        string     `json:"uuid"`
 }
 
+type PlanStatus string
+
+const (
+	PlanProposed  PlanStatus = "PROPOSED"
+	PlanApproved  PlanStatus = "APPROVED"
+	PlanRunning   PlanStatus = "RUNNING"
+	PlanPaused    PlanStatus = "PAUSED"
+	PlanCompleted PlanStatus = "COMPLETED"
+	PlanAborted   PlanStatus = "ABORTED"
+	PlanRejected  PlanStatus = "REJECTED"
+)
+
+type PlanStepState struct {
+	StepID      int       `json:"step_id"`
+	Status      string    `json:"status"` // PENDING | DISPATCHED | DONE | FAILED | ROLLED_BACK
+	IntentID    string    `json:"intent_id,omitempty"`
+	WorkOrderID string    `json:"work_order_id,omitempty"`
+	StartedAt   time.Time `json:"started_at,omitempty"`
+	FinishedAt  time.Time `json:"finished_at,omitempty"`
+	ConsumedMS  int       `json:"consumed_ms"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// PlanRecord is a stored StrategicPlan and its execution progress. Hash is the
+// value step intents carry as IntentEnvelope.PlanHash.
+type PlanRecord struct {
+	Plan         StrategicPlan   `json:"plan"`
+	Hash         string          `json:"plan_hash"`
+	Status       PlanStatus      `json:"status"`
+	Cursor       int             `json:"cursor"` // index of the step being (or next to be) run
+	Steps        []PlanStepState `json:"steps"`
+	BudgetUsedMS int             `json:"budget_used_ms"`
+	Agent        AgentIdentity   `json:"agent"` // Verified proposer; step intents and their grants belong to it
+	Approver     string          `json:"approver,omitempty"`
+	Reason       string          `json:"reason,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+type PlanDecisionArgs struct {
+	PlanID    string `json:"plan_id"`
+	Approver  string `json:"approver"`
+	Reason    string `json:"reason"`
+	Signature string `json:"signature"`
+}
+
+type PlanControlArgs struct {
+	PlanID string `json:"plan_id"`
+	Reason string `json:"reason,omitempty"`
+}
+
 type MutationIntegrityArgs struct {
 	UUID     string     `json:"uuid"`
 	Opcode   VibeOpcode `json:"opcode"`
@@ -471,6 +871,115 @@ type ForensicSnapshotArgs struct {
 }
 
 type EntropyBudget struct {
-	Limit int `json:"limit"`
-	Used  int `json:"used"`
+	Actor       string    `json:"actor"`
+	Limit       int       `json:"limit"`
+	Used        int       `json:"used"`
+	RefilledAt  time.Time `json:"refilled_at"`
+	LowNotified bool      `json:"low_notified,omitempty"`
+}
+
+type DriftDeviation struct {
+	Kind   string    `json:"kind"`
+	Detail string    `json:"detail"`
+	At     time.Time `json:"at"`
+}
+
+// DriftRecord is one agent's protocol deviation tally (THIRD_ORDER_INVARIANTS §5).
+type DriftRecord struct {
+	AgentID      string           `json:"agent_id"`
+	Allowed      int              `json:"allowed_deviations"`
+	Count        int              `json:"count"`
+	Locked       bool             `json:"locked"`
+	LockedAt     time.Time        `json:"locked_at,omitempty"`
+	Recent       []DriftDeviation `json:"recent,omitempty"`
+	RemediatedBy string           `json:"remediated_by,omitempty"`
+	RemediatedAt time.Time        `json:"remediated_at,omitempty"`
+}
+
+type DriftQueryArgs struct {
+	AgentID string `json:"agent_id,omitempty"`
+}
+
+type DriftRemediationArgs struct {
+	AgentID   string `json:"agent_id"`
+	Approver  string `json:"approver"`
+	Reason    string `json:"reason"`
+	Allowed   int    `json:"allowed_deviations,omitempty"`
+	Signature string `json:"signature"`
+}
+
+// FactEvidence cites something the orchestrator can re-check: a WAL entry
+// hash (source "wal"), an engine state hash ("unity", "blender") or the
+// bridge verification hash ("bridge").
+type FactEvidence struct {
+	Source string `json:"source"`
+	Hash   string `json:"hash"`
+}
+
+type Fact struct {
+	ID           string         `json:"id"`
+	Subject      string         `json:"subject"`
+	Claim        string         `json:"claim"`
+	AgentID      string         `json:"agent_id,omitempty"`
+	Evidence     []FactEvidence `json:"evidence"`
+	Status       string         `json:"status"`
+	Confidence   float64        `json:"confidence"`
+	ObservedHead string         `json:"observed_head"`
+	Reason       string         `json:"reason,omitempty"`
+	RecordedAt   time.Time      `json:"recorded_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// RecordFactArgs is signed by the recording agent: Signature is the hex HMAC
+// of the arguments' canonical JSON with action "record_fact".
+type RecordFactArgs struct {
+	Subject   string         `json:"subject"`
+	Claim     string         `json:"claim"`
+	AgentID   string         `json:"agent_id"`
+	Evidence  []FactEvidence `json:"evidence"`
+	Signature string         `json:"signature"`
+}
+
+type ObserveFactArgs struct {
+	FactID   string         `json:"fact_id"`
+	Evidence []FactEvidence `json:"evidence"`
+}
+
+type FactQueryArgs struct {
+	Subject        string `json:"subject,omitempty"`
+	IncludeInvalid bool   `json:"include_invalid,omitempty"`
+}
+
+// LogWindow is a slice of recent WAL and event history together with the
+// anchors needed to recompute its hash later.
+type LogWindow struct {
+	LogHash    string   `json:"-"` // Never handed out: the agent must hash what it read
+	WalHead    string   `json:"wal_head"`
+	StateHead  string   `json:"state_head"`
+	EventCount int      `json:"event_count"`
+	Wal        []string `json:"wal"`
+	Events     []string `json:"events"`
+}
+
+// IngestLogsArgs is signed by the ingesting agent: Signature is the hex HMAC
+// of the arguments' canonical JSON with action "ingest_forensic_logs".
+type IngestLogsArgs struct {
+	LogHash    string `json:"log_hash"`
+	WalHead    string `json:"wal_head"`
+	EventCount int    `json:"event_count"`
+	AgentID    string `json:"agent_id"`
+	Signature  string `json:"signature"`
+}
+
+type EntropyQueryArgs struct {
+	Actor string `json:"actor,omitempty"`
+}
+
+type EntropyGrantArgs struct {
+	Actor     string `json:"actor"`
+	Amount    int    `json:"amount"`
+	Limit     int    `json:"limit,omitempty"`
+	Approver  string `json:"approver"`
+	Reason    string `json:"reason"`
+	Signature string `json:"signature"`
 }
@@ -0,0 +1,353 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Wait-For Graph (FAILURE_MODES.md §5)
+//
+// Three things can make one party wait on another: the escalation mutex
+// (failing pipelines queue behind the one handling its failure), object locks
+// (an intent parked in WAIT_HUMAN_LOCK waits on whoever holds its scope), and
+// open transactions (an intent whose scope overlaps another's transaction
+// waits for it to close). The graph is rebuilt from that live state every
+// deadlockScanInterval. A cycle is a deadlock; a wait older than
+// waitStallAfter is reported as stalled. Both are announced once with their
+// blocking chain, and only a human can break them, by naming a victim.
+const (
+	deadlockScanInterval = 5 * time.Second
+	waitStallAfter       = 2 * time.Minute
+)
+
+// escalationWait is a failing pipeline queued behind the escalation mutex.
+type escalationWait struct {
+	OrderID string
+	Engine  string
+	Since   time.Time
+}
+
+var (
+	escalationWaiters []escalationWait // FIFO; guarded by escalationMu
+
+	reportedWaits = make(map[string]bool) // Chains already announced
+	deadlockMu    sync.Mutex              // Leaf lock: guards reportedWaits
+)
+
+func pipelineNode(orderID string) string { return "pipeline:" + orderID }
+
+// claimEscalation gives orderID (running on engine) the escalation mutex if it
+// is free, or queues it. It reports whether orderID holds the mutex.
+func claimEscalation(engine, orderID string) bool {
+	escalationMu.Lock()
+	defer escalationMu.Unlock()
+	if escalatedPipeline == "" {
+		escalatedPipeline = orderID
+		log.Printf("🛡️ Escalation Mutex: Pipeline %s has claimed the error-handling lock.", orderID)
+	}
+	if escalatedPipeline == orderID { return true }
+	for _, w := range escalationWaiters {
+		if w.OrderID == orderID { return false }
+	}
+	escalationWaiters = append(escalationWaiters, escalationWait{OrderID: orderID, Engine: engine, Since: time.Now()})
+	return false
+}
+
+// settleEscalation is called when orderID stops failing, either because it
+// succeeded or because it went TERMINAL. A holder hands the mutex to the
+// oldest waiter; a waiter simply leaves the queue. The waiter's result file is
+// long gone, so the handoff itself moves it to HALTED with the permissions a
+// holder gets and tells its workers it may now be handled.
+func settleEscalation(orderID, reason string) {
+	escalationMu.Lock()
+	if escalatedPipeline != orderID {
+		for i, w := range escalationWaiters {
+			if w.OrderID == orderID { escalationWaiters = append(escalationWaiters[:i], escalationWaiters[i+1:]...); break }
+		}
+		escalationMu.Unlock()
+		return
+	}
+	var next escalationWait
+	if len(escalationWaiters) > 0 {
+		next = escalationWaiters[0]
+		escalationWaiters = escalationWaiters[1:]
+	}
+	escalatedPipeline = next.OrderID
+	escalationMu.Unlock()
+
+	log.Printf("🛡️ Escalation Mutex: Pipeline %s released the error-handling lock (%s); next: %q", orderID, reason, next.OrderID)
+	journalOperation(map[string]interface{}{"type": "escalation", "from": orderID, "to": next.OrderID, "reason": reason})
+	if next.OrderID == "" { return }
+	journalOperation(map[string]interface{}{
+		"type":             "work_result",
+		"engine":           next.Engine,
+		"order_id":         next.OrderID,
+		"status":           "FAILURE",
+		"phase":            PhaseHalted,
+		"reason":           "ESCALATION_HANDOFF",
+		"permissions_mask": haltedPermissions(),
+	})
+	dispatchVibeEvent(LevelWarn, "escalation_handoff", "", "HANDLE_FAILURE", map[string]interface{}{
+		"order_id":  next.OrderID,
+		"engine":    next.Engine,
+		"from":      orderID,
+		"waited_ms": time.Since(next.Since).Milliseconds(),
+	})
+}
+
+// haltedPermissions is the permissions mask of the pipeline holding the
+// escalation mutex: Flash may execute the fix, Pro may retry once.
+func haltedPermissions() PermissionsMask {
+	return PermissionsMask{
+		"Flash": RolePermissions{CanExecute: true, CanRetry: false},
+		"Pro":   RolePermissions{CanExecute: false, CanRetry: true, MaxRetries: 1},
+	}
+}
+
+// intentNode names the party behind an intent: its signing agent if known.
+func intentNode(rec *IntentRecord) string {
+	if rec.Agent.AgentID != "" { return rec.Agent.AgentID }
+	return "intent:" + rec.ID
+}
+
+func txNode(tx *VibeTransaction) string {
+	if tx.Agent != "" { return tx.Agent }
+	return "intent:" + tx.IntentID
+}
+
+func intentWaitScope(rec *IntentRecord) []string {
+	payload, _ := splitOpSpec(rec.OpSpec)
+	return dryRunScope(rec.Envelope, payload)
+}
+
+// buildWaitGraph collects every current wait from live state.
+func buildWaitGraph(now time.Time) []WaitEdge {
+	var edges []WaitEdge
+	add := func(waiter, holder, resource string, since time.Time) {
+		if waiter == holder || holder == "" { return }
+		edges = append(edges, WaitEdge{Waiter: waiter, Holder: holder, Resource: resource, Since: since, WaitingMS: now.Sub(since).Milliseconds()})
+	}
+
+	escalationMu.Lock()
+	for _, w := range escalationWaiters {
+		if escalatedPipeline != "" { add(pipelineNode(w.OrderID), pipelineNode(escalatedPipeline), "escalation", w.Since) }
+	}
+	escalationMu.Unlock()
+
+	type waiting struct {
+		node  string
+		scope []string
+		since time.Time
+	}
+	type open struct {
+		node, id string
+		scope    []string
+	}
+	var waiters []waiting
+	var txs []open
+	txMu.Lock()
+	for _, rec := range intents {
+		if rec.Status == IntentWaitHuman { waiters = append(waiters, waiting{intentNode(rec), intentWaitScope(rec), rec.UpdatedAt}) }
+	}
+	for iid, tx := range transactions {
+		var scope []string
+		if rec, ok := intents[iid]; ok { scope = intentWaitScope(rec) }
+		txs = append(txs, open{txNode(tx), tx.ID, scope})
+	}
+	txMu.Unlock()
+
+	lockMu.RLock()
+	for _, w := range waiters {
+		for _, id := range w.scope {
+			if l := lockCoveringLocked(id, now); l != nil { add(w.node, l.Owner, "lock:"+id, w.since) }
+		}
+	}
+	lockMu.RUnlock()
+	for _, w := range waiters {
+		for _, tx := range txs {
+			if overlaps(w.scope, tx.scope) { add(w.node, tx.node, "tx:"+tx.id, w.since) }
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Waiter != edges[j].Waiter { return edges[i].Waiter < edges[j].Waiter }
+		return edges[i].Resource < edges[j].Resource
+	})
+	return edges
+}
+
+// findWaitCycles returns each distinct cycle once, rotated to start at its
+// smallest waiter.
+func findWaitCycles(edges []WaitEdge) [][]WaitEdge {
+	out := map[string][]WaitEdge{}
+	for _, e := range edges { out[e.Waiter] = append(out[e.Waiter], e) }
+	nodes := make([]string, 0, len(out))
+	for n := range out { nodes = append(nodes, n) }
+	sort.Strings(nodes)
+
+	seen := map[string]bool{}
+	var cycles [][]WaitEdge
+	var path []WaitEdge
+	onPath := map[string]int{} // node -> index in path where it starts waiting
+	var walk func(n string)
+	walk = func(n string) {
+		onPath[n] = len(path)
+		for _, e := range out[n] {
+			if i, ok := onPath[e.Holder]; ok {
+				c := append([]WaitEdge(nil), path[i:]...)
+				c = append(c, e)
+				min := 0
+				for k := range c { if c[k].Waiter < c[min].Waiter { min = k } }
+				c = append(append([]WaitEdge(nil), c[min:]...), c[:min]...)
+				if key := waitChainKey(c); !seen[key] { seen[key] = true; cycles = append(cycles, c) }
+				continue
+			}
+			path = append(path, e)
+			walk(e.Holder)
+			path = path[:len(path)-1]
+		}
+		delete(onPath, n)
+	}
+	for _, n := range nodes { walk(n) }
+	return cycles
+}
+
+func waitChainKey(chain []WaitEdge) string {
+	parts := make([]string, len(chain))
+	for i, e := range chain { parts[i] = e.Waiter + "->" + e.Resource + "->" + e.Holder }
+	return strings.Join(parts, "|")
+}
+
+func waitGraphReport(now time.Time) WaitGraphReport {
+	r := WaitGraphReport{Edges: buildWaitGraph(now), Cycles: [][]WaitEdge{}, Stalled: []WaitEdge{}}
+	r.Cycles = append(r.Cycles, findWaitCycles(r.Edges)...)
+	for _, e := range r.Edges {
+		if now.Sub(e.Since) >= waitStallAfter { r.Stalled = append(r.Stalled, e) }
+	}
+	return r
+}
+
+// scanDeadlocks announces deadlocks and stalled waits that were not already
+// announced. A chain that clears and comes back is announced again.
+func scanDeadlocks() WaitGraphReport {
+	r := waitGraphReport(time.Now())
+	current := map[string]bool{}
+	var fresh [][]WaitEdge
+	var stalled []WaitEdge
+	deadlockMu.Lock()
+	for _, c := range r.Cycles {
+		k := "cycle:" + waitChainKey(c)
+		current[k] = true
+		if !reportedWaits[k] { fresh = append(fresh, c) }
+	}
+	for _, e := range r.Stalled {
+		k := "stall:" + waitChainKey([]WaitEdge{e})
+		current[k] = true
+		if !reportedWaits[k] { stalled = append(stalled, e) }
+	}
+	reportedWaits = current
+	deadlockMu.Unlock()
+
+	for _, c := range fresh {
+		log.Printf("🔒 Deadlock: %s", waitChainKey(c))
+		journalOperation(map[string]interface{}{"type": "deadlock", "chain": c})
+		dispatchVibeEvent(LevelError, "deadlock_detected", "", "HUMAN_INTERVENTION_REQUIRED", map[string]interface{}{"chain": c})
+	}
+	for _, e := range stalled {
+		log.Printf("⏳ Stalled Wait: %s has waited %s on %s held by %s", e.Waiter, time.Duration(e.WaitingMS)*time.Millisecond, e.Resource, e.Holder)
+		journalOperation(map[string]interface{}{"type": "deadlock", "stalled": e})
+		dispatchVibeEvent(LevelWarn, "wait_stalled", "", "HUMAN_INTERVENTION_REQUIRED", map[string]interface{}{"chain": []WaitEdge{e}})
+	}
+	return r
+}
+
+func startDeadlockMonitor() {
+	ticker := time.NewTicker(deadlockScanInterval)
+	for range ticker.C {
+		scanDeadlocks()
+	}
+}
+
+// breakDeadlock frees everything victim holds and withdraws everything it
+// waits on. The victim must be on a chain that is currently deadlocked or
+// stalled, so a human cannot use this to preempt arbitrary work.
+func breakDeadlock(ctx context.Context, args BreakDeadlockArgs) (map[string]interface{}, error) {
+	if args.Approver == "" || args.Reason == "" { return nil, fmt.Errorf("DEADLOCK_BREAK_INVALID: approver and reason are required") }
+	if err := verifyHuman(args.Approver, args.Signature, "break_deadlock", args); err != nil { return nil, err }
+	r := waitGraphReport(time.Now())
+	onChain := false
+	for _, chain := range append(r.Cycles, r.Stalled) {
+		for _, e := range chain {
+			if e.Waiter == args.Victim || e.Holder == args.Victim { onChain = true }
+		}
+	}
+	if !onChain { return nil, fmt.Errorf("DEADLOCK_NOT_FOUND: %s is not on a deadlocked or stalled chain", args.Victim) }
+	reason := "DEADLOCK_BROKEN: " + args.Reason
+	done := map[string]interface{}{}
+
+	if strings.HasPrefix(args.Victim, "pipeline:") {
+		settleEscalation(strings.TrimPrefix(args.Victim, "pipeline:"), reason)
+		done["escalation"] = "RELEASED"
+	}
+
+	var locks []string
+	lockMu.RLock()
+	for id, l := range lockTable {
+		if l.Owner == args.Victim { locks = append(locks, id) }
+	}
+	lockMu.RUnlock()
+	for _, id := range locks {
+		releaseLock(ReleaseLockArgs{UUID: id, Owner: "deadlock-breaker", Force: true, Approver: args.Approver, Reason: reason})
+	}
+	done["locks_released"] = locks
+
+	var aborted, withdrawn []string
+	txMu.Lock()
+	for iid, tx := range transactions {
+		if txNode(tx) == args.Victim { aborted = append(aborted, iid) }
+	}
+	for _, rec := range intents {
+		if rec.Status == IntentWaitHuman && intentNode(rec) == args.Victim { withdrawn = append(withdrawn, rec.ID) }
+	}
+	txMu.Unlock()
+	for _, iid := range aborted {
+		abort_atomic_operation(ctx, nil, AtomicOpArgs{IntentID: iid, Reason: reason})
+	}
+	for _, id := range withdrawn { transitionIntent(id, IntentRejected, reason) }
+	done["transactions_aborted"], done["intents_withdrawn"] = aborted, withdrawn
+
+	journalOperation(map[string]interface{}{"type": "deadlock_broken", "victim": args.Victim, "approver": args.Approver, "reason": args.Reason, "released": done})
+	dispatchVibeEvent(LevelWarn, "deadlock_broken", "", "NOTIFY_OWNER", map[string]interface{}{"victim": args.Victim, "approver": args.Approver, "released": done})
+	log.Printf("🔓 Deadlock: %s broken by %s (%s)", args.Victim, args.Approver, args.Reason)
+	return done, nil
+}
+
+func inspect_wait_graph(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+	return wrapForensicResult(waitGraphReport(time.Now())), nil, nil
+}
+
+func break_deadlock(ctx context.Context, req *mcp.CallToolRequest, args BreakDeadlockArgs) (*mcp.CallToolResult, any, error) {
+	res, err := breakDeadlock(ctx, args)
+	if err != nil { return nil, nil, err }
+	return wrapForensicResult(res), nil, nil
+}
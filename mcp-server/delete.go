@@ -0,0 +1,179 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Closure-Aware Destructive Deletes (PATHOLOGICAL_EDGE_CASES.md §3)
+//
+// A delete never targets a bare UUID. The orchestrator reloads each engine's
+// hierarchy, expands the requested roots into their delete closure (every
+// descendant, plus the constraints and modifiers that target a member), and
+// sends the whole closure in one atomic object/delete per engine. A .git_safety
+// snapshot is taken first and carried in WalRoll.SnapshotRef. If any engine
+// refuses, or a member survives the delete, every engine is rewound through
+// its undo record; if that fails too, the delete is quarantined for a human.
+// The snapshot only covers project files, never the scenes, so it is not a
+// restore path.
+const deleteEndpoint = "object/delete"
+
+// deleteClosure expands roots into everything that must go with them, sorted.
+// A root the engine does not know is refused: deleting it would leave a hole
+// the orchestrator cannot account for.
+func deleteClosure(g *hierarchyGraph, roots []string) ([]string, error) {
+	hierarchyMu.Lock()
+	defer hierarchyMu.Unlock()
+	seen := map[string]bool{}
+	var out []string
+	add := func(id string) {
+		if seen[id] { return }
+		seen[id] = true
+		out = append(out, id)
+	}
+	for _, r := range roots {
+		if _, ok := g.Parent[r]; !ok { return nil, fmt.Errorf("DELETE_CLOSURE_UNKNOWN: %s is not in the engine hierarchy", r) }
+		add(r)
+		for _, d := range g.descendants(r) { add(d) }
+	}
+	for _, id := range append([]string(nil), out...) {
+		for _, dep := range g.Dependents[id] { add(dep) }
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// delete_objects deletes uuids and their closure on every target engine as
+// one destructive, snapshot-gated mutation.
+func delete_objects(ctx context.Context, req *mcp.CallToolRequest, args DeleteArgs) (*mcp.CallToolResult, any, error) {
+	if len(args.UUIDs) == 0 { return nil, nil, fmt.Errorf("DELETE_EMPTY: no UUIDs given") }
+	targets := args.Targets
+	if len(targets) == 0 { targets = []string{"unity", "blender"} }
+	targets = append([]string(nil), targets...)
+	sort.Strings(targets)
+
+	// A delete runs only under its own submitted intent, and that intent must
+	// name the delete opcode: the grant is checked against it below.
+	intent, err := lookupIntent(args.IntentID)
+	if err != nil { return nil, nil, fmt.Errorf("%v: delete_objects needs its submitted intent (%s)", err, args.IntentID) }
+	txMu.Lock(); env, status, reason := intent.Envelope, intent.Status, intent.Reason; txMu.Unlock()
+	if status != IntentValidated && status != IntentApproved && status != IntentExecuting {
+		return nil, nil, fmt.Errorf("INTENT_%s: delete_objects only runs admitted intents %s", status, reason)
+	}
+	if env.Opcode != OpDelete { return nil, nil, fmt.Errorf("CAPABILITY_DENIED: intent %s carries opcode %X, delete_objects requires %X", args.IntentID, env.Opcode, OpDelete) }
+
+	// The closure is computed from a fresh hierarchy; a cached one could miss
+	// children added since.
+	closures := map[string][]string{}
+	var scope []string
+	for _, t := range targets {
+		g, err := refreshHierarchy(ctx, t)
+		if err != nil { return nil, nil, fmt.Errorf("DELETE_CLOSURE_UNKNOWN: cannot load %s hierarchy: %v", t, err) }
+		c, err := deleteClosure(g, args.UUIDs)
+		if err != nil { return nil, nil, err }
+		closures[t] = c
+		for _, id := range c { if !containsString(scope, id) { scope = append(scope, id) } }
+	}
+	sort.Strings(scope)
+
+	g, err := checkGrant(args.Grant, targets, OpDelete, scope); if err != nil { return nil, nil, err }
+	if g.IntentID != args.IntentID { return nil, nil, fmt.Errorf("CAPABILITY_DENIED: Grant %s was minted for intent %q, not %s", g.ID, g.IntentID, args.IntentID) }
+	if d := evaluatePolicy(env, ClassDestructive); !d.Allowed { return nil, nil, fmt.Errorf("%s", d.Reason) }
+	if err := checkPerimeter(ClassDestructive, deleteEndpoint); err != nil { return nil, nil, err }
+	targetHash := fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(scope, ","))))
+	if err := checkInvariants(g.Holder, ClassDestructive, args.IdempotencyKey, targetHash); err != nil { return nil, nil, noteDeviation(g.Holder, err) }
+
+	ref, err := safetySnapshot("Pre-Delete: " + args.IntentID)
+	if err != nil { return nil, nil, err }
+	if ref == "" { return nil, nil, fmt.Errorf("SNAPSHOT_REQUIRED: destructive delete needs a .git_safety reference") }
+	rec := newUndoRecord("delete_objects", OpDelete, args.IntentID, g.Holder)
+	undoMu.Lock(); rec.SnapshotRef = ref; undoMu.Unlock()
+
+	claim := newConflictClaim(args.IntentID, g.Holder, ClassDestructive, scope, nil, rec)
+	if err := admitMutation(ctx, claim); err != nil { return nil, nil, err }
+	bctx, finish, err := budgetContext(ctx, args.IntentID); if err != nil { return nil, nil, err }
+	defer finish()
+
+	var failure error
+	for _, t := range targets {
+		if _, err := sendMutation(bctx, rec, t, deleteEndpoint, map[string]interface{}{"uuids": closures[t], "atomic": true}); err != nil {
+			failure = fmt.Errorf("%s refused delete: %v", t, err)
+			break
+		}
+	}
+	if failure == nil {
+		failure = verifyDeleted(bctx, closures)
+	}
+	if failure != nil {
+		phase := restoreDelete(ctx, rec, targets)
+		journalDelete(args.IntentID, scope, closures, phase, ref, failure.Error())
+		return nil, nil, fmt.Errorf("DELETE_%s: %v", phase, failure)
+	}
+
+	sealUndoRecord(rec)
+	journalDelete(args.IntentID, scope, closures, PhaseFinal, ref, "")
+	log.Printf("💀 Delete Closure: %d objects removed from %v (snapshot %s)", len(scope), targets, ref)
+	return wrapForensicResult(map[string]interface{}{"status": "DELETED", "closure": closures, "snapshot_ref": ref}), nil, nil
+}
+
+// verifyDeleted reloads each engine's hierarchy and fails if any closure
+// member is still there.
+func verifyDeleted(ctx context.Context, closures map[string][]string) error {
+	for t, c := range closures {
+		g, err := refreshHierarchy(ctx, t)
+		if err != nil { return fmt.Errorf("%s could not verify delete: %v", t, err) }
+		hierarchyMu.Lock()
+		var survivors []string
+		for _, id := range c { if _, ok := g.Parent[id]; ok { survivors = append(survivors, id) } }
+		hierarchyMu.Unlock()
+		if len(survivors) > 0 { return fmt.Errorf("%s kept %v after delete", t, survivors) }
+	}
+	return nil
+}
+
+// restoreDelete puts back whatever part of the closure was deleted, through
+// each engine's own undo. It returns the phase the delete ends in. A rewind
+// that fails leaves the scenes for a human: the intent is quarantined and
+// nothing else is attempted.
+func restoreDelete(ctx context.Context, rec *UndoRecord, targets []string) WalPhase {
+	defer func() { for _, t := range targets { markHierarchyStale(t) } }()
+	if len(rec.Steps) == 0 { return PhaseRolledBack }
+	err := rewindRecord(context.WithoutCancel(ctx), rec)
+	if err == nil { return PhaseRolledBack }
+	log.Printf("💀 Delete Closure: rewind failed (%v); quarantining %s", err, rec.IntentID)
+	if rec.IntentID != "" { transitionIntent(rec.IntentID, IntentQuarantined, "DELETE_RESTORE_FAILED: "+err.Error()) }
+	dispatchVibeEvent(LevelError, "delete_restore_failed", rec.IntentID, "HUMAN_INTERVENTION_REQUIRED", map[string]interface{}{"snapshot_ref": rec.SnapshotRef, "rewind_error": err.Error()})
+	return PhaseQuarantined
+}
+
+func journalDelete(intentID string, scope []string, closures map[string][]string, phase WalPhase, ref, reason string) {
+	journalOperation(map[string]interface{}{
+		"type":     "delete_closure",
+		"intent":   intentID,
+		"phase":    phase,
+		"scope":    WalScope{UUIDs: scope, Class: ClassDestructive},
+		"closure":  closures,
+		"rollback": WalRoll{SnapshotRef: ref},
+		"reason":   reason,
+	})
+}
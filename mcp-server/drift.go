@@ -0,0 +1,181 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Drift Budget (THIRD_ORDER_INVARIANTS.md §5)
+//
+// Protocol deviations are tallied per agent. Unlike entropy, drift never
+// replenishes on its own: once an agent exceeds its allowed deviations its
+// grants stop working and only human_remediate_drift restores them.
+const (
+	DriftFile                = PersistenceDir + "/drift.json"
+	defaultAllowedDeviations = 5
+	driftHistoryLimit        = 20
+)
+
+const (
+	DriftLogIngestion      = "LOG_INGESTION_REQUIRED"
+	DriftIntentMismatch    = "INTENT_MISMATCH"
+	DriftSecurityIntercept = "SECURITY_INTERCEPT"
+	DriftIdempotency       = "IDEMPOTENCY_BREACH"
+)
+
+var (
+	driftRecords = make(map[string]*DriftRecord) // agent ID -> record
+	driftMu      sync.Mutex
+)
+
+// deviationKind classifies an error as a protocol deviation, or returns ""
+// for ordinary failures (engine down, expired intent, ...) that are not the
+// agent's fault.
+func deviationKind(err error) string {
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "LOG_INGESTION_REQUIRED"):
+		return DriftLogIngestion
+	case strings.HasPrefix(msg, "INTENT_MISMATCH"):
+		return DriftIntentMismatch
+	case strings.HasPrefix(msg, "SECURITY_VIOLATION"), strings.HasPrefix(msg, "NUMERICAL_INSTABILITY"):
+		return DriftSecurityIntercept
+	case strings.Contains(msg, "Idempotency breach"):
+		return DriftIdempotency
+	}
+	return ""
+}
+
+func driftRecordLocked(agent string) *DriftRecord {
+	r, ok := driftRecords[agent]
+	if !ok {
+		r = &DriftRecord{AgentID: agent, Allowed: defaultAllowedDeviations}
+		driftRecords[agent] = r
+	}
+	return r
+}
+
+// noteDeviation charges err to agent's drift budget if it is a protocol
+// deviation and returns err unchanged, so callers can wrap their returns.
+func noteDeviation(agent string, err error) error {
+	if err == nil || agent == "" { return err }
+	kind := deviationKind(err)
+	if kind == "" { return err }
+
+	driftMu.Lock()
+	r := driftRecordLocked(agent)
+	r.Count++
+	r.Recent = append(r.Recent, DriftDeviation{Kind: kind, Detail: err.Error(), At: time.Now()})
+	if len(r.Recent) > driftHistoryLimit { r.Recent = r.Recent[len(r.Recent)-driftHistoryLimit:] }
+	locked := !r.Locked && r.Count > r.Allowed
+	if locked { r.Locked, r.LockedAt = true, time.Now() }
+	count, allowed := r.Count, r.Allowed
+	saveDriftLocked()
+	driftMu.Unlock()
+
+	journalOperation(map[string]interface{}{
+		"type":    "protocol_deviation",
+		"agent":   agent,
+		"kind":    kind,
+		"detail":  err.Error(),
+		"count":   count,
+		"allowed": allowed,
+		"locked":  locked,
+	})
+	if locked {
+		log.Printf("🧭 Drift Budget: %s exceeded %d deviations, mutation rights locked", agent, allowed)
+		dispatchVibeEvent(LevelError, "drift_budget_exhausted", "", "HUMAN_INTERVENTION_REQUIRED", map[string]interface{}{"agent": agent, "count": count, "allowed": allowed, "last": kind})
+	}
+	return err
+}
+
+// checkDriftLock refuses mutation rights to an agent over its drift budget.
+func checkDriftLock(agent string) error {
+	driftMu.Lock()
+	defer driftMu.Unlock()
+	if r, ok := driftRecords[agent]; ok && r.Locked {
+		return fmt.Errorf("DRIFT_LOCKED: %s exceeded its drift budget (%d/%d) and awaits human remediation", agent, r.Count, r.Allowed)
+	}
+	return nil
+}
+
+func saveDriftLocked() {
+	data, err := json.MarshalIndent(driftRecords, "", "  ")
+	if err != nil { return }
+	os.WriteFile(DriftFile, data, 0644)
+}
+
+func loadDrift() {
+	data, err := os.ReadFile(DriftFile)
+	if err != nil { return }
+	loaded := make(map[string]*DriftRecord)
+	if err := json.Unmarshal(data, &loaded); err != nil { return }
+	driftMu.Lock()
+	defer driftMu.Unlock()
+	for agent, r := range loaded { r.AgentID = agent; driftRecords[agent] = r }
+}
+
+func get_drift_status(ctx context.Context, req *mcp.CallToolRequest, args DriftQueryArgs) (*mcp.CallToolResult, any, error) {
+	driftMu.Lock()
+	defer driftMu.Unlock()
+	if args.AgentID != "" { return wrapForensicResult(*driftRecordLocked(args.AgentID)), nil, nil }
+	out := make([]DriftRecord, 0, len(driftRecords))
+	for _, r := range driftRecords { out = append(out, *r) }
+	sort.Slice(out, func(i, j int) bool { return out[i].AgentID < out[j].AgentID })
+	return wrapForensicResult(out), nil, nil
+}
+
+// human_remediate_drift clears an agent's deviation tally and restores its
+// mutation rights. The reviewer may also change how many deviations it is
+// allowed from now on.
+func human_remediate_drift(ctx context.Context, req *mcp.CallToolRequest, args DriftRemediationArgs) (*mcp.CallToolResult, any, error) {
+	if args.AgentID == "" || args.Approver == "" || args.Reason == "" {
+		return nil, nil, fmt.Errorf("REMEDIATION_INVALID: agent_id, approver and reason are required")
+	}
+	if args.Allowed < 0 { return nil, nil, fmt.Errorf("REMEDIATION_INVALID: allowed must not be negative") }
+	if err := verifyHuman(args.Approver, args.Signature, "remediate_drift", args); err != nil { return nil, nil, err }
+
+	driftMu.Lock()
+	r := driftRecordLocked(args.AgentID)
+	prev := r.Count
+	r.Count, r.Locked, r.LockedAt = 0, false, time.Time{}
+	if args.Allowed > 0 { r.Allowed = args.Allowed }
+	r.RemediatedBy, r.RemediatedAt = args.Approver, time.Now()
+	out := *r
+	saveDriftLocked()
+	driftMu.Unlock()
+
+	journalOperation(map[string]interface{}{
+		"type":     "drift_remediation",
+		"agent":    args.AgentID,
+		"cleared":  prev,
+		"allowed":  out.Allowed,
+		"approver": args.Approver,
+		"reason":   args.Reason,
+	})
+	log.Printf("🧭 Drift Budget: %s remediated by %s (%d deviations cleared)", args.AgentID, args.Approver, prev)
+	return wrapForensicResult(out), nil, nil
+}
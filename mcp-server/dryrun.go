@@ -0,0 +1,156 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// dryRunIntent walks an envelope through the full intent pipeline without
+// storing it or mutating either engine. Every gate is evaluated (not just up
+// to the first failure) so reviewers see the complete picture, and each
+// affected engine's preflight endpoint is asked for the hash it expects to
+// reach if the operation were applied. An envelope whose signature does not
+// verify never reaches an engine; its preflights are reported as skipped.
+func dryRunIntent(ctx context.Context, env IntentEnvelope, opSpec map[string]interface{}) DryRunReport {
+	report := DryRunReport{Intent: env.Intent, Opcode: env.Opcode, Class: classifyOpSpec(env.Opcode, opSpec), Predicted: []PredictedChange{}}
+
+	record := func(name string, err error) {
+		c := DryRunCheck{Name: name, Passed: err == nil}
+		if err != nil { c.Reason = err.Error() }
+		report.Checks = append(report.Checks, c)
+	}
+
+	var signatureErr error
+	for _, c := range intentChecks {
+		err := c.run(env)
+		if c.name == "signature" { signatureErr = err }
+		record(c.name, err)
+	}
+
+	var classErr error
+	d := evaluatePolicy(env, report.Class)
+	if !d.Allowed { classErr = fmt.Errorf("%s", d.Reason) }
+	record("class", classErr)
+
+	var approvalErr error
+	if d.RequiredApprovals > 0 {
+		approvalErr = fmt.Errorf("HUMAN_INTERVENTION_REQUIRED: Rule %s requires %d approval(s) %s", d.RuleID, d.RequiredApprovals, d.Reason)
+	}
+	record("approval", approvalErr)
+
+	payload, targets := splitOpSpec(opSpec)
+	uuids := dryRunScope(env, payload)
+	for _, id := range uuids { record("lock:"+id, checkHumanLock(id)) }
+	record("conflict", checkInFlightConflict(uuids))
+	record("hierarchy", checkOpSpecHierarchy(ctx, opSpec))
+
+	// Preflights spend the same budget the real run would get.
+	if env.BudgetMS > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(env.BudgetMS)*time.Millisecond)
+		defer cancel()
+	}
+	props := predictedProperties(payload)
+	for _, t := range targets {
+		var res map[string]interface{}
+		err := fmt.Errorf("PREFLIGHT_SKIPPED: %v", signatureErr)
+		if signatureErr == nil {
+			res, err = sendToEngine(ctx, t, "preflight/run", "POST", map[string]interface{}{
+				"dry_run": true,
+				"intent":  env.Intent,
+				"opcode":  env.Opcode,
+				"uuids":   uuids,
+				"payload": payload,
+			})
+		}
+		if err == nil && res != nil && res["error"] != nil { err = fmt.Errorf("PREFLIGHT_REJECTED: %v", res["error"]) }
+		record("preflight:"+t, err)
+
+		change := PredictedChange{Engine: t, UUIDs: uuids, Properties: props}
+		if err == nil && res != nil && res["hash"] != nil { change.ExpectedHash = fmt.Sprintf("%v", res["hash"]) }
+		report.Predicted = append(report.Predicted, change)
+	}
+
+	report.WouldCommit = true
+	for _, c := range report.Checks {
+		if !c.Passed { report.WouldCommit = false; break }
+	}
+
+	log.Printf("🧪 Dry Run: intent %s (opcode %X) -> would_commit=%v", env.Intent, env.Opcode, report.WouldCommit)
+	dispatchVibeEvent(LevelInfo, "dry_run_evaluated", "", "REVIEW", map[string]interface{}{"intent": env.Intent, "would_commit": report.WouldCommit})
+	return report
+}
+
+// splitOpSpec separates a governed op spec ({target, endpoint, payload}) into
+// the engine payload and the engines it would reach. A spec without an explicit
+// target is assumed to be mirrored to both engines.
+func splitOpSpec(opSpec map[string]interface{}) (map[string]interface{}, []string) {
+	targets := []string{"unity", "blender"}
+	if t, ok := opSpec["target"].(string); ok && t != "" { targets = []string{t} }
+
+	if p, ok := opSpec["payload"].(map[string]interface{}); ok { return p, targets }
+	payload := make(map[string]interface{})
+	for k, v := range opSpec {
+		if k == "target" || k == "endpoint" { continue }
+		payload[k] = v
+	}
+	return payload, targets
+}
+
+func dryRunScope(env IntentEnvelope, payload map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	var uuids []string
+	add := func(id string) {
+		if id == "" || seen[id] { return }
+		seen[id] = true
+		uuids = append(uuids, id)
+	}
+	for _, id := range env.Scope { add(id) }
+	if id, ok := payload["id"].(string); ok { add(id) }
+	if id, ok := payload["uuid"].(string); ok { add(id) }
+	return uuids
+}
+
+func predictedProperties(payload map[string]interface{}) []string {
+	props := []string{}
+	for k := range payload {
+		if k == "id" || k == "uuid" || k == "ids" { continue }
+		props = append(props, k)
+	}
+	sort.Strings(props)
+	return props
+}
+
+// checkInFlightConflict reports overlap with intents that currently hold an
+// open atomic transaction.
+func checkInFlightConflict(uuids []string) error {
+	txMu.Lock()
+	defer txMu.Unlock()
+	for intentID := range transactions {
+		rec, ok := intents[intentID]
+		if !ok { continue }
+		for _, scoped := range rec.Envelope.Scope {
+			for _, id := range uuids {
+				if scoped == id { return fmt.Errorf("CONFLICT: UUID %s is held by in-flight intent %s", id, intentID) }
+			}
+		}
+	}
+	return nil
+}
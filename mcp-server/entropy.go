@@ -0,0 +1,166 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Entropy Budgets (SECOND_ORDER_INVARIANTS.md §4)
+//
+// Every actor (the agent holding the grant a mutation runs under) has its own
+// budget. Mutations are charged by intent class, spent entropy trickles back
+// at entropyRefillInterval per point, and a human can top a budget up. Budgets
+// are persisted so a restart neither forgives nor forgets.
+const (
+	EntropyFile           = PersistenceDir + "/entropy.json"
+	defaultEntropyLimit   = 100
+	entropyRefillInterval = 6 * time.Second // 10 points per minute
+	unattributedActor     = "unattributed"
+)
+
+var entropyWeights = map[IntentClass]int{
+	ClassCosmetic:    1,
+	ClassStructural:  3,
+	ClassDestructive: 10,
+}
+
+var entropyBudgets = make(map[string]*EntropyBudget) // actor -> budget, guarded by entropyMu
+
+// entropyBudgetLocked returns the budget for actor, creating it on first use
+// and crediting any entropy replenished since it was last touched.
+func entropyBudgetLocked(actor string) *EntropyBudget {
+	now := time.Now()
+	b, ok := entropyBudgets[actor]
+	if !ok {
+		b = &EntropyBudget{Actor: actor, Limit: defaultEntropyLimit, RefilledAt: now}
+		entropyBudgets[actor] = b
+	}
+	if n := int(now.Sub(b.RefilledAt) / entropyRefillInterval); n > 0 {
+		b.Used -= n
+		b.RefilledAt = b.RefilledAt.Add(time.Duration(n) * entropyRefillInterval)
+	}
+	if b.Used <= 0 { b.Used, b.RefilledAt = 0, now }
+	if b.Limit-b.Used > b.Limit/5 { b.LowNotified = false }
+	return b
+}
+
+// chargeEntropy spends the class weight of one mutation from actor's budget.
+func chargeEntropy(actor string, class IntentClass) error {
+	if actor == "" { actor = unattributedActor }
+	cost, ok := entropyWeights[class]
+	if !ok { cost = entropyWeights[ClassStructural] }
+
+	entropyMu.Lock()
+	b := entropyBudgetLocked(actor)
+	if b.Used+cost > b.Limit {
+		used, limit := b.Used, b.Limit
+		entropyMu.Unlock()
+		dispatchVibeEvent(LevelError, "entropy_exhausted", "", "HUMAN_INTERVENTION_REQUIRED", map[string]interface{}{"actor": actor, "used": used, "limit": limit, "cost": cost})
+		return fmt.Errorf("INVARIANT_VIOLATION: Entropy budget exhausted for %s (%d/%d, %s costs %d)", actor, used, limit, class, cost)
+	}
+	b.Used += cost
+	low := !b.LowNotified && b.Limit-b.Used <= b.Limit/5
+	if low { b.LowNotified = true }
+	used, limit := b.Used, b.Limit
+	saveEntropyLocked()
+	entropyMu.Unlock()
+
+	if low {
+		log.Printf("🔋 Entropy Budget: %s is running low (%d/%d)", actor, used, limit)
+		dispatchVibeEvent(LevelWarn, "entropy_low", "", "SLOW_DOWN", map[string]interface{}{"actor": actor, "used": used, "limit": limit})
+	}
+	return nil
+}
+
+// entropySnapshot returns every budget, replenished to now, sorted by actor.
+func entropySnapshot() []EntropyBudget {
+	entropyMu.Lock()
+	defer entropyMu.Unlock()
+	out := make([]EntropyBudget, 0, len(entropyBudgets))
+	for actor := range entropyBudgets { out = append(out, *entropyBudgetLocked(actor)) }
+	sort.Slice(out, func(i, j int) bool { return out[i].Actor < out[j].Actor })
+	return out
+}
+
+// entropyTotals sums all budgets for the one-line pulse displays.
+func entropyTotals() (used, limit int) {
+	for _, b := range entropySnapshot() { used, limit = used+b.Used, limit+b.Limit }
+	if limit == 0 { limit = defaultEntropyLimit }
+	return used, limit
+}
+
+func saveEntropyLocked() {
+	data, err := json.MarshalIndent(entropyBudgets, "", "  ")
+	if err != nil { return }
+	os.WriteFile(EntropyFile, data, 0644)
+}
+
+func loadEntropy() {
+	data, err := os.ReadFile(EntropyFile)
+	if err != nil { return }
+	loaded := make(map[string]*EntropyBudget)
+	if err := json.Unmarshal(data, &loaded); err != nil { return }
+	entropyMu.Lock()
+	defer entropyMu.Unlock()
+	for actor, b := range loaded { b.Actor = actor; entropyBudgets[actor] = b }
+}
+
+func get_entropy_budgets(ctx context.Context, req *mcp.CallToolRequest, args EntropyQueryArgs) (*mcp.CallToolResult, any, error) {
+	if args.Actor == "" { return wrapForensicResult(entropySnapshot()), nil, nil }
+	entropyMu.Lock(); b := *entropyBudgetLocked(args.Actor); entropyMu.Unlock()
+	return wrapForensicResult(b), nil, nil
+}
+
+// human_grant_entropy returns spent entropy to an actor and optionally raises
+// or lowers its limit. Only humans top up budgets, so the grant is journaled
+// with the approver and reason.
+func human_grant_entropy(ctx context.Context, req *mcp.CallToolRequest, args EntropyGrantArgs) (*mcp.CallToolResult, any, error) {
+	if args.Actor == "" || args.Approver == "" || args.Reason == "" {
+		return nil, nil, fmt.Errorf("ENTROPY_GRANT_INVALID: actor, approver and reason are required")
+	}
+	if args.Amount < 0 || args.Limit < 0 { return nil, nil, fmt.Errorf("ENTROPY_GRANT_INVALID: amount and limit must not be negative") }
+	if err := verifyHuman(args.Approver, args.Signature, "grant_entropy", args); err != nil { return nil, nil, err }
+
+	entropyMu.Lock()
+	b := entropyBudgetLocked(args.Actor)
+	if args.Limit > 0 { b.Limit = args.Limit }
+	b.Used -= args.Amount
+	if b.Used < 0 { b.Used = 0 }
+	if b.Limit-b.Used > b.Limit/5 { b.LowNotified = false }
+	granted := *b
+	saveEntropyLocked()
+	entropyMu.Unlock()
+
+	journalOperation(map[string]interface{}{
+		"type":     "entropy_grant",
+		"actor":    args.Actor,
+		"amount":   args.Amount,
+		"limit":    granted.Limit,
+		"used":     granted.Used,
+		"approver": args.Approver,
+		"reason":   args.Reason,
+	})
+	log.Printf("🔋 Entropy Budget: %s granted %d by %s (%d/%d)", args.Actor, args.Amount, args.Approver, granted.Used, granted.Limit)
+	return wrapForensicResult(granted), nil, nil
+}
@@ -0,0 +1,392 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Fact Ledger (THIRD_ORDER_INVARIANTS.md §1, §2, §7)
+//
+// A fact is a claim about the scene backed by evidence the orchestrator can
+// check: WAL entry hashes, engine state hashes or the bridge verification
+// hash. Facts seen by fewer than two independent sources stay UNCONFIRMED.
+// Confidence halves every factHalfLifeOps state-changing WAL entries since the
+// fact was last observed, and a fact is INVALIDATED as soon as any hash it
+// cites stops holding.
+//
+// The WAL is indexed once at startup and then incrementally by
+// journalOperation, covering both the current file and the rotated one. A WAL
+// entry that has rotated out of both cannot be re-checked: the fact is not
+// invalidated for it, but its confidence decays to zero.
+const (
+	FactFile          = PersistenceDir + "/facts.json"
+	factHalfLifeOps   = 12
+	factSweepInterval = 10 * time.Second
+)
+
+const (
+	FactConfirmed   = "CONFIRMED"
+	FactUnconfirmed = "UNCONFIRMED"
+	FactInvalidated = "INVALIDATED"
+)
+
+var (
+	facts  = make(map[string]*Fact) // fact ID -> fact
+	factMu sync.Mutex
+
+	walIdx = newWalIndex() // Guarded by walMu; lock order factMu before walMu
+)
+
+// walIndex records where each WAL entry still on disk sits, how many
+// state-changing entries precede it, and when each subject last changed.
+type walIndex struct {
+	entries  map[string]walPos // entry hash -> position, for entries in wal.jsonl or wal.jsonl.old
+	order    []string          // hashes in entries, oldest first
+	touched  map[string]int    // subject id -> seq of its last state-changing entry
+	seq, ops int               // entries and state-changing entries indexed so far
+	stateHead string           // hash of the newest state-changing entry
+	base     int               // seq of the oldest entry still indexed
+	fileBase int               // seq of the first entry in the current WAL file
+}
+
+type walPos struct {
+	seq, ops int
+	subjects []string // objects the entry is about: its id and its scope
+}
+
+// walSubjects reads the objects a WAL entry is about from its JSON: its id,
+// its scope, and the scopes of the batch entries it carries.
+type walSubjects struct {
+	ID      string     `json:"id"`
+	Scope   WalScope   `json:"scope"`
+	Entries []WalEntry `json:"entries"`
+}
+
+func (w walSubjects) list() []string {
+	var out []string
+	if w.ID != "" { out = append(out, w.ID) }
+	for _, sc := range append([]WalScope{w.Scope}, entryScopes(w.Entries)...) { out = append(append(out, sc.UUIDs...), sc.ClosureUUIDs...) }
+	return out
+}
+
+func entryScopes(entries []WalEntry) []WalScope {
+	out := make([]WalScope, len(entries))
+	for i, e := range entries { out[i] = e.Scope }
+	return out
+}
+
+// covers reports whether the entry is about subject.
+func (p walPos) covers(subject string) bool {
+	for _, s := range p.subjects { if s == subject { return true } }
+	return false
+}
+
+func newWalIndex() *walIndex {
+	return &walIndex{entries: map[string]walPos{}, touched: map[string]int{}}
+}
+
+// add indexes the entry journalOperation just wrote.
+func (idx *walIndex) add(hash, typ string, subjects []string) {
+	if !auditOnlyJournalTypes[typ] {
+		idx.ops++
+		for _, s := range subjects { idx.touched[s] = idx.seq }
+		if hash != "" { idx.stateHead = hash }
+	}
+	if hash != "" { idx.entries[hash] = walPos{seq: idx.seq, ops: idx.ops, subjects: subjects}; idx.order = append(idx.order, hash) }
+	idx.seq++
+}
+
+// rotate follows the WAL file being renamed to wal.jsonl.old: entries of the
+// previous .old file are gone from disk and leave the index.
+func (idx *walIndex) rotate() {
+	for len(idx.order) > 0 && idx.entries[idx.order[0]].seq < idx.fileBase {
+		delete(idx.entries, idx.order[0])
+		idx.order = idx.order[1:]
+	}
+	idx.base, idx.fileBase = idx.fileBase, idx.seq
+}
+
+// opsSince counts state-changing entries after the entry with the given hash.
+// A hash that is no longer indexed (rotated away) counts as fully decayed.
+func (idx *walIndex) opsSince(hash string) int {
+	if hash == "" { return idx.ops }
+	p, ok := idx.entries[hash]
+	if !ok { return math.MaxInt32 }
+	return idx.ops - p.ops
+}
+
+// mutatedAfter reports whether subject was changed after the entry at hash.
+// For an entry that rotated away, only changes still indexed are known to be
+// later.
+func (idx *walIndex) mutatedAfter(subject, hash string) bool {
+	last, ok := idx.touched[subject]
+	if !ok { return false }
+	p, ok := idx.entries[hash]
+	if !ok { return last >= idx.base }
+	return last > p.seq
+}
+
+// indexWalFile feeds every entry of a WAL file to the index.
+func (idx *walIndex) indexWalFile(path string) {
+	f, err := os.Open(path)
+	if err != nil { return }
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	s.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for s.Scan() {
+		var e struct {
+			walSubjects
+			Hash string `json:"hash"`
+			Type string `json:"type"`
+		}
+		if json.Unmarshal(s.Bytes(), &e) != nil && e.Hash == "" { continue }
+		idx.add(e.Hash, e.Type, e.list())
+	}
+}
+
+// loadWalIndex indexes the rotated and the current WAL file, in that order,
+// and resumes the state head from them.
+func loadWalIndex() {
+	idx := newWalIndex()
+	idx.indexWalFile(WalFile + ".old")
+	idx.fileBase = idx.seq
+	idx.indexWalFile(WalFile)
+	walMu.Lock()
+	walIdx, lastStateHash = idx, idx.stateHead
+	walMu.Unlock()
+}
+
+// liveEvidenceHashes asks every non-WAL source cited in evidence for its hash
+// once. Sources that cannot be reached are left out.
+func liveEvidenceHashes(ctx context.Context, evidence []FactEvidence) map[string]string {
+	live := map[string]string{}
+	for _, ev := range evidence {
+		if _, done := live[ev.Source]; done || ev.Source == "wal" { continue }
+		if h, ok := currentEvidenceHash(ctx, ev.Source); ok { live[ev.Source] = h }
+	}
+	return live
+}
+
+// currentEvidenceHash is what a source reports right now. Engines that cannot
+// be reached yield ok=false: absence of an answer neither confirms nor refutes.
+func currentEvidenceHash(ctx context.Context, source string) (string, bool) {
+	switch source {
+	case "bridge":
+		return bridgeVerificationHash(), true
+	case "unity", "blender":
+		v, err := sendToEngine(ctx, source, "state/get", "GET", nil)
+		if err != nil || v == nil || v["hash"] == nil { return "", false }
+		return fmt.Sprintf("%v", v["hash"]), true
+	}
+	return "", false
+}
+
+// checkEvidence returns why a piece of evidence does not (or no longer) hold,
+// or "" if it does. live holds the hashes fetched by liveEvidenceHashes; a
+// source missing from it could not be reached. fresh evidence must still be in
+// the WAL, while evidence admitted earlier may have rotated out of it. A WAL
+// entry only witnesses the objects in its id or scope. Caller holds walMu.
+func checkEvidence(subject string, ev FactEvidence, live map[string]string, fresh bool) string {
+	if ev.Hash == "" { return fmt.Sprintf("%s evidence has no hash", ev.Source) }
+	if ev.Source == "wal" {
+		p, ok := walIdx.entries[ev.Hash]
+		if !ok && fresh { return "WAL entry " + ev.Hash + " not found" }
+		if ok && !p.covers(subject) { return "WAL entry " + ev.Hash + " does not cover " + subject }
+		if walIdx.mutatedAfter(subject, ev.Hash) { return subject + " was mutated after WAL entry " + ev.Hash }
+		return ""
+	}
+	cur, ok := live[ev.Source]
+	if !ok { return "" }
+	if cur != ev.Hash { return fmt.Sprintf("%s hash changed (%s -> %s)", ev.Source, ev.Hash, cur) }
+	return ""
+}
+
+func witnessStatus(evidence []FactEvidence) string {
+	sources := map[string]bool{}
+	for _, ev := range evidence { sources[ev.Source] = true }
+	if len(sources) >= 2 { return FactConfirmed }
+	return FactUnconfirmed
+}
+
+// factConfidence applies the half-life to a fact's base confidence. Caller
+// holds walMu.
+func factConfidence(f *Fact) float64 {
+	if f.Status == FactInvalidated { return 0 }
+	base := 0.5
+	if f.Status == FactConfirmed { base = 1 }
+	return base * math.Pow(0.5, float64(walIdx.opsSince(f.ObservedHead))/factHalfLifeOps)
+}
+
+func invalidateFactLocked(f *Fact, reason string) {
+	f.Status, f.Reason, f.UpdatedAt = FactInvalidated, reason, time.Now()
+	log.Printf("📒 Fact Ledger: %s invalidated (%s)", f.ID, reason)
+	journalOperation(map[string]interface{}{"type": "fact_invalidated", "fact_id": f.ID, "subject": f.Subject, "reason": reason})
+}
+
+// revalidateFacts re-checks the evidence of every live fact and refreshes
+// confidence. Engine hashes are fetched once per source, before factMu is
+// taken, so a slow engine does not stall the ledger.
+func revalidateFacts(ctx context.Context) {
+	var cited []FactEvidence
+	factMu.Lock()
+	for _, f := range facts {
+		if f.Status != FactInvalidated { cited = append(cited, f.Evidence...) }
+	}
+	factMu.Unlock()
+	live := liveEvidenceHashes(ctx, cited)
+
+	factMu.Lock()
+	defer factMu.Unlock()
+	var invalid map[*Fact]string
+	walMu.Lock()
+	for _, f := range facts {
+		if f.Status == FactInvalidated { continue }
+		for _, ev := range f.Evidence {
+			if reason := checkEvidence(f.Subject, ev, live, false); reason != "" {
+				if invalid == nil { invalid = map[*Fact]string{} }
+				invalid[f] = reason
+				break
+			}
+		}
+		f.Confidence = factConfidence(f)
+	}
+	walMu.Unlock()
+	for f, reason := range invalid { invalidateFactLocked(f, reason); f.Confidence = 0 }
+	saveFactsLocked()
+}
+
+func startFactJanitor() {
+	ticker := time.NewTicker(factSweepInterval)
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), factSweepInterval)
+		revalidateFacts(ctx)
+		cancel()
+	}
+}
+
+func saveFactsLocked() {
+	data, err := json.MarshalIndent(facts, "", "  ")
+	if err != nil { return }
+	os.WriteFile(FactFile, data, 0644)
+}
+
+func loadFacts() {
+	data, err := os.ReadFile(FactFile)
+	if err != nil { return }
+	loaded := make(map[string]*Fact)
+	if err := json.Unmarshal(data, &loaded); err != nil { return }
+	factMu.Lock()
+	defer factMu.Unlock()
+	for id, f := range loaded { facts[id] = f }
+}
+
+// record_fact admits a claim only if every piece of its evidence holds right
+// now. The claim is stored verbatim; only the evidence is authoritative. The
+// recording agent must sign the request with its key.
+func record_fact(ctx context.Context, req *mcp.CallToolRequest, args RecordFactArgs) (*mcp.CallToolResult, any, error) {
+	if args.Subject == "" || args.Claim == "" { return nil, nil, fmt.Errorf("FACT_INVALID: subject and claim are required") }
+	if len(args.Evidence) == 0 { return nil, nil, fmt.Errorf("PROVENANCE_REQUIRED: A fact must cite WAL entries or engine hashes") }
+	if err := verifyAgentSignature(args.AgentID, args.Signature, "record_fact", args); err != nil { return nil, nil, err }
+
+	live := liveEvidenceHashes(ctx, args.Evidence)
+	for _, ev := range args.Evidence {
+		if _, ok := live[ev.Source]; !ok && (ev.Source == "unity" || ev.Source == "blender") {
+			return nil, nil, fmt.Errorf("EVIDENCE_UNVERIFIABLE: %s did not report its state hash", ev.Source)
+		}
+	}
+	walMu.Lock()
+	for _, ev := range args.Evidence {
+		if reason := checkEvidence(args.Subject, ev, live, true); reason != "" { walMu.Unlock(); return nil, nil, fmt.Errorf("EVIDENCE_REJECTED: %s", reason) }
+	}
+	head := lastWalHash
+	walMu.Unlock()
+	now := time.Now()
+	f := &Fact{
+		ID:           uuid.New().String(),
+		Subject:      args.Subject,
+		Claim:        args.Claim,
+		AgentID:      args.AgentID,
+		Evidence:     args.Evidence,
+		Status:       witnessStatus(args.Evidence),
+		ObservedHead: head,
+		RecordedAt:   now,
+		UpdatedAt:    now,
+	}
+	walMu.Lock(); f.Confidence = factConfidence(f); walMu.Unlock()
+
+	factMu.Lock(); facts[f.ID] = f; saveFactsLocked(); out := *f; factMu.Unlock()
+	journalOperation(map[string]interface{}{"type": "fact_recorded", "fact_id": f.ID, "subject": f.Subject, "agent": f.AgentID, "status": f.Status, "evidence": f.Evidence})
+	return wrapForensicResult(out), nil, nil
+}
+
+// observe_fact adds a fresh observation to a fact. Only evidence that is new
+// (a source or hash not already cited) resets the decay clock; repeating the
+// same observation proves nothing new.
+func observe_fact(ctx context.Context, req *mcp.CallToolRequest, args ObserveFactArgs) (*mcp.CallToolResult, any, error) {
+	if len(args.Evidence) == 0 { return nil, nil, fmt.Errorf("PROVENANCE_REQUIRED: An observation must cite evidence") }
+	live := liveEvidenceHashes(ctx, args.Evidence)
+
+	factMu.Lock()
+	defer factMu.Unlock()
+	f, ok := facts[args.FactID]
+	if !ok { return nil, nil, fmt.Errorf("FACT_NOT_FOUND: %s", args.FactID) }
+	if f.Status == FactInvalidated { return nil, nil, fmt.Errorf("FACT_INVALIDATED: %s", f.Reason) }
+
+	walMu.Lock()
+	for _, ev := range args.Evidence {
+		if reason := checkEvidence(f.Subject, ev, live, true); reason != "" { walMu.Unlock(); return nil, nil, fmt.Errorf("EVIDENCE_REJECTED: %s", reason) }
+	}
+	fresh := false
+	for _, ev := range args.Evidence {
+		seen := false
+		for _, old := range f.Evidence { if old == ev { seen = true; break } }
+		if !seen { f.Evidence, fresh = append(f.Evidence, ev), true }
+	}
+	if fresh {
+		f.ObservedHead = lastWalHash
+		f.Status, f.UpdatedAt = witnessStatus(f.Evidence), time.Now()
+	}
+	f.Confidence = factConfidence(f)
+	walMu.Unlock()
+	saveFactsLocked()
+	return wrapForensicResult(map[string]interface{}{"fact": *f, "new_evidence": fresh}), nil, nil
+}
+
+func get_facts(ctx context.Context, req *mcp.CallToolRequest, args FactQueryArgs) (*mcp.CallToolResult, any, error) {
+	revalidateFacts(ctx)
+	factMu.Lock()
+	out := []Fact{}
+	for _, f := range facts {
+		if args.Subject != "" && f.Subject != args.Subject { continue }
+		if f.Status == FactInvalidated && !args.IncludeInvalid { continue }
+		out = append(out, *f)
+	}
+	factMu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].RecordedAt.Before(out[j].RecordedAt) })
+	return wrapForensicResult(out), nil, nil
+}
@@ -0,0 +1,235 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Capability Grants
+//
+// Scope and Capabilities on an envelope are the agent's own claims. Grants are
+// the orchestrator's answer: a signed, expiring token naming exactly which
+// UUIDs, opcodes and engines the holder may touch. Every mutating tool checks
+// the grant it is given. The signing key lives only in memory, so grants do not
+// survive an orchestrator restart. undo/redo are not gated: they only reverse
+// or replay mutations that were already authorized.
+const (
+	defaultGrantTTL = 60 * time.Second
+	maxGrantTTL     = intentTTL
+)
+
+var grantKey = newGrantKey()
+
+func newGrantKey() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func signGrant(g CapabilityGrant) string {
+	g.Signature = ""
+	data, _ := json.Marshal(g)
+	return hmacHex(grantKey, data)
+}
+
+func encodeGrant(g CapabilityGrant) string {
+	g.Signature = signGrant(g)
+	data, _ := json.Marshal(g)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeGrant parses a token and verifies its signature and expiry. It does
+// not check what the grant allows; see checkGrant.
+func decodeGrant(token string) (CapabilityGrant, error) {
+	var g CapabilityGrant
+	if token == "" { return g, fmt.Errorf("CAPABILITY_REQUIRED: Mutating tools require a capability grant") }
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil { return g, fmt.Errorf("CAPABILITY_INVALID: Malformed grant") }
+	if err := json.Unmarshal(data, &g); err != nil { return g, fmt.Errorf("CAPABILITY_INVALID: Malformed grant") }
+	if !hmac.Equal([]byte(g.Signature), []byte(signGrant(g))) { return g, fmt.Errorf("CAPABILITY_INVALID: Grant %s was not issued by this orchestrator", g.ID) }
+	if time.Now().After(g.ExpiresAt) { return g, fmt.Errorf("CAPABILITY_EXPIRED: Grant %s expired at %s", g.ID, g.ExpiresAt.Format(time.RFC3339)) }
+	if _, err := activeAgentKey(g.Holder); err != nil { return g, fmt.Errorf("CAPABILITY_REVOKED: %v", err) }
+	if err := checkDriftLock(g.Holder); err != nil { return g, err }
+	return g, nil
+}
+
+// checkGrant verifies that token authorizes op on every engine and UUID
+// listed. Every caller must name the opcode it is about to use; 0 is refused.
+func checkGrant(token string, engines []string, op VibeOpcode, uuids []string) (CapabilityGrant, error) {
+	g, err := decodeGrant(token)
+	if err != nil { return g, err }
+	if op == 0 { return g, fmt.Errorf("CAPABILITY_DENIED: Grant %s checked without an opcode", g.ID) }
+	for _, e := range engines {
+		if !containsString(g.Engines, e) { return g, fmt.Errorf("CAPABILITY_DENIED: Grant %s does not cover engine %s", g.ID, e) }
+	}
+	if !containsOpcode(g.Opcodes, op) { return g, fmt.Errorf("CAPABILITY_DENIED: Grant %s does not cover opcode %X", g.ID, op) }
+	for _, id := range uuids {
+		if !matchesAnyScope(g.UUIDs, id) { return g, fmt.Errorf("CAPABILITY_DENIED: Grant %s does not cover UUID %s", g.ID, id) }
+	}
+	return g, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list { if v == s { return true } }
+	return false
+}
+
+func grantExpiry(ttlSeconds int, ceiling time.Time) time.Time {
+	ttl := defaultGrantTTL
+	if ttlSeconds > 0 { ttl = time.Duration(ttlSeconds) * time.Second }
+	if ttl > maxGrantTTL { ttl = maxGrantTTL }
+	exp := time.Now().Add(ttl)
+	if !ceiling.IsZero() && exp.After(ceiling) { exp = ceiling }
+	return exp
+}
+
+func journalGrant(g CapabilityGrant) {
+	journalOperation(map[string]interface{}{
+		"type":          "grant_issued",
+		"grant_id":      g.ID,
+		"holder":        g.Holder,
+		"issued_by":     g.IssuedBy,
+		"parent_id":     g.ParentID,
+		"intent":        g.IntentID,
+		"work_order_id": g.WorkOrderID,
+		"uuids":         g.UUIDs,
+		"opcodes":       g.Opcodes,
+		"engines":       g.Engines,
+		"expires_at":    g.ExpiresAt,
+	})
+}
+
+// mint_capability_grant issues a grant to the verified signer of an admitted
+// intent, covering the intent's scope and opcode and nothing more. The request
+// itself must be signed by that agent, or by a Human approving on its behalf.
+func mint_capability_grant(ctx context.Context, req *mcp.CallToolRequest, args MintGrantArgs) (*mcp.CallToolResult, any, error) {
+	rec, err := lookupIntent(args.IntentID)
+	if err != nil { return nil, nil, err }
+	txMu.Lock(); env, agent, status, expires := rec.Envelope, rec.Agent, rec.Status, rec.ExpiresAt; txMu.Unlock()
+	if status != IntentValidated && status != IntentApproved && status != IntentExecuting {
+		return nil, nil, fmt.Errorf("INTENT_%s: Grants are only minted for admitted intents", status)
+	}
+	if agent.AgentID == "" { return nil, nil, fmt.Errorf("SIGNATURE_REQUIRED: Intent %s has no verified agent", args.IntentID) }
+	if err := verifyMintRequest(agent.AgentID, args); err != nil { return nil, nil, err }
+	if err := checkDriftLock(agent.AgentID); err != nil { return nil, nil, err }
+
+	opcodes := []VibeOpcode{env.Opcode}
+	if env.Opcode == 0 {
+		policyMu.RLock(); opcodes = policyRuleFor(activePolicy, env.Intent).AllowedOpcodes; policyMu.RUnlock()
+	}
+	engines := args.Engines
+	if len(engines) == 0 { engines = []string{"unity", "blender"} }
+	ceiling := time.Time{}
+	if status != IntentExecuting { ceiling = expires }
+
+	g := CapabilityGrant{
+		ID:        uuid.New().String(),
+		Holder:    agent.AgentID,
+		IssuedBy:  "orchestrator",
+		IntentID:  args.IntentID,
+		UUIDs:     env.Scope,
+		Opcodes:   opcodes,
+		Engines:   engines,
+		ExpiresAt: grantExpiry(args.TTLSeconds, ceiling),
+	}
+	journalGrant(g)
+	log.Printf("🎫 Capability Grant: %s issued to %s (%d UUIDs, until %s)", g.ID, g.Holder, len(g.UUIDs), g.ExpiresAt.Format(time.Kitchen))
+	return wrapForensicResult(map[string]interface{}{"grant": encodeGrant(g), "expires_at": g.ExpiresAt}), nil, nil
+}
+
+// verifyMintRequest authenticates who asked for a grant: the intent's own
+// agent, or a named Human approver.
+func verifyMintRequest(agentID string, args MintGrantArgs) error {
+	if args.Approver != "" { return verifyHuman(args.Approver, args.Signature, "mint_capability_grant", args) }
+	key, err := activeAgentKey(agentID)
+	if err != nil { return err }
+	if args.Signature == "" || !hmac.Equal([]byte(args.Signature), []byte(hmacHex(key.Secret, canonicalDecision("mint_capability_grant", args)))) {
+		return fmt.Errorf("SIGNATURE_INVALID: Grant request for intent %s was not signed by %s", args.IntentID, agentID)
+	}
+	return nil
+}
+
+// delegate_capability_grant lets a Foreman hand an Operator a strictly
+// narrower grant for a single work order. The request must be signed with the
+// Foreman's agent key.
+func delegate_capability_grant(ctx context.Context, req *mcp.CallToolRequest, args DelegateGrantArgs) (*mcp.CallToolResult, any, error) {
+	parent, err := decodeGrant(args.Grant)
+	if err != nil { return nil, nil, err }
+	foreman, err := activeAgentKey(parent.Holder)
+	if err != nil { return nil, nil, err }
+	if foreman.Role != RoleForeman { return nil, nil, fmt.Errorf("DELEGATION_DENIED: Only a Foreman may delegate, %s is %s", foreman.AgentID, foreman.Role) }
+
+	signed := args; signed.Signature = ""
+	data, _ := json.Marshal(signed)
+	if !hmac.Equal([]byte(args.Signature), []byte(hmacHex(foreman.Secret, data))) {
+		return nil, nil, fmt.Errorf("SIGNATURE_INVALID: Delegation was not signed by %s", foreman.AgentID)
+	}
+	if args.WorkOrderID == "" { return nil, nil, fmt.Errorf("DELEGATION_DENIED: Delegated grants are bound to a single work order") }
+
+	operator, err := activeAgentKey(args.To)
+	if err != nil { return nil, nil, err }
+	if operator.Role != RoleOperator { return nil, nil, fmt.Errorf("DELEGATION_DENIED: %s is not an Operator", args.To) }
+	if foreman.Engine != "" && operator.Engine != "" && foreman.Engine != operator.Engine {
+		return nil, nil, fmt.Errorf("DELEGATION_DENIED: %s works on %s, not %s", args.To, operator.Engine, foreman.Engine)
+	}
+
+	uuids, opcodes, engines := parent.UUIDs, parent.Opcodes, parent.Engines
+	if len(args.UUIDs) > 0 {
+		for _, id := range args.UUIDs {
+			if !matchesAnyScope(parent.UUIDs, id) { return nil, nil, fmt.Errorf("DELEGATION_DENIED: UUID %s exceeds parent grant", id) }
+		}
+		uuids = args.UUIDs
+	}
+	if len(args.Opcodes) > 0 {
+		for _, op := range args.Opcodes {
+			if !containsOpcode(parent.Opcodes, op) { return nil, nil, fmt.Errorf("DELEGATION_DENIED: Opcode %X exceeds parent grant", op) }
+		}
+		opcodes = args.Opcodes
+	}
+	if len(args.Engines) > 0 {
+		for _, e := range args.Engines {
+			if !containsString(parent.Engines, e) { return nil, nil, fmt.Errorf("DELEGATION_DENIED: Engine %s exceeds parent grant", e) }
+		}
+		engines = args.Engines
+	}
+
+	g := CapabilityGrant{
+		ID:          uuid.New().String(),
+		Holder:      operator.AgentID,
+		IssuedBy:    foreman.AgentID,
+		ParentID:    parent.ID,
+		IntentID:    parent.IntentID,
+		WorkOrderID: args.WorkOrderID,
+		UUIDs:       uuids,
+		Opcodes:     opcodes,
+		Engines:     engines,
+		ExpiresAt:   grantExpiry(args.TTLSeconds, parent.ExpiresAt),
+	}
+	journalGrant(g)
+	log.Printf("🎫 Capability Grant: %s delegated %s -> %s for work order %s", g.ID, foreman.AgentID, operator.AgentID, args.WorkOrderID)
+	return wrapForensicResult(map[string]interface{}{"grant": encodeGrant(g), "expires_at": g.ExpiresAt}), nil, nil
+}
@@ -0,0 +1,244 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Central DAG Validation (PATHOLOGICAL_EDGE_CASES.md §2)
+//
+// The orchestrator mirrors each engine's scene hierarchy as a child -> parent
+// map, loaded from the adapter's hierarchy/get and kept current by the
+// mutations it sends itself. Reparenting child under parent is only admissible
+// if child is neither parent nor one of parent's ancestors; anything else
+// would close a cycle and is refused before it reaches an engine. Mutations
+// whose effect on the tree cannot be predicted (create, delete, undo) mark the
+// mirror stale so the next check reloads it.
+const hierarchyMaxAge = 30 * time.Second
+
+type hierarchyGraph struct {
+	Parent     map[string]string   // child -> parent; roots map to ""
+	Dependents map[string][]string // node -> constraints/modifiers that target it
+	Identity   map[string]ObjectIdentity
+	Refreshed  time.Time
+}
+
+var (
+	hierarchies = make(map[string]*hierarchyGraph) // engine -> mirrored hierarchy
+	hierarchyMu sync.Mutex
+)
+
+// buildHierarchy turns an adapter's node list into a graph, refusing one that
+// already contains a cycle.
+func buildHierarchy(nodes []HierarchyNode) (*hierarchyGraph, error) {
+	g := &hierarchyGraph{Parent: make(map[string]string, len(nodes)), Dependents: map[string][]string{}, Identity: make(map[string]ObjectIdentity, len(nodes)), Refreshed: time.Now()}
+	for _, n := range nodes {
+		if n.UUID == "" { continue }
+		id := ObjectIdentity{UUID: n.UUID, Kind: n.Kind, PrefabDepth: n.PrefabDepth}
+		if id.Kind == "" { id.Kind = KindObject }
+		if err := validateIdentity(id); err != nil { return nil, err }
+		g.Parent[n.UUID] = n.Parent
+		g.Identity[n.UUID] = id
+		if len(n.Dependents) > 0 { g.Dependents[n.UUID] = n.Dependents }
+	}
+	for id := range g.Parent {
+		if _, err := g.ancestors(id); err != nil { return nil, err }
+	}
+	return g, nil
+}
+
+// ancestors walks from id to its root, nearest first.
+func (g *hierarchyGraph) ancestors(id string) ([]string, error) {
+	var out []string
+	seen := map[string]bool{id: true}
+	for p := g.Parent[id]; p != ""; p = g.Parent[p] {
+		if seen[p] { return nil, fmt.Errorf("GRAPH_INVALIDITY: hierarchy cycle through %s", p) }
+		seen[p] = true
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// descendants returns every node below id, in no particular order.
+func (g *hierarchyGraph) descendants(id string) []string {
+	children := map[string][]string{}
+	for c, p := range g.Parent { children[p] = append(children[p], c) }
+	var out []string
+	queue := []string{id}
+	seen := map[string]bool{id: true}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, c := range children[n] {
+			if seen[c] { continue }
+			seen[c] = true
+			out = append(out, c)
+			queue = append(queue, c)
+		}
+	}
+	return out
+}
+
+func refreshHierarchy(ctx context.Context, engine string) (*hierarchyGraph, error) {
+	res, err := sendToEngine(ctx, engine, "hierarchy/get", "GET", nil)
+	if err != nil { return nil, err }
+	raw, _ := res["nodes"].([]interface{})
+	nodes := make([]HierarchyNode, 0, len(raw))
+	for _, r := range raw {
+		m, _ := r.(map[string]interface{})
+		id, err := parseIdentity(m)
+		if err != nil { return nil, err }
+		parent, _ := m["parent"].(string)
+		var deps []string
+		if ds, ok := m["dependents"].([]interface{}); ok {
+			for _, d := range ds { if s, ok := d.(string); ok { deps = append(deps, s) } }
+		}
+		nodes = append(nodes, HierarchyNode{UUID: id.UUID, Parent: parent, Kind: id.Kind, PrefabDepth: id.PrefabDepth, Dependents: deps})
+	}
+	g, err := buildHierarchy(nodes)
+	if err != nil {
+		dispatchVibeEvent(LevelError, "hierarchy_invalid", "", "HUMAN_INTERVENTION_REQUIRED", map[string]interface{}{"engine": engine, "error": err.Error()})
+		return nil, err
+	}
+	hierarchyMu.Lock(); hierarchies[engine] = g; hierarchyMu.Unlock()
+	return g, nil
+}
+
+// engineHierarchy returns the mirrored graph for engine, reloading it when it
+// is stale. If the engine cannot be reached the last known graph is used.
+func engineHierarchy(ctx context.Context, engine string) (*hierarchyGraph, error) {
+	hierarchyMu.Lock(); g := hierarchies[engine]; hierarchyMu.Unlock()
+	if g != nil && time.Since(g.Refreshed) < hierarchyMaxAge { return g, nil }
+	fresh, err := refreshHierarchy(ctx, engine)
+	if err == nil { return fresh, nil }
+	if g != nil && !strings.HasPrefix(err.Error(), "GRAPH_INVALIDITY") { return g, nil }
+	return nil, fmt.Errorf("GRAPH_UNVERIFIABLE: no hierarchy for %s: %v", engine, err)
+}
+
+// hierarchyDescendants returns every node below uuid on either engine, which a
+// lock on uuid covers. At least one engine's hierarchy must be known.
+func hierarchyDescendants(ctx context.Context, uuid string) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	known := false
+	var lastErr error
+	for _, engine := range []string{"unity", "blender"} {
+		g, err := engineHierarchy(ctx, engine)
+		if err != nil { lastErr = err; continue }
+		known = true
+		for _, d := range g.descendants(uuid) {
+			if !seen[d] { seen[d] = true; out = append(out, d) }
+		}
+	}
+	if !known { return nil, lastErr }
+	return out, nil
+}
+
+// reparentOf extracts (child, parent) from a payload that sets a parent.
+// An empty parent means "move to the scene root".
+func reparentOf(payload map[string]interface{}) (string, string, bool) {
+	child, _ := payload["id"].(string)
+	if child == "" { child, _ = payload["uuid"].(string) }
+	for _, k := range []string{"parent", "parent_id", "new_parent"} {
+		if v, ok := payload[k]; ok {
+			p, _ := v.(string)
+			return child, p, child != ""
+		}
+	}
+	return "", "", false
+}
+
+// checkReparent refuses a reparent that would make child its own ancestor on
+// any of the target engines.
+func checkReparent(ctx context.Context, targets []string, child, parent string) error {
+	if parent == "" { return nil }
+	if parent == child { return fmt.Errorf("GRAPH_INVALIDITY: %s cannot be its own parent", child) }
+	for _, t := range targets {
+		g, err := engineHierarchy(ctx, t)
+		if err != nil { return err }
+		hierarchyMu.Lock(); up, err := g.ancestors(parent); hierarchyMu.Unlock()
+		if err != nil { return err }
+		if containsString(up, child) {
+			return fmt.Errorf("GRAPH_INVALIDITY: parenting %s under %s creates a cycle on %s (%s is an ancestor of %s)", child, parent, t, child, parent)
+		}
+	}
+	return nil
+}
+
+// checkOpSpecHierarchy validates a governed op spec against the mirror. Specs
+// that do not set a parent pass.
+func checkOpSpecHierarchy(ctx context.Context, opSpec map[string]interface{}) error {
+	payload, targets := splitOpSpec(opSpec)
+	child, parent, ok := reparentOf(payload)
+	if !ok { return nil }
+	return checkReparent(ctx, targets, child, parent)
+}
+
+// observeHierarchyMutation keeps the mirror in step with a mutation the
+// engine accepted.
+func observeHierarchyMutation(engine, endpoint string, data interface{}) {
+	payload, _ := data.(map[string]interface{})
+	hierarchyMu.Lock()
+	defer hierarchyMu.Unlock()
+	g := hierarchies[engine]
+	if g == nil { return }
+	class := classifyIntent(0, endpoint, payload)
+	if child, parent, ok := reparentOf(payload); ok && class != ClassDestructive {
+		if _, known := g.Parent[child]; known {
+			g.Parent[child] = parent
+			return
+		}
+	}
+	if class != ClassCosmetic {
+		g.Refreshed = time.Time{} // Effect on the tree unknown: reload before the next check
+	}
+}
+
+func markHierarchyStale(engine string) {
+	hierarchyMu.Lock()
+	if g := hierarchies[engine]; g != nil { g.Refreshed = time.Time{} }
+	hierarchyMu.Unlock()
+}
+
+// get_hierarchy returns an engine's mirrored hierarchy, or one node's
+// ancestors and descendants.
+func get_hierarchy(ctx context.Context, req *mcp.CallToolRequest, args HierarchyQueryArgs) (*mcp.CallToolResult, any, error) {
+	if args.Refresh { markHierarchyStale(args.Engine) }
+	g, err := engineHierarchy(ctx, args.Engine)
+	if err != nil { return nil, nil, err }
+	hierarchyMu.Lock()
+	defer hierarchyMu.Unlock()
+	if args.UUID != "" {
+		up, err := g.ancestors(args.UUID)
+		if err != nil { return nil, nil, err }
+		down := g.descendants(args.UUID)
+		sort.Strings(down)
+		return wrapForensicResult(map[string]interface{}{"uuid": args.UUID, "parent": g.Parent[args.UUID], "ancestors": up, "descendants": down}), nil, nil
+	}
+	nodes := make([]HierarchyNode, 0, len(g.Parent))
+	for c, p := range g.Parent { nodes = append(nodes, HierarchyNode{UUID: c, Parent: p}) }
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].UUID < nodes[j].UUID })
+	log.Printf("🌳 Hierarchy: %s has %d nodes (refreshed %s)", args.Engine, len(nodes), g.Refreshed.Format(time.RFC3339))
+	return wrapForensicResult(map[string]interface{}{"engine": args.Engine, "refreshed_at": g.Refreshed, "nodes": nodes}), nil, nil
+}
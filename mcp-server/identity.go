@@ -0,0 +1,133 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// Identity-Depth Metadata (PATHOLOGICAL_EDGE_CASES.md §1)
+//
+// Engines report every node with its kind (prefab definition, prefab instance
+// or plain object) and its prefab nesting depth. Each mutation records the
+// identities it targets as the mirrored hierarchy last reported them; a payload
+// may only name a depth for an object the mirror has not seen, and one that
+// contradicts the mirror is refused before it reaches the engine. Verification
+// compares the engine's report for those UUIDs against the recorded depth and
+// folds the depths into the verified hash, so an instance override can never be
+// verified against its definition. A mismatch quarantines the transaction.
+
+var objectKinds = map[ObjectKind]bool{KindPrefabDef: true, KindPrefabInstance: true, KindObject: true}
+
+// parseIdentity reads one identity report. A report without a kind is a plain
+// object at depth 0.
+func parseIdentity(m map[string]interface{}) (ObjectIdentity, error) {
+	id := ObjectIdentity{Kind: KindObject}
+	id.UUID, _ = m["uuid"].(string)
+	if k, ok := m["kind"].(string); ok && k != "" { id.Kind = ObjectKind(k) }
+	if d, ok := m["prefab_depth"].(float64); ok { id.PrefabDepth = int(d) }
+	return id, validateIdentity(id)
+}
+
+func validateIdentity(id ObjectIdentity) error {
+	if !objectKinds[id.Kind] { return fmt.Errorf("IDENTITY_INVALID: %s reports unknown kind %q", id.UUID, id.Kind) }
+	if id.PrefabDepth < 0 { return fmt.Errorf("IDENTITY_INVALID: %s reports negative prefab depth %d", id.UUID, id.PrefabDepth) }
+	return nil
+}
+
+// identityOf returns the identity engine last reported for uuid.
+func identityOf(engine, uuid string) (ObjectIdentity, bool) {
+	hierarchyMu.Lock()
+	defer hierarchyMu.Unlock()
+	g := hierarchies[engine]
+	if g == nil { return ObjectIdentity{}, false }
+	id, ok := g.Identity[uuid]
+	return id, ok
+}
+
+// mutationIdentities names the identities a mutation targets. The mirrored
+// report is authoritative; a prefab_depth (and kind) in the payload is only
+// used for an object the mirror does not know, and must agree with it otherwise.
+func mutationIdentities(engine string, payload map[string]interface{}) ([]ObjectIdentity, error) {
+	uuid, _ := payload["id"].(string)
+	if uuid == "" { uuid, _ = payload["uuid"].(string) }
+	if uuid == "" { return nil, nil }
+	id, known := identityOf(engine, uuid)
+	d, hasDepth := payload["prefab_depth"].(float64)
+	k, _ := payload["kind"].(string)
+	if known {
+		if (hasDepth && int(d) != id.PrefabDepth) || (k != "" && ObjectKind(k) != id.Kind) {
+			return nil, fmt.Errorf("IDENTITY_DEPTH_MISMATCH: payload names %s as kind %q depth %v, %s reports %s depth %d", uuid, k, payload["prefab_depth"], engine, id.Kind, id.PrefabDepth)
+		}
+		return []ObjectIdentity{id}, nil
+	}
+	if !hasDepth { return nil, nil }
+	id = ObjectIdentity{UUID: uuid, Kind: KindPrefabInstance, PrefabDepth: int(d)}
+	if k != "" { id.Kind = ObjectKind(k) }
+	if err := validateIdentity(id); err != nil { return nil, err }
+	return []ObjectIdentity{id}, nil
+}
+
+// verifyAtDepth checks an engine's state report against the identities a
+// mutation targeted and returns the hash to record: the engine hash with the
+// targeted depths folded in. A prefab target the engine does not report on
+// cannot be verified at all.
+func verifyAtDepth(v map[string]interface{}, expected []ObjectIdentity) (string, error) {
+	hash := fmt.Sprintf("%v", v["hash"])
+	if len(expected) == 0 { return hash, nil }
+
+	observed := map[string]ObjectIdentity{}
+	if raw, ok := v["identities"].([]interface{}); ok {
+		for _, r := range raw {
+			m, _ := r.(map[string]interface{})
+			id, err := parseIdentity(m)
+			if err != nil { return "", err }
+			observed[id.UUID] = id
+		}
+	}
+	keys := make([]string, 0, len(expected))
+	for _, e := range expected {
+		o, ok := observed[e.UUID]
+		if !ok && (e.Kind != KindObject || e.PrefabDepth != 0) {
+			return "", fmt.Errorf("IDENTITY_DEPTH_MISMATCH: %s targeted at %s depth %d, engine reported no identity", e.UUID, e.Kind, e.PrefabDepth)
+		}
+		if ok && (o.Kind != e.Kind || o.PrefabDepth != e.PrefabDepth) {
+			return "", fmt.Errorf("IDENTITY_DEPTH_MISMATCH: %s targeted at %s depth %d, engine reports %s depth %d", e.UUID, e.Kind, e.PrefabDepth, o.Kind, o.PrefabDepth)
+		}
+		keys = append(keys, fmt.Sprintf("%s:%s:%d", e.UUID, e.Kind, e.PrefabDepth))
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	fmt.Fprint(h, hash)
+	for _, k := range keys { fmt.Fprintf(h, "|%s", k) }
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// quarantineTransaction takes an intent and its open transaction out of the
+// pipeline after a depth mismatch; the transaction can no longer commit.
+func quarantineTransaction(intentID, engine string, cause error) {
+	if intentID != "" {
+		transitionIntent(intentID, IntentQuarantined, cause.Error())
+		txMu.Lock()
+		if tx, ok := transactions[intentID]; ok { tx.Status = TxQuarantined }
+		txMu.Unlock()
+	}
+	log.Printf("🏗️ Identity Depth: %s on %s quarantined: %v", intentID, engine, cause)
+	dispatchVibeEvent(LevelError, "identity_depth_mismatch", intentID, "HUMAN_INTERVENTION_REQUIRED", map[string]interface{}{"engine": engine, "error": cause.Error()})
+}
@@ -0,0 +1,194 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Intent Lifecycle
+//
+// Intents move SUBMITTED -> VALIDATED -> APPROVED -> EXECUTING -> DONE, and can
+// leave the pipeline early as EXPIRED (TTL elapsed or the hashes they were based
+// on moved: Intent Decay Invariance), REJECTED, or QUARANTINED by the conflict
+// resolver. An intent whose mutation was rolled back for a human waits in
+// WAIT_HUMAN_LOCK and returns to APPROVED once its objects are free again.
+// Terminal records are kept for intentRetention for audit, then pruned. All
+// access goes through txMu.
+const (
+	IntentFile      = PersistenceDir + "/intents.json"
+	intentTTL       = 5 * time.Minute
+	intentRetention = 24 * time.Hour
+)
+
+var intentTransitions = map[IntentStatus][]IntentStatus{
+	IntentSubmitted: {IntentValidated, IntentApproved, IntentExpired, IntentRejected},
+	IntentValidated: {IntentApproved, IntentExecuting, IntentExpired, IntentRejected, IntentQuarantined, IntentWaitHuman},
+	IntentApproved:  {IntentExecuting, IntentExpired, IntentRejected, IntentQuarantined, IntentWaitHuman},
+	IntentExecuting: {IntentDone, IntentRejected, IntentQuarantined, IntentWaitHuman},
+	IntentWaitHuman: {IntentApproved, IntentExpired, IntentRejected},
+}
+
+func isIntentTerminal(s IntentStatus) bool {
+	return s == IntentDone || s == IntentExpired || s == IntentRejected || s == IntentQuarantined
+}
+
+// isIntentDecayable reports whether the intent has not yet touched an engine;
+// once executing, its own mutations are expected to move the hashes.
+func isIntentDecayable(s IntentStatus) bool {
+	return s == IntentSubmitted || s == IntentValidated || s == IntentApproved || s == IntentWaitHuman
+}
+
+func newIntentRecord(id string, env IntentEnvelope) *IntentRecord {
+	now := time.Now()
+	return &IntentRecord{ID: id, Envelope: env, Status: IntentSubmitted, SubmittedAt: now, UpdatedAt: now, ExpiresAt: now.Add(intentTTL)}
+}
+
+// transitionIntentLocked moves an intent to a new status. Caller holds txMu.
+func transitionIntentLocked(rec *IntentRecord, to IntentStatus, reason string) error {
+	if rec.Status == to { return nil }
+	allowed := false
+	for _, s := range intentTransitions[rec.Status] {
+		if s == to { allowed = true; break }
+	}
+	if !allowed { return fmt.Errorf("INTENT_%s: Cannot move intent %s to %s", rec.Status, rec.ID, to) }
+
+	from := rec.Status
+	rec.Status, rec.Reason, rec.UpdatedAt = to, reason, time.Now()
+	dispatchVibeEvent(LevelInfo, "intent_transition", rec.ID, string(to), map[string]interface{}{"from": from, "to": to, "reason": reason})
+	if isIntentTerminal(to) { dropApproval(rec.ID) }
+	saveIntentsLocked()
+	return nil
+}
+
+func transitionIntent(id string, to IntentStatus, reason string) error {
+	txMu.Lock()
+	defer txMu.Unlock()
+	rec, ok := intents[id]
+	if !ok { return fmt.Errorf("UNKNOWN_INTENT") }
+	return transitionIntentLocked(rec, to, reason)
+}
+
+// observedHashes returns the current WAL state head and the last referee-verified
+// scene hash of each engine, keyed like IntentEnvelope.BasedOnHashes.
+func observedHashes() map[string]string {
+	walMu.Lock()
+	h := map[string]string{"wal": lastStateHash}
+	walMu.Unlock()
+	refereeMu.Lock()
+	for engine, hash := range refereeHashes { h[engine] = hash }
+	refereeMu.Unlock()
+	return h
+}
+
+// decayReason explains why a pre-execution intent is no longer valid, or
+// returns "" if it still is.
+func decayReason(rec *IntentRecord, now time.Time, current map[string]string) string {
+	if !isIntentDecayable(rec.Status) { return "" }
+	if now.After(rec.ExpiresAt) { return "TTL_ELAPSED" }
+	for key, based := range rec.Envelope.BasedOnHashes {
+		if key == "log" { continue } // Grounding in logs is enforced by checkLogIngestion
+		if cur, ok := current[key]; ok && cur != "" && cur != based {
+			return fmt.Sprintf("STALE_HASH: %s moved %s -> %s", key, based, cur)
+		}
+	}
+	return ""
+}
+
+// lookupIntent returns an intent after applying decay, so callers never act on
+// an intent whose basis has silently changed.
+func lookupIntent(id string) (*IntentRecord, error) {
+	current := observedHashes()
+	txMu.Lock()
+	defer txMu.Unlock()
+	rec, ok := intents[id]
+	if !ok { return nil, fmt.Errorf("UNKNOWN_INTENT") }
+	if reason := decayReason(rec, time.Now(), current); reason != "" { transitionIntentLocked(rec, IntentExpired, reason) }
+	return rec, nil
+}
+
+func sweepStaleIntents() {
+	current := observedHashes()
+	txMu.Lock()
+	defer txMu.Unlock()
+	now := time.Now()
+	pruned := 0
+	for id, rec := range intents {
+		if isIntentTerminal(rec.Status) && now.Sub(rec.UpdatedAt) > intentRetention {
+			if _, open := transactions[id]; !open { delete(intents, id); pruned++ }
+			continue
+		}
+		if reason := decayReason(rec, now, current); reason != "" {
+			log.Printf("⌛ Intent Decay: %s expired (%s)", rec.ID, reason)
+			transitionIntentLocked(rec, IntentExpired, reason)
+			continue
+		}
+		if rec.Status == IntentWaitHuman && humanLockFree(rec.Envelope.Scope) {
+			transitionIntentLocked(rec, IntentApproved, "HUMAN_LOCK_RELEASED")
+		}
+	}
+	if pruned > 0 {
+		log.Printf("⌛ Intent Decay: pruned %d terminal intents past retention", pruned)
+		saveIntentsLocked()
+	}
+}
+
+func startIntentJanitor() {
+	ticker := time.NewTicker(2 * time.Second)
+	for range ticker.C {
+		sweepStaleIntents()
+	}
+}
+
+func saveIntentsLocked() {
+	refreshBudgetViewLocked()
+	data, err := json.MarshalIndent(intents, "", "  ")
+	if err != nil { return }
+	os.WriteFile(IntentFile, data, 0644)
+}
+
+func loadIntents() {
+	data, err := os.ReadFile(IntentFile)
+	if err != nil { return }
+	loaded := make(map[string]*IntentRecord)
+	if err := json.Unmarshal(data, &loaded); err != nil { return }
+	txMu.Lock()
+	defer txMu.Unlock()
+	for id, rec := range loaded {
+		// Anything that was mid-flight when the orchestrator died cannot be
+		// trusted to resume; the engines may have moved on without us.
+		if rec.Status == IntentExecuting {
+			rec.Status, rec.Reason, rec.UpdatedAt = IntentRejected, "ORCHESTRATOR_RESTART", time.Now()
+		}
+		intents[id] = rec
+	}
+	refreshBudgetViewLocked()
+}
+
+func get_intent_status(ctx context.Context, req *mcp.CallToolRequest, args struct{ID string `json:"intent_id"`}) (*mcp.CallToolResult, any, error) {
+	rec, err := lookupIntent(args.ID)
+	if err != nil { return nil, nil, err }
+	txMu.Lock()
+	snapshot := *rec
+	txMu.Unlock()
+	return wrapForensicResult(snapshot), nil, nil
+}
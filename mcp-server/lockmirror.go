@@ -0,0 +1,157 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Lock Mirror
+//
+// The central lock table is pushed to both editors so artists can see what is
+// locked and by whom. Every push is the whole table with its digest, so an
+// adapter simply replaces its flags and echoes the digest on /health. A table
+// is only pushed to an engine whose last acknowledged digest differs: a new or
+// released lock pushes at once, and every lockMirrorInterval each adapter's
+// reported digest is read back, so an editor that restarted (or missed a push)
+// and leases that expired quietly are reconciled. Expiry is not part of the
+// digest; a renewed lease is the same flag, and adapters keep flags until the
+// next push replaces them.
+const (
+	lockMirrorEndpoint = "locks/sync"
+	lockMirrorInterval = 5 * time.Second
+)
+
+var lockMirrorLabels = map[LockType]string{
+	LockHumanActive:   "Human editing",
+	LockAISpeculative: "AI working on this",
+	LockPerimeter:     "Perimeter locked",
+}
+
+var (
+	lockMirrorKick  = make(chan struct{}, 1)
+	mirroredDigests = make(map[string]string) // engine -> digest of the last table it acknowledged
+	lockMirrorMu    sync.Mutex
+	lockMirrorRun   sync.Mutex // Serializes pushes and reconciliation; taken before lockMirrorMu
+)
+
+// kickLockMirror asks for a push as soon as possible. It never blocks, so it
+// is safe to call with lockMu held.
+func kickLockMirror() {
+	select {
+	case lockMirrorKick <- struct{}{}:
+	default:
+	}
+}
+
+// lockMirrorSnapshot flattens the live lock table into editor flags.
+func lockMirrorSnapshot() []MirroredLock {
+	now := time.Now()
+	lockMu.RLock()
+	var out []MirroredLock
+	for id, l := range lockTable {
+		if !lockLive(l, now) { continue }
+		out = append(out, MirroredLock{UUID: id, Type: l.Type, Owner: l.Owner, Label: lockMirrorLabels[l.Type], ExpiresAt: l.ExpiresAt})
+		for _, c := range l.Covers {
+			out = append(out, MirroredLock{UUID: c, Type: l.Type, Owner: l.Owner, Label: lockMirrorLabels[l.Type], Via: id, ExpiresAt: l.ExpiresAt})
+		}
+	}
+	lockMu.RUnlock()
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].UUID != out[j].UUID { return out[i].UUID < out[j].UUID }
+		return out[i].Via < out[j].Via
+	})
+	return out
+}
+
+func lockMirrorDigest(locks []MirroredLock) string {
+	flat := make([]MirroredLock, len(locks))
+	for i, l := range locks { l.ExpiresAt = time.Time{}; flat[i] = l }
+	data, _ := json.Marshal(flat)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func mirrorEngines() []string {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+	names := make([]string, 0, len(engines))
+	for n := range engines { names = append(names, n) }
+	sort.Strings(names)
+	return names
+}
+
+// pushLockMirror sends the table to every engine that has not acknowledged
+// its current digest. An engine that could not be reached is retried on the
+// next push.
+func pushLockMirror(ctx context.Context) map[string]error {
+	lockMirrorRun.Lock()
+	defer lockMirrorRun.Unlock()
+	locks := lockMirrorSnapshot()
+	digest := lockMirrorDigest(locks)
+
+	errs := map[string]error{}
+	for _, n := range mirrorEngines() {
+		lockMirrorMu.Lock(); done := mirroredDigests[n] == digest; lockMirrorMu.Unlock()
+		if done { continue }
+		res, err := sendToEngine(ctx, n, lockMirrorEndpoint, "POST", map[string]interface{}{"digest": digest, "locks": locks})
+		if err == nil { err = engineRefusal(res) }
+		lockMirrorMu.Lock()
+		if err != nil { delete(mirroredDigests, n); errs[n] = err } else { mirroredDigests[n] = digest }
+		lockMirrorMu.Unlock()
+	}
+	return errs
+}
+
+// reconcileLockMirror takes the digest each reachable adapter reports on
+// /health as what it holds, so the next push repairs any that differ.
+func reconcileLockMirror(ctx context.Context) {
+	lockMirrorRun.Lock()
+	defer lockMirrorRun.Unlock()
+	for _, n := range mirrorEngines() {
+		res, err := sendToEngine(ctx, n, "health", "GET", nil)
+		if err != nil || res == nil { continue }
+		reported, _ := res["lock_digest"].(string)
+		lockMirrorMu.Lock()
+		if mirroredDigests[n] != reported {
+			log.Printf("🔏 Lock Mirror: %s reports a different lock table; resyncing", n)
+			mirroredDigests[n] = reported
+		}
+		lockMirrorMu.Unlock()
+	}
+}
+
+func startLockMirror() {
+	ticker := time.NewTicker(lockMirrorInterval)
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), lockMirrorInterval)
+		select {
+		case <-lockMirrorKick:
+		case <-ticker.C:
+			reconcileLockMirror(ctx)
+		}
+		for n, err := range pushLockMirror(ctx) {
+			log.Printf("🔏 Lock Mirror: %s not updated: %v", n, err)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,412 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Lock Manager
+//
+// Locks are leases: each has an owner and a lease token, expires unless
+// renewed, and can only be released by its owner. Anyone else needs a forced
+// release, which names a human approver and is journaled. A lock on a
+// collection or prefab root also covers the descendants the mirrored engine
+// hierarchies report when it is taken. Mutations of a UUID covered by another
+// owner's lease are refused. lockTable is keyed by the locked UUID; lockMu
+// guards it.
+const (
+	defaultLockTTL = 30 * time.Second
+	maxLockTTL     = 10 * time.Minute
+	perimeterKey   = "GLOBAL_PERIMETER"
+	perimeterTTL   = 60 * time.Second
+	PerimeterFile  = PersistenceDir + "/perimeter.json"
+)
+
+var heldWorkOrders []WorkOrder // Orders queued behind the perimeter, guarded by lockMu
+
+func newLease() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func lockTTL(seconds int) time.Duration {
+	ttl := defaultLockTTL
+	if seconds > 0 { ttl = time.Duration(seconds) * time.Second }
+	if ttl > maxLockTTL { ttl = maxLockTTL }
+	return ttl
+}
+
+func lockLive(l *VibeLock, now time.Time) bool { return l != nil && now.Before(l.ExpiresAt) }
+
+// lockCoveringLocked returns the live lock that covers uuid, either directly
+// or through a hierarchical lock on one of its ancestors. lockMu must be held.
+func lockCoveringLocked(uuid string, now time.Time) *VibeLock {
+	if l := lockTable[uuid]; lockLive(l, now) { return l }
+	for _, l := range lockTable {
+		if lockLive(l, now) && containsString(l.Covers, uuid) { return l }
+	}
+	return nil
+}
+
+// pruneLocksLocked drops expired leases. lockMu must be held.
+func pruneLocksLocked(now time.Time) {
+	for id, l := range lockTable {
+		if !lockLive(l, now) { delete(lockTable, id) }
+	}
+}
+
+// acquireLock takes or refreshes a lease on uuid and, for hierarchical locks,
+// on every descendant in covers. It fails if any of them is covered by a live
+// lock with a different owner. Refreshing a lock the owner already holds
+// requires its lease.
+func acquireLock(uuid string, typ LockType, actor Actor, owner, lease string, covers []string, ttl time.Duration) (*VibeLock, error) {
+	if uuid == "" || owner == "" { return nil, fmt.Errorf("LOCK_INVALID: uuid and owner are required") }
+	now := time.Now()
+	lockMu.Lock()
+	defer lockMu.Unlock()
+	pruneLocksLocked(now)
+	return acquireLockLocked(uuid, typ, actor, owner, lease, covers, ttl, now)
+}
+
+// acquireLockLocked is acquireLock for callers that hold lockMu and have
+// pruned the table.
+func acquireLockLocked(uuid string, typ LockType, actor Actor, owner, lease string, covers []string, ttl time.Duration, now time.Time) (*VibeLock, error) {
+
+	for _, id := range append([]string{uuid}, covers...) {
+		if l := lockCoveringLocked(id, now); l != nil && l.Owner != owner {
+			return nil, fmt.Errorf("LOCK_HELD: %s is locked by %s (%s) until %s", id, l.Owner, l.Type, l.ExpiresAt.Format(time.RFC3339))
+		}
+	}
+
+	l, ok := lockTable[uuid]
+	if ok && l.Owner == owner && l.Lease != lease { return nil, fmt.Errorf("LOCK_NOT_OWNER: %s is already held by %s under another lease", uuid, owner) }
+	if !ok || l.Owner != owner {
+		l = &VibeLock{UUID: uuid, Owner: owner, Lease: newLease(), Timestamp: now}
+		lockTable[uuid] = l
+	} else {
+		l.Renewals++
+	}
+	l.Type, l.Actor, l.Covers, l.ExpiresAt = typ, actor, covers, now.Add(ttl)
+	if l.Renewals == 0 { kickLockMirror() }
+	return l, nil
+}
+
+// renewLock extends a lease held by owner.
+func renewLock(uuid, owner, lease string, ttl time.Duration) (*VibeLock, error) {
+	now := time.Now()
+	lockMu.Lock()
+	defer lockMu.Unlock()
+	l, ok := lockTable[uuid]
+	if !ok || !lockLive(l, now) { return nil, fmt.Errorf("LOCK_NOT_HELD: %s has no live lock", uuid) }
+	if l.Owner != owner || l.Lease != lease { return nil, fmt.Errorf("LOCK_NOT_OWNER: %s is held by %s", uuid, l.Owner) }
+	l.ExpiresAt = now.Add(ttl)
+	l.Renewals++
+	return l, nil
+}
+
+// releaseLock ends a lease. Owners release with their lease token; anyone else
+// must force the release and say who authorized it and why.
+func releaseLock(args ReleaseLockArgs) error {
+	lockMu.Lock()
+	l, ok := lockTable[args.UUID]
+	if !ok { lockMu.Unlock(); return nil }
+	owned := l.Owner == args.Owner && l.Lease == args.Lease
+	if !owned && !args.Force {
+		lockMu.Unlock()
+		return fmt.Errorf("LOCK_NOT_OWNER: %s is held by %s; a forced release needs an approver and reason", args.UUID, l.Owner)
+	}
+	if !owned && (args.Approver == "" || args.Reason == "") {
+		lockMu.Unlock()
+		return fmt.Errorf("LOCK_FORCE_INVALID: approver and reason are required to break another owner's lock")
+	}
+	delete(lockTable, args.UUID)
+	if args.UUID == perimeterKey { savePerimeterLocked() } // Or a restart would restore it
+	prev := *l
+	lockMu.Unlock()
+	kickLockMirror()
+
+	if !owned {
+		log.Printf("🔓 Lock Manager: %s lock on %s force-released by %s (%s)", prev.Owner, args.UUID, args.Approver, args.Reason)
+		journalOperation(map[string]interface{}{
+			"type":     "lock_forced_release",
+			"uuid":     args.UUID,
+			"owner":    prev.Owner,
+			"lock":     prev.Type,
+			"by":       args.Owner,
+			"approver": args.Approver,
+			"reason":   args.Reason,
+		})
+		dispatchVibeEvent(LevelWarn, "lock_forced_release", "", "NOTIFY_OWNER", map[string]interface{}{"uuid": args.UUID, "owner": prev.Owner, "approver": args.Approver})
+	}
+	return nil
+}
+
+func checkHumanLock(uuid string) error {
+	lockMu.RLock()
+	defer lockMu.RUnlock()
+	now := time.Now()
+	if l := lockTable[uuid]; lockLive(l, now) && l.Type == LockHumanActive { return humanLockError(uuid) }
+	for _, l := range lockTable {
+		if lockLive(l, now) && l.Type == LockHumanActive && containsString(l.Covers, uuid) { return humanLockError(uuid) }
+	}
+	return nil
+}
+
+func humanLockError(uuid string) error {
+	return fmt.Errorf("WAIT_HUMAN_LOCK: UUID %s is under active human manipulation", uuid)
+}
+
+// checkLease refuses a mutation of uuid by holder while another owner's live
+// lease covers it. Human locks are reported by checkHumanLock.
+func checkLease(holder, uuid string) error {
+	lockMu.RLock()
+	defer lockMu.RUnlock()
+	now := time.Now()
+	for id, l := range lockTable {
+		if !lockLive(l, now) || l.Type == LockPerimeter || l.Owner == holder { continue }
+		if id == uuid || containsString(l.Covers, uuid) {
+			return fmt.Errorf("LOCK_HELD: %s is leased to %s (%s) until %s", uuid, l.Owner, l.Type, l.ExpiresAt.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// checkLocks is the lock gate for a mutation by holder.
+func checkLocks(holder, uuid string) error {
+	if err := checkHumanLock(uuid); err != nil { return err }
+	return checkLease(holder, uuid)
+}
+
+// Creation Perimeter (SECURITY_GOVERNANCE.md §9)
+//
+// While the perimeter is locked nothing enters or leaves either scene and the
+// hierarchy holds still: structural and destructive operations are refused,
+// while cosmetic edits of objects that already exist carry on. Work orders are
+// not refused but held, and go out in order once the perimeter is released or
+// its lease runs out. The perimeter is a lease like any other lock: the
+// holder renews it with its lease token for as long as the creation work
+// needs, and releases it with the same token. The perimeter and the held
+// orders are persisted, so a restart neither lifts the one nor loses the
+// other.
+
+// perimeterState is what PerimeterFile keeps across restarts.
+type perimeterState struct {
+	Lock *VibeLock   `json:"lock,omitempty"`
+	Held []WorkOrder `json:"held"`
+}
+
+// savePerimeterLocked persists the perimeter lease and held orders. Caller
+// holds lockMu.
+func savePerimeterLocked() {
+	st := perimeterState{Held: heldWorkOrders}
+	if l := lockTable[perimeterKey]; lockLive(l, time.Now()) { st.Lock = l }
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil { return }
+	os.WriteFile(PerimeterFile, data, 0644)
+}
+
+func loadPerimeter() {
+	data, err := os.ReadFile(PerimeterFile)
+	if err != nil { return }
+	var st perimeterState
+	if err := json.Unmarshal(data, &st); err != nil { return }
+	lockMu.Lock()
+	defer lockMu.Unlock()
+	if lockLive(st.Lock, time.Now()) { lockTable[perimeterKey] = st.Lock }
+	heldWorkOrders = st.Held
+}
+
+// setPerimeter takes, renews or releases the perimeter lease. Taking it while
+// another lease holds it, or renewing or releasing it without its lease, is
+// refused; releasing a perimeter that has already lapsed is not.
+func setPerimeter(args PerimeterLockArgs) (*VibeLock, error) {
+	lockMu.Lock()
+	defer lockMu.Unlock()
+	now := time.Now()
+	cur := lockTable[perimeterKey]
+	if !lockLive(cur, now) { cur = nil }
+	if cur != nil && args.Lease != cur.Lease {
+		if args.Locked { return nil, fmt.Errorf("LOCK_HELD: the creation perimeter is held under another lease until %s", cur.ExpiresAt.Format(time.RFC3339)) }
+		return nil, fmt.Errorf("LOCK_NOT_OWNER: releasing the creation perimeter requires its lease")
+	}
+	if !args.Locked {
+		delete(lockTable, perimeterKey)
+		savePerimeterLocked()
+		return nil, nil
+	}
+	ttl := perimeterTTL
+	if args.TTLSeconds > 0 { ttl = lockTTL(args.TTLSeconds) }
+	if cur == nil {
+		cur = &VibeLock{UUID: perimeterKey, Type: LockPerimeter, Actor: ActorSystem, Owner: string(ActorSystem), Lease: newLease(), Timestamp: now}
+		lockTable[perimeterKey] = cur
+	}
+	cur.ExpiresAt = now.Add(ttl)
+	savePerimeterLocked()
+	c := *cur
+	return &c, nil
+}
+
+// perimeterLock returns the live perimeter lock, if any.
+func perimeterLock() *VibeLock {
+	lockMu.RLock()
+	defer lockMu.RUnlock()
+	if l := lockTable[perimeterKey]; lockLive(l, time.Now()) { c := *l; return &c }
+	return nil
+}
+
+func checkPerimeter(class IntentClass, op string) error {
+	if class == ClassCosmetic { return nil }
+	if l := perimeterLock(); l != nil {
+		return fmt.Errorf("PERIMETER_LOCKED: %s is %s and the creation perimeter is locked until %s; only cosmetic edits of existing objects may proceed", op, class, l.ExpiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// holdWorkOrder queues an order that the perimeter blocks. It reports false if
+// the order may be dispatched now.
+func holdWorkOrder(order WorkOrder) bool {
+	if checkPerimeter(classifyIntent(order.Opcode, "", nil), "work order") == nil { return false }
+	lockMu.Lock()
+	heldWorkOrders = append(heldWorkOrders, order)
+	n := len(heldWorkOrders)
+	savePerimeterLocked()
+	lockMu.Unlock()
+	log.Printf("🚧 Perimeter: Work order %s held (%d queued)", order.ID, n)
+	return true
+}
+
+// releaseHeldWorkOrders dispatches queued orders once the perimeter is down.
+// Orders that cannot be written to their inbox stay held for the next pass.
+func releaseHeldWorkOrders() {
+	if perimeterLock() != nil { return }
+	lockMu.Lock()
+	orders := heldWorkOrders
+	heldWorkOrders = nil
+	lockMu.Unlock()
+	if len(orders) == 0 { return }
+	var failed []WorkOrder
+	for _, o := range orders {
+		if err := writeWorkOrder(o); err != nil { log.Printf("🚧 Perimeter: Work order %s not released: %v", o.ID, err); failed = append(failed, o) }
+	}
+	lockMu.Lock()
+	heldWorkOrders = append(failed, heldWorkOrders...)
+	savePerimeterLocked()
+	lockMu.Unlock()
+	log.Printf("🚧 Perimeter: Released %d held work orders", len(orders)-len(failed))
+}
+
+// humanLockFree reports whether none of uuids is under a HUMAN_ACTIVE lock.
+func humanLockFree(uuids []string) bool {
+	for _, id := range uuids {
+		if checkHumanLock(id) != nil { return false }
+	}
+	return true
+}
+
+// acquireHumanLock takes or refreshes a HUMAN_ACTIVE lease for an editor user.
+// A human is never blocked by an AI lease: any non-human lock covering uuid is
+// preempted and returned. Another human already holding uuid is left alone and
+// reported as an error. The check, the preemption and the new lease happen
+// under one hold of lockMu, so no AI lease can slip in between.
+func acquireHumanLock(uuid, owner string, ttl time.Duration) (*VibeLock, []VibeLock, error) {
+	now := time.Now()
+	lockMu.Lock()
+	defer lockMu.Unlock()
+	pruneLocksLocked(now)
+	for id, l := range lockTable {
+		if l.Type == LockHumanActive && l.Owner != owner && (id == uuid || containsString(l.Covers, uuid)) {
+			return nil, nil, fmt.Errorf("LOCK_HELD: %s is locked by %s (%s) until %s", uuid, l.Owner, l.Type, l.ExpiresAt.Format(time.RFC3339))
+		}
+	}
+	var preempted []VibeLock
+	lease := ""
+	if l := lockTable[uuid]; l != nil && l.Owner == owner { lease = l.Lease } // The editor's own activity refreshes its lease
+	for id, l := range lockTable {
+		if l.Type == LockHumanActive || l.Type == LockPerimeter { continue }
+		if id == uuid || containsString(l.Covers, uuid) {
+			preempted = append(preempted, publicLock(l))
+			delete(lockTable, id)
+		}
+	}
+	l, err := acquireLockLocked(uuid, LockHumanActive, ActorHuman, owner, lease, nil, ttl, now)
+	return l, preempted, err
+}
+
+// publicLock hides the lease token, which is the owner's proof of ownership.
+func publicLock(l *VibeLock) VibeLock {
+	out := *l
+	out.Lease = ""
+	return out
+}
+
+// apply_lock takes an agent's AI_SPECULATIVE lease. HUMAN_ACTIVE locks only
+// come from editor activity reports and the perimeter only from a
+// human-signed perimeter_lock, so neither can be requested here.
+func apply_lock(ctx context.Context, req *mcp.CallToolRequest, args ApplyLockArgs) (*mcp.CallToolResult, any, error) {
+	if args.LockType == "" { args.LockType = LockAISpeculative }
+	if args.LockType != LockAISpeculative || (args.Actor != "" && args.Actor != ActorAI) {
+		return nil, nil, fmt.Errorf("LOCK_TYPE_DENIED: apply_lock only takes %s locks for %s; %s comes from editor activity and %s from perimeter_lock", LockAISpeculative, ActorAI, LockHumanActive, LockPerimeter)
+	}
+	covers, err := hierarchyDescendants(ctx, args.UUID)
+	if err != nil { return nil, nil, err }
+	l, err := acquireLock(args.UUID, LockAISpeculative, ActorAI, args.Owner, args.Lease, covers, lockTTL(args.TTLSeconds))
+	if err != nil { return nil, nil, err }
+	updateBridgeActivity(fmt.Sprintf("KERNEL: LOCK_%s", args.UUID))
+	return wrapForensicResult(map[string]interface{}{"status": "LOCKED", "lease": l.Lease, "expires_at": l.ExpiresAt}), nil, nil
+}
+
+func renew_lock(ctx context.Context, req *mcp.CallToolRequest, args RenewLockArgs) (*mcp.CallToolResult, any, error) {
+	l, err := renewLock(args.UUID, args.Owner, args.Lease, lockTTL(args.TTLSeconds))
+	if err != nil { return nil, nil, err }
+	return wrapForensicResult(map[string]interface{}{"status": "RENEWED", "expires_at": l.ExpiresAt}), nil, nil
+}
+
+func release_lock(ctx context.Context, req *mcp.CallToolRequest, args ReleaseLockArgs) (*mcp.CallToolResult, any, error) {
+	if args.Force {
+		if err := verifyHuman(args.Approver, args.Signature, "force_release_lock", args); err != nil { return nil, nil, err }
+	}
+	if err := releaseLock(args); err != nil { return nil, nil, err }
+	updateBridgeActivity("KERNEL: READY")
+	return wrapForensicResult("RELEASED"), nil, nil
+}
+
+// inspect_locks lists live locks, or the lock covering a single UUID.
+func inspect_locks(ctx context.Context, req *mcp.CallToolRequest, args InspectLocksArgs) (*mcp.CallToolResult, any, error) {
+	now := time.Now()
+	lockMu.Lock()
+	defer lockMu.Unlock()
+	pruneLocksLocked(now)
+	if args.UUID != "" {
+		l := lockCoveringLocked(args.UUID, now)
+		if l == nil { return wrapForensicResult(map[string]interface{}{"uuid": args.UUID, "locked": false}), nil, nil }
+		return wrapForensicResult(map[string]interface{}{"uuid": args.UUID, "locked": true, "lock": publicLock(l)}), nil, nil
+	}
+	out := make([]VibeLock, 0, len(lockTable))
+	for _, l := range lockTable { out = append(out, publicLock(l)) }
+	sort.Slice(out, func(i, j int) bool { return out[i].UUID < out[j].UUID })
+	return wrapForensicResult(out), nil, nil
+}
@@ -0,0 +1,155 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Log-Driven Governance
+//
+// The log hash is computed by the orchestrator, never supplied by the agent.
+// A window is the last logWindowSize lines of wal.jsonl up to and including a
+// WAL entry, plus the last logWindowSize lines of events.jsonl among the first
+// EventCount. Anchoring the window this way lets an agent read it, think, and
+// ingest it even though both logs keep growing in the meantime.
+//
+// The window is handed out without its hash. The agent proves it read the
+// lines by hashing them itself: hex sha256 of "wal\n", the WAL lines joined by
+// "\n", "\nevents\n", then the event lines joined by "\n".
+const logWindowSize = 50
+
+var ingestedWindows = map[string]LogWindow{} // agent ID -> window it last ingested; guarded by logIngestMu
+
+// readLines returns the complete lines of a log. A trailing line without its
+// newline is still being written and is not part of any window yet.
+func readLines(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil { return nil }
+	lines := strings.Split(string(data), "\n")
+	return lines[:len(lines)-1]
+}
+
+func tail(lines []string, n int) []string {
+	if len(lines) > n { return lines[len(lines)-n:] }
+	return lines
+}
+
+// readLogWindow builds the window ending at WAL entry walHead and event line
+// eventCount. An empty walHead or a negative eventCount means "now".
+func readLogWindow(walHead string, eventCount int) (LogWindow, error) {
+	w := LogWindow{}
+	wal := readLines(WalFile)
+	end := len(wal)
+	if walHead != "" {
+		end = -1
+		for i := len(wal) - 1; i >= 0 && end < 0; i-- {
+			var e struct{ Hash string `json:"hash"` }
+			if json.Unmarshal([]byte(wal[i]), &e) == nil && e.Hash == walHead { end = i + 1 }
+		}
+		if end < 0 { return w, fmt.Errorf("LOG_WINDOW_UNAVAILABLE: WAL entry %s is not in the current WAL", walHead) }
+	}
+	w.WalHead, w.StateHead = walHeads(wal[:end])
+	// Right after a rotation the state head may still be in the old file
+	if w.StateHead == "" { _, w.StateHead = walHeads(readLines(WalFile + ".old")) }
+
+	events := readLines(EventFile)
+	if eventCount < 0 { eventCount = len(events) }
+	if eventCount > len(events) { return w, fmt.Errorf("LOG_WINDOW_UNAVAILABLE: only %d events recorded, window claims %d", len(events), eventCount) }
+
+	w.EventCount, w.Wal, w.Events = eventCount, tail(wal[:end], logWindowSize), tail(events[:eventCount], logWindowSize)
+	w.LogHash = logWindowHash(w.Wal, w.Events)
+	return w, nil
+}
+
+// walHeads returns the hash of the last WAL line and of the last one that
+// changed the scene.
+func walHeads(lines []string) (head, stateHead string) {
+	for _, line := range lines {
+		var e struct{ Hash, Type string }
+		if json.Unmarshal([]byte(line), &e) != nil { continue }
+		head = e.Hash
+		if !auditOnlyJournalTypes[e.Type] { stateHead = e.Hash }
+	}
+	return head, stateHead
+}
+
+func logWindowHash(wal, events []string) string {
+	h := sha256.New()
+	h.Write([]byte("wal\n" + strings.Join(wal, "\n") + "\nevents\n" + strings.Join(events, "\n")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// currentStateHead is the hash of the newest WAL entry that changed the scene.
+func currentStateHead() string {
+	walMu.Lock()
+	defer walMu.Unlock()
+	return lastStateHash
+}
+
+// Log-Driven Governance: the envelope must cite the window its agent ingested,
+// and that window must still reach the current state head.
+func checkLogIngestion(env IntentEnvelope) error {
+	logIngestMu.Lock()
+	ingested := ingestedWindows[env.AgentID]
+	logIngestMu.Unlock()
+	if ingested.LogHash == "" {
+		return fmt.Errorf("LOG_INGESTION_REQUIRED: %s has not ingested a forensic log window", env.AgentID)
+	}
+	if env.BasedOnHashes["log"] != ingested.LogHash {
+		return fmt.Errorf("LOG_INGESTION_REQUIRED: Intent must be grounded in latest forensic logs")
+	}
+	if head := currentStateHead(); ingested.StateHead != head {
+		return fmt.Errorf("LOG_INGESTION_REQUIRED: Ingested logs end at %s but the WAL head is %s", ingested.StateHead, head)
+	}
+	return nil
+}
+
+// get_forensic_log_window returns the current window and its anchors, but not
+// its hash. The agent hashes the lines and hands the anchors and its hash back
+// to ingest_forensic_logs.
+func get_forensic_log_window(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+	w, err := readLogWindow("", -1)
+	if err != nil { return nil, nil, err }
+	return wrapForensicResult(w), nil, nil
+}
+
+// ingest_forensic_logs records the window as read by the signing agent only;
+// another agent's reading does not ground its intents.
+func ingest_forensic_logs(ctx context.Context, req *mcp.CallToolRequest, args IngestLogsArgs) (*mcp.CallToolResult, any, error) {
+	if err := verifyAgentSignature(args.AgentID, args.Signature, "ingest_forensic_logs", args); err != nil { return nil, nil, err }
+	w, err := readLogWindow(args.WalHead, args.EventCount)
+	if err != nil { return nil, nil, err }
+	if args.LogHash != w.LogHash {
+		return nil, nil, fmt.Errorf("LOG_HASH_MISMATCH: %s is not the hash of the window ending at %s/%d", args.LogHash, args.WalHead, args.EventCount)
+	}
+	if head := currentStateHead(); w.StateHead != head {
+		return nil, nil, fmt.Errorf("LOG_WINDOW_STALE: Window ends at %s but the WAL head is %s", w.StateHead, head)
+	}
+	logIngestMu.Lock()
+	ingestedWindows[args.AgentID] = w
+	logIngestMu.Unlock()
+	log.Printf("📜 Log-Driven Governance: %s has ingested history at hash %s", args.AgentID, w.LogHash)
+	return wrapForensicResult("INGESTED"), nil, nil
+}
@@ -32,6 +32,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"strings"
 	"sync"
@@ -56,6 +57,7 @@ const (
 // Global State
 var (
 	unityPort      = 8087
+	blenderPort    = BlenderPort
 	engines = map[string]*EngineData{
 		"unity":   {Token: "5715493b", State: StateStopped},
 		"blender": {Token: "VIBE_BLENDER_BOOTSTRAP_SECRET", State: StateStopped},
@@ -67,7 +69,7 @@ var (
 	stateMu          sync.RWMutex
 	startTime        = time.Now()
 
-	intents      = make(map[string]IntentEnvelope)
+	intents      = make(map[string]*IntentRecord)
 	transactions = make(map[string]*VibeTransaction)
 	txMu         sync.Mutex
 
@@ -76,8 +78,9 @@ var (
 	monotonicID int64
 	clockMu     sync.Mutex
 
-	lastWalHash string
-	walMu       sync.Mutex
+	lastWalHash   string
+	lastStateHash string // Hash of the last entry that changed world state (see auditOnlyJournalTypes)
+	walMu         sync.Mutex
 
 	requestCounts = make(map[string]int)
 	rateMu        sync.Mutex
@@ -87,12 +90,11 @@ var (
 	lockMu    sync.RWMutex
 
 	// Intent Coalescing
-	intentBuffer = make(map[string]*WalEntry)
+	intentBuffer = make(map[string]*bufferedIntent)
 	bufferMu     sync.Mutex
 
 	// Log-Driven Governance
-	lastLogIngestedHash string
-	logIngestMu         sync.Mutex
+	logIngestMu sync.Mutex
 
 	// Anti-Thrashing Table (Failure Signatures)
 	failureRegistry = make(map[string]int) // Hash -> Count
@@ -115,8 +117,6 @@ var (
 	// Second-Order Invariant State
 	idempotencyMap = make(map[string]string) // key -> hash
 	idempMu        sync.Mutex
-	sessionEntropy = 0
-	maxEntropy     = 100 // Default budget
 	entropyMu      sync.Mutex
 	schemaVersion  = "bridge.v0.4.0"
 
@@ -149,8 +149,14 @@ type VibeTransaction struct {
 	IntentID  string    `json:"intent_id"`
 	StartTime time.Time `json:"start_time"`
 	Status    string    `json:"status"`
+	Agent     string    `json:"agent,omitempty"` // verified signer of the owning intent
 }
 
+const (
+	TxOpen        = "OPEN"
+	TxQuarantined = "QUARANTINED"
+)
+
 type EngineData struct {
 	Token         string      `json:"token"`
 	State         EngineState `json:"state"`
@@ -226,7 +232,9 @@ func isPerformanceOp(endpoint string) bool {
 	return false
 }
 
-func init() {
+// preparePersistence lays out .vibesync (and its mailboxes) under the
+// working directory.
+func preparePersistence() {
 	if _, err := os.Stat(PersistenceDir); os.IsNotExist(err) { os.Mkdir(PersistenceDir, 0755) }
 	
 	// Initialize 5-Agent Mailbox Structure
@@ -237,7 +245,21 @@ func init() {
 
 	sandbox := filepath.Join(PersistenceDir, "tmp")
 	if _, err := os.Stat(sandbox); os.IsNotExist(err) { os.Mkdir(sandbox, 0755) }
+}
+
+func init() {
+	preparePersistence()
 	loadState()
+	loadIntents()
+	loadApprovals()
+	loadPlans()
+	loadEntropy()
+	loadDrift()
+	loadFacts()
+	loadWalIndex()
+	loadPerimeter()
+	if err := loadPolicy(); err != nil { log.Printf("📜 Governance Policy: %v (using builtin default)", err) }
+	if err := loadAgentKeys(); err != nil { log.Printf("🔑 Agent Registry: %v (all envelopes will be refused)", err) }
 
 	// 1. Token & Port Discovery
 	token := discoverUnityToken()
@@ -250,7 +272,7 @@ func init() {
 	unityPort = port
 	if e, ok := engines["unity"]; ok {
 		e.Token = token
-		lastWalHash = hash
+		lastWalHash = hash // lastStateHash was resumed from the WAL by loadWalIndex
 		monotonicID = tick
 		log.Printf("🛡️ VibeSync Discovery: Port=%d | Token=%s | Hash=%s | Tick=%d", port, token, hash, tick)
 	}
@@ -259,54 +281,11 @@ func init() {
 	go startSyncLoop()
 	go startHeartbeatWatcher()
 	go startCoalescingLoop()
-}
-
-func startCoalescingLoop() {
-	ticker := time.NewTicker(250 * time.Millisecond)
-	for range ticker.C {
-		flushIntentBuffer()
-	}
-}
-
-func flushIntentBuffer() {
-	bufferMu.Lock()
-	count := len(intentBuffer)
-	if count == 0 {
-		bufferMu.Unlock()
-		return
-	}
-	log.Printf("🌊 VibeSync Batching: Finalizing %d speculative intents", count)
-	
-	// In a full implementation, we'd trigger a combined verification hash here
-	// and promote WAL entries from PROVISIONAL to FINAL.
-	
-	intentBuffer = make(map[string]*WalEntry)
-	bufferMu.Unlock()
-}
-
-func bufferSpeculativeIntent(uuid string, op string, data interface{}) {
-	bufferMu.Lock()
-	defer bufferMu.Unlock()
-	
-	// Create a virtual WalEntry for the buffer
-	intentBuffer[uuid] = &WalEntry{
-		IntentID: uint64(monotonicID),
-		Engine:   "orchestrator",
-		Actor:    "ai",
-		Scope:    WalScope{UUIDs: []string{uuid}, Class: ClassCosmetic},
-		Phase:    PhaseProvisional,
-	}
-}
-
-func checkHumanLock(uuid string) error {
-	lockMu.RLock()
-	defer lockMu.RUnlock()
-	if lock, ok := lockTable[uuid]; ok {
-		if lock.Type == LockHumanActive && time.Now().Before(lock.ExpiresAt) {
-			return fmt.Errorf("WAIT_HUMAN_LOCK: UUID %s is under active human manipulation", uuid)
-		}
-	}
-	return nil
+	go startIntentJanitor()
+	go startFactJanitor()
+	go startPolicyWatcher()
+	go startLockMirror()
+	go startDeadlockMonitor()
 }
 
 func startSyncLoop() {
@@ -330,6 +309,7 @@ func startSyncLoop() {
 			}
 		}
 		stateMu.Unlock()
+		releaseHeldWorkOrders() // The perimeter lease may have run out
 	}
 }
 
@@ -355,14 +335,8 @@ func isRateLimited(target string) bool {
 	return false
 }
 
-func checkInvariants(idempKey, targetHash string) error {
-	entropyMu.Lock()
-	if sessionEntropy >= maxEntropy {
-		entropyMu.Unlock()
-		return fmt.Errorf("INVARIANT_VIOLATION: Entropy budget exhausted")
-	}
-	sessionEntropy++
-	entropyMu.Unlock()
+func checkInvariants(actor string, class IntentClass, idempKey, targetHash string) error {
+	if err := chargeEntropy(actor, class); err != nil { return err }
 
 	if idempKey != "" {
 		idempMu.Lock()
@@ -399,14 +373,37 @@ func sanitizeForTarget(target string, data interface{}) interface{} {
 	return sanitized
 }
 
+// auditPayload rejects payloads carrying a blocked command in any string or a
+// NaN/Inf in any number. Only values are inspected, so a label such as
+// "Infantry" is not mistaken for a number.
 func auditPayload(data interface{}) error {
 	if data == nil { return nil }
-	jsonBytes, _ := json.Marshal(data)
-	payload := strings.ToLower(string(jsonBytes))
-
-	blocked := []string{"os.system", "exec(", "eval(", "rm -rf", "reflection", "process.start", "import ", "__import__", "powershell", "cmd.exe", "/bin/sh", "/bin/bash"}
-	for _, b := range blocked { if strings.Contains(payload, b) { return fmt.Errorf("SECURITY_VIOLATION: %s", b) } }
-	if strings.Contains(payload, "nan") || strings.Contains(payload, "inf") { return fmt.Errorf("NUMERICAL_INSTABILITY: NaN/Inf detected") }
+	return auditValue(reflect.ValueOf(data))
+}
+
+var blockedPayloadSubstrings = []string{"os.system", "exec(", "eval(", "rm -rf", "reflection", "process.start", "import ", "__import__", "powershell", "cmd.exe", "/bin/sh", "/bin/bash"}
+
+func auditValue(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() { return nil }
+		return auditValue(v.Elem())
+	case reflect.String:
+		s := strings.ToLower(v.String())
+		for _, b := range blockedPayloadSubstrings { if strings.Contains(s, b) { return fmt.Errorf("SECURITY_VIOLATION: %s", b) } }
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); math.IsNaN(f) || math.IsInf(f, 0) { return fmt.Errorf("NUMERICAL_INSTABILITY: NaN/Inf detected") }
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := auditValue(iter.Key()); err != nil { return err }
+			if err := auditValue(iter.Value()); err != nil { return err }
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ { if err := auditValue(v.Index(i)); err != nil { return err } }
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ { if v.Type().Field(i).IsExported() { if err := auditValue(v.Field(i)); err != nil { return err } } }
+	}
 	return nil
 }
 
@@ -439,14 +436,13 @@ func getForensicReport() map[string]interface{} {
 	for name, data := range engines { health[name] = fmt.Sprintf("State: %s | Gen: %d", data.State, data.Generation) }
 	stateMu.RUnlock()
 	report["engine_status"], report["system_time"] = health, time.Now().Format(time.RFC3339)
+	if b := intentBudgets(); len(b) > 0 { report["intent_budgets"] = b }
 	return report
 }
 
 func wrapForensicResult(data interface{}) *mcp.CallToolResult {
 	report := getForensicReport()
-	entropyMu.Lock()
-	report["entropy_stats"] = map[string]int{"limit": maxEntropy, "used": sessionEntropy}
-	entropyMu.Unlock()
+	report["entropy_stats"] = entropySnapshot()
 	wrapped := map[string]interface{}{
 		"result":          data,
 		"forensic_report": report,
@@ -456,13 +452,23 @@ func wrapForensicResult(data interface{}) *mcp.CallToolResult {
 	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(jsonStr)}}}
 }
 
-func sendToEngine(target, endpoint, method string, data interface{}) (map[string]interface{}, error) {
+// readOnlyPosts are POST endpoints that only observe the scene.
+var readOnlyPosts = map[string]bool{"export": true, "validate": true, "preflight/run": true}
+
+// isMutationCall reports whether a call can change an engine's scene. The
+// handshake and the lock mirror are orchestrator bookkeeping: they are neither
+// rate-counted as mutations nor followed by a state verification.
+func isMutationCall(method, endpoint string) bool {
+	return method == "POST" && !strings.Contains(endpoint, "handshake") && !strings.Contains(endpoint, lockMirrorEndpoint) && !readOnlyPosts[strings.TrimPrefix(endpoint, "/")]
+}
+
+func sendToEngine(ctx context.Context, target, endpoint, method string, data interface{}) (map[string]interface{}, error) {
 	stateMu.RLock(); engine, ok := engines[target]; stateMu.RUnlock()
 	if !ok { return nil, fmt.Errorf("unknown target") }
 
 	log.Printf("📡 DEBUG | sendToEngine: %s %s/%s", method, target, endpoint)
 
-	if method == "POST" && !strings.Contains(endpoint, "handshake") {
+	if isMutationCall(method, endpoint) {
 		stateMu.Lock()
 		now := time.Now()
 		if now.Sub(engine.LastMutation) < 200*time.Millisecond { engine.MutationCount++ } else { engine.MutationCount = 1 }
@@ -472,34 +478,50 @@ func sendToEngine(target, endpoint, method string, data interface{}) (map[string
 	}
 
 	if isRateLimited(target) { return nil, fmt.Errorf("RATE_LIMIT") }
-	if err := auditPayload(data); err != nil { dispatchVibeEvent(LevelError, "security_intercept", "", "PANIC", map[string]interface{}{"error": err.Error()}); decayTrust(target, 20, "AUDIT_VIOLATION"); return nil, err }
+	if err := auditPayload(data); err != nil {
+		// The engine pays in trust; drift is charged to the caller by the tool
+		// entry point, which knows the verified grant holder.
+		dispatchVibeEvent(LevelError, "security_intercept", "", "PANIC", map[string]interface{}{"error": err.Error()}); decayTrust(target, 20, "AUDIT_VIOLATION")
+		return nil, err
+	}
 	
 	endpoint = strings.TrimPrefix(endpoint, "/")
 	data = sanitizeForTarget(target, data)
 
 	var lastErr error
 	for i := 0; i < 3; i++ {
-		res, err := attemptSend(target, endpoint, method, data)
+		if ctx.Err() != nil { return nil, budgetError(ctx) }
+		res, err := attemptSend(ctx, target, endpoint, method, data)
 		if err == nil {
-			if res != nil && res["error"] == "Engine Busy: Compiling or Updating" { time.Sleep(2 * time.Second); continue }
-			if method == "POST" && !strings.Contains(endpoint, "handshake") {
+			if res != nil && res["error"] == "Engine Busy: Compiling or Updating" { if !sleepCtx(ctx, 2*time.Second) { return nil, budgetError(ctx) }; continue }
+			if isMutationCall(method, endpoint) {
 				go func() { ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second); defer cancel(); verifyEngineState(ctx, target, endpoint) }()
 			}
 			return res, nil
 		}
 		lastErr = err
-		time.Sleep(time.Duration(math.Pow(2, float64(i))) * 100 * time.Millisecond)
+		if !sleepCtx(ctx, time.Duration(math.Pow(2, float64(i)))*100*time.Millisecond) { return nil, budgetError(ctx) }
 	}
 	return nil, fmt.Errorf("ENGINE_ERROR | %v", lastErr)
 }
 
+// sleepCtx waits for d, returning false early if ctx is done first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done(): return false
+	case <-t.C: return true
+	}
+}
+
 func computeSignature(token, timestamp, method, path, body string) string {
 	h := hmac.New(sha256.New, []byte(token))
 	h.Write([]byte(timestamp + "|" + method + "|" + path + "|" + body))
 	return hex.EncodeToString(h.Sum(nil))
 }
 
-func attemptSend(target, endpoint, method string, data interface{}) (map[string]interface{}, error) {
+func attemptSend(ctx context.Context, target, endpoint, method string, data interface{}) (map[string]interface{}, error) {
 	stateMu.RLock(); engine, ok := engines[target]; stateMu.RUnlock()
 	if !ok || engine.State == StatePanic || engine.State == StateHumanReq { return nil, fmt.Errorf("LOCKED") }
 	if engine.State == StateQuarantine && method != "GET" && !strings.Contains(endpoint, "health") { return nil, fmt.Errorf("QUARANTINE_READ_ONLY") }
@@ -507,7 +529,7 @@ func attemptSend(target, endpoint, method string, data interface{}) (map[string]
 
 	isPerf := isPerformanceOp(endpoint)
 
-	port := unityPort; if target == "blender" { port = BlenderPort }
+	stateMu.RLock(); port := unityPort; if target == "blender" { port = blenderPort }; stateMu.RUnlock()
 	url := fmt.Sprintf("http://127.0.0.1:%d/%s", port, endpoint)
 	log.Printf("📡 DEBUG | attemptSend: %s %s (Token: %s)", method, url, engine.Token)
 	mid := nextMonotonicID()
@@ -527,7 +549,7 @@ func attemptSend(target, endpoint, method string, data interface{}) (map[string]
 		signature = computeSignature(engine.Token, timestamp, method, "/"+endpoint, bodyStr)
 	}
 
-	req, _ := http.NewRequest(method, url, bytes.NewBuffer(jsonBody))
+	req, _ := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(jsonBody))
 	req.Header.Set("X-Vibe-Token", engine.Token); 
 	if signature != "" {
 		req.Header.Set("X-Vibe-Signature", signature); 
@@ -546,13 +568,14 @@ func verifyEngineState(ctx context.Context, target, endpoint string) {
 	log.Printf("🔍 REFEREE | Verifying %s after %s", target, endpoint)
 	type result struct { res map[string]interface{}; err error }
 	done := make(chan result, 1)
-	go func() { res, err := sendToEngine(target, "state/get", "GET", nil); done <- result{res, err} }()
-	select { case <-ctx.Done(): log.Printf("🚨 VERIFICATION TIMEOUT | %s", target); case r := <-done: if r.err != nil { log.Printf("🚨 VERIFICATION FAILURE | %s: %v", target, r.err) } else { log.Printf("✅ VERIFIED | %s: %v", target, r.res["hash"]) } }
+	go func() { res, err := sendToEngine(ctx, target, "state/get", "GET", nil); done <- result{res, err} }()
+	select { case <-ctx.Done(): log.Printf("🚨 VERIFICATION TIMEOUT | %s", target); case r := <-done: if r.err != nil { log.Printf("🚨 VERIFICATION FAILURE | %s: %v", target, r.err) } else { log.Printf("✅ VERIFIED | %s: %v", target, r.res["hash"]); if r.res != nil && r.res["hash"] != nil { recordRefereeHash(target, fmt.Sprintf("%v", r.res["hash"])) } } }
 }
 
 func startHeartbeatWatcher() {
-	ticker := time.NewTicker(5 * time.Second); portMap := map[string]int{"unity": unityPort, "blender": BlenderPort}
+	ticker := time.NewTicker(5 * time.Second)
 	for range ticker.C {
+		stateMu.RLock(); portMap := map[string]int{"unity": unityPort, "blender": blenderPort}; stateMu.RUnlock()
 		targets := make(map[string]struct{ Port, Generation int })
 		stateMu.RLock()
 		for name, e := range engines { 
@@ -579,7 +602,7 @@ func startHeartbeatWatcher() {
 		wg.Wait(); if panicRequired { 
 			for name, e := range engines { 
 				if name == "blender" && e.State == StateStopped { continue } // Ignore offline Blender
-				go sendToEngine(name, "panic", "POST", map[string]interface{}{"reason": "HEARTBEAT_TIMEOUT"}) 
+				go sendToEngine(context.Background(), name, "panic", "POST", map[string]interface{}{"reason": "HEARTBEAT_TIMEOUT"}) 
 			} 
 		}
 	}
@@ -614,9 +637,7 @@ func get_bridge_pulse(ctx context.Context, req *mcp.CallToolRequest, args struct
 	bState := engines["blender"].State
 	stateMu.RUnlock()
 
-	entropyMu.Lock()
-	eUsed := sessionEntropy
-	entropyMu.Unlock()
+	eUsed, eLimit := entropyTotals()
 
 	walMu.Lock()
 	hash := lastWalHash
@@ -627,7 +648,7 @@ func get_bridge_pulse(ctx context.Context, req *mcp.CallToolRequest, args struct
 	}
 
 	pulse := fmt.Sprintf("[KERNEL: READY | UNITY: %s | BLENDER: %s | ENTROPY: %d/%d | WAL: %s]",
-		uState, bState, eUsed, maxEntropy, hash)
+		uState, bState, eUsed, eLimit, hash)
 	
 	return wrapForensicResult(pulse), nil, nil
 }
@@ -639,8 +660,8 @@ func verify_identity_parity(ctx context.Context, req *mcp.CallToolRequest, args
 	
 	results := make(map[string]map[string]string)
 	
-	uRes, _ := sendToEngine("unity", "object/exists", "POST", map[string]interface{}{"ids": args.IDs})
-	bRes, _ := sendToEngine("blender", "object/exists", "POST", map[string]interface{}{"ids": args.IDs})
+	uRes, _ := sendToEngine(ctx, "unity", "object/exists", "POST", map[string]interface{}{"ids": args.IDs})
+	bRes, _ := sendToEngine(ctx, "blender", "object/exists", "POST", map[string]interface{}{"ids": args.IDs})
 	
 	results["unity"] = make(map[string]string)
 	if uRes != nil && uRes["exists"] != nil {
@@ -669,7 +690,7 @@ func handshake_init(ctx context.Context, req *mcp.CallToolRequest, args Handshak
 		endpoint = "status" // Real VibeBridge uses status for health/handshake
 	}
 
-	res, err := sendToEngine(args.Target, endpoint, "POST", map[string]interface{}{"version": args.Version, "new_token": newToken, "challenge": chal})
+	res, err := sendToEngine(ctx, args.Target, endpoint, "POST", map[string]interface{}{"version": args.Version, "new_token": newToken, "challenge": chal})
 	
 	// Real VibeBridge returns {"status":"ok"} for /status
 	if err == nil && args.Target == "unity" && res["status"] == "ok" {
@@ -700,64 +721,97 @@ func handshake_init(ctx context.Context, req *mcp.CallToolRequest, args Handshak
 
 func read_engine_state(ctx context.Context, req *mcp.CallToolRequest, args ReadStateArgs) (*mcp.CallToolResult, any, error) {
 	endpoint := "state/get"; if args.Target == "unity" { endpoint = "scene/state" }
-	res, _ := sendToEngine(args.Target, endpoint, "GET", nil); return wrapForensicResult(res), nil, nil
+	res, _ := sendToEngine(ctx, args.Target, endpoint, "GET", nil); return wrapForensicResult(res), nil, nil
 }
 
 func verify_engine_state(ctx context.Context, req *mcp.CallToolRequest, args VerifyStateArgs) (*mcp.CallToolResult, any, error) {
-	res, _ := sendToEngine(args.Target, "state/get", "GET", nil); if fmt.Sprintf("%v", res["hash"]) == args.ExpectedHash { return wrapForensicResult("VERIFIED"), nil, nil }; return nil, nil, fmt.Errorf("DRIFT_DETECTED")
+	res, _ := sendToEngine(ctx, args.Target, "state/get", "GET", nil)
+	if len(args.Identities) > 0 {
+		// The expected hash was taken at specific prefab depths; it says nothing about any other depth
+		h, err := verifyAtDepth(res, args.Identities); if err != nil { return nil, nil, err }
+		if h == args.ExpectedHash { return wrapForensicResult("VERIFIED"), nil, nil }
+		return nil, nil, fmt.Errorf("DRIFT_DETECTED")
+	}
+	if fmt.Sprintf("%v", res["hash"]) == args.ExpectedHash { return wrapForensicResult("VERIFIED"), nil, nil }; return nil, nil, fmt.Errorf("DRIFT_DETECTED")
 }
 
-func submit_intent(ctx context.Context, req *mcp.CallToolRequest, args SubmitIntentArgs) (*mcp.CallToolResult, any, error) {
-	if args.Envelope.Rationale == "" || args.Envelope.Provenance == "" { return nil, nil, fmt.Errorf("TECHNICAL_RATIONALE_REQUIRED") }
-	
-	// 1. Log-Driven Governance: Check if AI has ingested recent history
-	logIngestMu.Lock()
-	if lastLogIngestedHash == "" {
-		// First intent of session, allow but warn in audit
-	} else if args.Envelope.BasedOnHashes["log"] != lastLogIngestedHash {
-		logIngestMu.Unlock()
-		return nil, nil, fmt.Errorf("LOG_INGESTION_REQUIRED: Intent must be grounded in latest forensic logs")
-	}
-	logIngestMu.Unlock()
+// intentCheck is a single named gate of the intent pipeline. Submission stops
+// at the first failing gate; a dry run evaluates every gate and reports them all.
+type intentCheck struct {
+	name string
+	run  func(env IntentEnvelope) error
+}
 
-	// 2. Intent Binding: Validate Opcode against declared Intent
-	if !validateOpcodeIntent(args.Envelope.Intent, args.Envelope.Opcode) {
-		return nil, nil, fmt.Errorf("INTENT_MISMATCH: Opcode %X not permitted for intent %s", args.Envelope.Opcode, args.Envelope.Intent)
-	}
+var intentChecks = []intentCheck{
+	{"signature", checkSignature},
+	{"rationale", checkIntentRationale},
+	{"log_ingestion", checkLogIngestion},
+	{"policy", checkPolicy},
+}
 
-	id := uuid.New().String(); txMu.Lock(); intents[id] = args.Envelope; txMu.Unlock()
-	return wrapForensicResult(id), nil, nil
+func checkIntentRationale(env IntentEnvelope) error {
+	if env.Rationale == "" || env.Provenance == "" { return fmt.Errorf("TECHNICAL_RATIONALE_REQUIRED") }
+	return nil
 }
 
-func validateOpcodeIntent(intent IntentType, opcode VibeOpcode) bool {
-	if opcode == 0 { return true } // No opcode specified
-	switch intent {
-	case IntentOptimize:
-		return opcode == OpModifier || opcode == OpAudit || opcode == OpSystem
-	case IntentRig:
-		return opcode == OpTransform || opcode == OpAudit
-	case IntentLight:
-		return opcode == OpNode || opcode == OpAudit
-	case IntentSceneSetup:
-		return true // General setup allows all
-	default:
-		return opcode == OpAudit || opcode == OpSystem
+// Intent Binding: Validate Opcode and scope against the governance policy
+func checkPolicy(env IntentEnvelope) error {
+	if d := evaluatePolicy(env, ""); !d.Allowed { return fmt.Errorf("%s", d.Reason) }
+	return nil
+}
+
+func submit_intent(ctx context.Context, req *mcp.CallToolRequest, args SubmitIntentArgs) (*mcp.CallToolResult, any, error) {
+	// Provenance first: nothing from an unverified caller reaches the WAL or
+	// an engine, not even as a dry run.
+	agent, err := verifyEnvelope(args.Envelope)
+	if err != nil { return nil, nil, err }
+	if args.Envelope.DryRun {
+		return wrapForensicResult(dryRunIntent(ctx, args.Envelope, args.OpSpec)), nil, nil
+	}
+	id := uuid.New().String()
+	class := classifyOpSpec(args.Envelope.Opcode, args.OpSpec)
+	decision := evaluatePolicy(args.Envelope, class)
+	journalPolicyDecision(id, agent.AgentID, "submit", decision)
+	for _, c := range intentChecks {
+		if err := c.run(args.Envelope); err != nil { return nil, nil, noteDeviation(agent.AgentID, err) }
 	}
+	if !decision.Allowed { return nil, nil, noteDeviation(agent.AgentID, fmt.Errorf("%s", decision.Reason)) }
+
+	rec := newIntentRecord(id, args.Envelope); rec.Agent, rec.Class, rec.Policy, rec.OpSpec = agent, class, decision, args.OpSpec
+	txMu.Lock(); intents[id] = rec; saveIntentsLocked(); txMu.Unlock()
+	return wrapForensicResult(id), nil, nil
 }
 
 func validate_intent(ctx context.Context, req *mcp.CallToolRequest, args struct{ID string `json:"intent_id"`}) (*mcp.CallToolResult, any, error) {
-	txMu.Lock(); intent, ok := intents[args.ID]; txMu.Unlock(); if !ok { return nil, nil, fmt.Errorf("UNKNOWN_INTENT") }
-	if intent.Confidence < 0.8 { stateMu.Lock(); for n := range engines { engines[n].State = StateHumanReq }; stateMu.Unlock(); return wrapForensicResult("HUMAN_INTERVENTION_REQUIRED"), nil, nil }
+	rec, err := lookupIntent(args.ID); if err != nil { return nil, nil, err }
+	txMu.Lock(); intent, agent, class, status, reason := rec.Envelope, rec.Agent, rec.Class, rec.Status, rec.Reason; txMu.Unlock()
+	if isIntentTerminal(status) { return nil, nil, fmt.Errorf("INTENT_%s: %s", status, reason) }
+
+	decision := evaluatePolicy(intent, class)
+	journalPolicyDecision(args.ID, agent.AgentID, "validate", decision)
+	txMu.Lock(); rec.Policy = decision; txMu.Unlock()
+	if !decision.Allowed { transitionIntent(args.ID, IntentRejected, decision.Reason); return nil, nil, noteDeviation(agent.AgentID, fmt.Errorf("%s", decision.Reason)) }
+	// Central DAG validation: a cycle is refused here, before any engine sees it.
+	txMu.Lock(); opSpec := rec.OpSpec; txMu.Unlock()
+	if err := checkOpSpecHierarchy(ctx, opSpec); err != nil { transitionIntent(args.ID, IntentRejected, err.Error()); return nil, nil, err }
+	if decision.RequiredApprovals > 0 {
+		// Hold only this intent for review; engines and unrelated intents keep running.
+		pending := enqueueApproval(rec, dryRunIntent(ctx, intent, opSpec))
+		return wrapForensicResult(map[string]interface{}{"status": "HUMAN_INTERVENTION_REQUIRED", "approval": pending}), nil, nil
+	}
+	if err := transitionIntent(args.ID, IntentValidated, "POLICY_ALLOW"); err != nil { return nil, nil, err }
 	return wrapForensicResult("ALLOW"), nil, nil
 }
 
-func human_approve_intent(ctx context.Context, req *mcp.CallToolRequest, args struct{ID string `json:"intent_id"`}) (*mcp.CallToolResult, any, error) {
-	stateMu.Lock(); for n := range engines { if engines[n].State == StateHumanReq { engines[n].State = StateRunning } }; stateMu.Unlock(); return wrapForensicResult("APPROVED"), nil, nil
-}
-
 func begin_atomic_operation(ctx context.Context, req *mcp.CallToolRequest, args AtomicOpArgs) (*mcp.CallToolResult, any, error) {
-	txMu.Lock(); defer txMu.Unlock(); tx := &VibeTransaction{ID: uuid.New().String(), IntentID: args.IntentID, StartTime: time.Now(), Status: "OPEN"}; transactions[args.IntentID], activeTransaction = tx, tx
-	return wrapForensicResult("TX_OPEN"), nil, nil
+	rec, err := lookupIntent(args.IntentID)
+	if err != nil { return nil, nil, fmt.Errorf("%v: transactions can only be opened for a submitted intent (%s)", err, args.IntentID) }
+	txMu.Lock(); defer txMu.Unlock()
+	tx := &VibeTransaction{ID: uuid.New().String(), IntentID: args.IntentID, StartTime: time.Now(), Status: TxOpen}
+	if err := transitionIntentLocked(rec, IntentExecuting, "TX_OPEN"); err != nil { return nil, nil, err }
+	tx.Agent = rec.Agent.AgentID
+	transactions[args.IntentID], activeTransaction = tx, tx
+	return wrapForensicResult(map[string]string{"status": "TX_OPEN", "transaction_id": tx.ID}), nil, nil
 }
 
 func commit_atomic_operation(ctx context.Context, req *mcp.CallToolRequest, args CommitAtomicOpArgs) (*mcp.CallToolResult, any, error) {
@@ -770,7 +824,21 @@ func commit_atomic_operation(ctx context.Context, req *mcp.CallToolRequest, args
 		return nil, nil, fmt.Errorf("MECHANICAL_AUDIT_FAILED: Security violation detected during transaction. Check security_gate.py output.")
 	}
 
-	txMu.Lock(); defer txMu.Unlock(); if args.ProofOfWork == "" { return nil, nil, fmt.Errorf("INVARIANT_VIOLATION: ProofOfWork Required") }
+	// Cross-Layer Echo: the proof must match state observed right now, not
+	// whatever the engines reported when the AI assembled it.
+	bctx, finish, err := budgetContext(ctx, args.IntentID)
+	if err != nil { updateBridgeActivity("KERNEL: READY"); return nil, nil, err }
+	observed := observeCommitState(bctx)
+	budgetErr := bctx.Err(); finish()
+	if budgetErr != nil { updateBridgeActivity("KERNEL: READY"); return nil, nil, budgetError(bctx) }
+	txMu.Lock(); defer txMu.Unlock()
+	if err := verifyProofOfWork(transactions[args.IntentID], args.ProofOfWork, observed); err != nil {
+		dispatchVibeEvent(LevelError, "proof_rejected", args.IntentID, "REVERIFY", map[string]interface{}{"error": err.Error()})
+		updateBridgeActivity("KERNEL: READY")
+		return nil, nil, err
+	}
+	if tx, ok := transactions[args.IntentID]; ok { commitTransactionUndo(tx.ID) }
+	if rec, ok := intents[args.IntentID]; ok { transitionIntentLocked(rec, IntentDone, "COMMITTED") }
 	delete(transactions, args.IntentID); activeTransaction = nil; 
 	
 	// Ghost Audit Protocol: Commit-on-Commit
@@ -784,16 +852,29 @@ func commit_atomic_operation(ctx context.Context, req *mcp.CallToolRequest, args
 }
 
 func dispatch_work_order(ctx context.Context, req *mcp.CallToolRequest, args WorkOrder) (*mcp.CallToolResult, any, error) {
-	// Determine directory based on intent/opcode
-	dir := "unity/inbox"
-	if args.Opcode == OpNode || args.Opcode == OpModifier { dir = "blender/inbox" }
+	g, err := checkGrant(args.Grant, []string{workOrderEngine(args)}, args.Opcode, []string{args.UUID}); if err != nil { return nil, nil, err }
+	if g.WorkOrderID != "" && g.WorkOrderID != args.ID { return nil, nil, fmt.Errorf("CAPABILITY_DENIED: Grant %s was delegated for work order %s", g.ID, g.WorkOrderID) }
+	if holdWorkOrder(args) { return wrapForensicResult("HELD_BY_PERIMETER"), nil, nil }
+	releaseHeldWorkOrders()
+	writeWorkOrder(args)
+	return wrapForensicResult("DISPATCHED"), nil, nil
+}
+
+// workOrderEngine names the engine whose inbox receives order.
+func workOrderEngine(order WorkOrder) string {
+	if order.Opcode == OpNode || order.Opcode == OpModifier { return "blender" }
+	return "unity"
+}
+
+func writeWorkOrder(order WorkOrder) error {
+	dir := workOrderEngine(order) + "/inbox"
 	
-	path := filepath.Join(QueueDir, dir, fmt.Sprintf("order_%s.json", args.ID))
-	data, _ := json.MarshalIndent(args, "", "  ")
-	os.WriteFile(path, data, 0644)
+	path := filepath.Join(QueueDir, dir, fmt.Sprintf("order_%s.json", order.ID))
+	data, _ := json.MarshalIndent(order, "", "  ")
+	if err := os.WriteFile(path, data, 0644); err != nil { return err }
 	
-	log.Printf("📩 Dispatching Work Order %s to %s", args.ID, dir)
-	return wrapForensicResult("DISPATCHED"), nil, nil
+	log.Printf("📩 Dispatching Work Order %s to %s", order.ID, dir)
+	return nil
 }
 
 // --- CLASS S: COORDINATED STRATEGY TOOLS ---
@@ -816,6 +897,7 @@ func audit_coordinated(ctx context.Context, req *mcp.CallToolRequest, args struc
 func dispatch_coordinated(ctx context.Context, req *mcp.CallToolRequest, args struct {
 	Envelope IntentEnvelope `json:"envelope"`
 	UUID     string         `json:"uuid"`
+	Grant    string         `json:"grant"`
 }) (*mcp.CallToolResult, any, error) {
 	id, _, err := submit_intent(ctx, nil, SubmitIntentArgs{Envelope: args.Envelope})
 	if err != nil { return nil, nil, err }
@@ -830,6 +912,7 @@ func dispatch_coordinated(ctx context.Context, req *mcp.CallToolRequest, args st
 		Opcode:      args.Envelope.Opcode,
 		UUID:        args.UUID,
 		Description: args.Envelope.Rationale,
+		Grant:       args.Grant,
 	}
 	return dispatch_work_order(ctx, nil, workOrder)
 }
@@ -882,7 +965,7 @@ func generate_sitrep(ctx context.Context, req *mcp.CallToolRequest, args struct{
 		Pulse:         "READY",
 		EngineStatus:  health,
 		AffordanceMap: affordances,
-		GlobalPerimeter: lockTable["GLOBAL_PERIMETER"] != nil,
+		GlobalPerimeter: perimeterLock() != nil,
 	}
 	return wrapForensicResult(sitrep), nil, nil
 }
@@ -900,12 +983,6 @@ func verify_mutation_integrity(ctx context.Context, req *mcp.CallToolRequest, ar
 	return wrapForensicResult(report), nil, nil
 }
 
-func propose_strategic_plan(ctx context.Context, req *mcp.CallToolRequest, args StrategicPlan) (*mcp.CallToolResult, any, error) {
-	log.Printf("📝 Strategic Plan Proposed: %s (%d steps)", args.Title, len(args.Steps))
-	// Forces Human-In-The-Loop review for multi-step tasks
-	return wrapForensicResult("PLAN_LOCKED_PENDING_GO"), nil, nil
-}
-
 func generate_forensic_snapshot(ctx context.Context, req *mcp.CallToolRequest, args ForensicSnapshotArgs) (*mcp.CallToolResult, any, error) {
 	updateBridgeActivity("KERNEL: SNAPSHOTTING_FORENSICS")
 	
@@ -958,14 +1035,8 @@ func processWorkResult(engine, path string) {
 	if res.Status == "FAILURE" {
 		phase = PhaseFailed
 		
-		// 1. Escalation Mutex Check
-		escalationMu.Lock()
-		if escalatedPipeline == "" {
-			escalatedPipeline = res.WorkOrderID // Use ID as simple pipeline ref
-			log.Printf("🛡️ Escalation Mutex: Pipeline %s has claimed the error-handling lock.", escalatedPipeline)
-		}
-		isLockedByMe := escalatedPipeline == res.WorkOrderID
-		escalationMu.Unlock()
+		// 1. Escalation Mutex Check (the order ID is the pipeline ref)
+		isLockedByMe := claimEscalation(engine, res.WorkOrderID)
 
 		// 2. Loop B (Foreman) Logic: Anti-Thrashing
 		sig := FailureSignature{
@@ -992,10 +1063,7 @@ func processWorkResult(engine, path string) {
 
 	// 3. Permission Mask Reduction (Simulated)
 	permissions := make(PermissionsMask)
-	if phase == PhaseHalted {
-		permissions["Flash"] = RolePermissions{CanExecute: true, CanRetry: false}
-		permissions["Pro"] = RolePermissions{CanExecute: false, CanRetry: true, MaxRetries: 1}
-	}
+	if phase == PhaseHalted { permissions = haltedPermissions() }
 
 	// Finalize WAL or update state machine
 	journalOperation(map[string]interface{}{
@@ -1008,12 +1076,18 @@ func processWorkResult(engine, path string) {
 		"failure_signature": sigHash,
 		"permissions_mask": permissions,
 	})
+	// A pipeline that recovered or gave up no longer holds or waits on the mutex
+	if phase == PhaseFinal || phase == PhaseTerminal { settleEscalation(res.WorkOrderID, string(phase)) }
+	advancePlan(res)
 	
 	os.Remove(path)
 }
 
 func abort_atomic_operation(ctx context.Context, req *mcp.CallToolRequest, args AtomicOpArgs) (*mcp.CallToolResult, any, error) {
-	txMu.Lock(); defer txMu.Unlock(); delete(transactions, args.IntentID); activeTransaction = nil; return wrapForensicResult("ABORTED"), nil, nil
+	txMu.Lock(); defer txMu.Unlock(); if tx, ok := transactions[args.IntentID]; ok { discardTransactionUndo(tx.ID) }
+	reason := args.Reason; if reason == "" { reason = "ABORTED" }
+	if rec, ok := intents[args.IntentID]; ok { transitionIntentLocked(rec, IntentRejected, reason) }
+	delete(transactions, args.IntentID); activeTransaction = nil; return wrapForensicResult("ABORTED"), nil, nil
 }
 
 func emit_diag_bundle(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
@@ -1021,17 +1095,37 @@ func emit_diag_bundle(ctx context.Context, req *mcp.CallToolRequest, args struct
 	return wrapForensicResult(p), nil, nil
 }
 
+// lock_object takes or drops an operator lock in the central lock table. The
+// lock mirror shows it in both editors, whichever target the caller named.
 func lock_object(ctx context.Context, req *mcp.CallToolRequest, args LockObjectArgs) (*mcp.CallToolResult, any, error) {
-	res, err := sendToEngine(args.Target, "object/lock", "POST", map[string]interface{}{"id": args.ObjectID, "locked": args.Locked}); if err != nil { return nil, nil, err }; return wrapForensicResult(res), nil, nil
+	g, err := checkGrant(args.Grant, []string{args.Target}, OpSystem, []string{args.ObjectID}); if err != nil { return nil, nil, err }
+	if !args.Locked {
+		if args.Lease == "" { return nil, nil, fmt.Errorf("LOCK_NOT_OWNER: releasing %s requires its lease", args.ObjectID) }
+		if err := releaseLock(ReleaseLockArgs{UUID: args.ObjectID, Owner: g.Holder, Lease: args.Lease}); err != nil { return nil, nil, err }
+		return wrapForensicResult("RELEASED"), nil, nil
+	}
+	l, err := acquireLock(args.ObjectID, LockAISpeculative, ActorSystem, g.Holder, args.Lease, nil, maxLockTTL); if err != nil { return nil, nil, err }
+	return wrapForensicResult(map[string]interface{}{"status": "LOCKED", "lease": l.Lease, "expires_at": l.ExpiresAt}), nil, nil
 }
 
 func get_metrics(ctx context.Context, req *mcp.CallToolRequest, args struct{Target string `json:"target"`}) (*mcp.CallToolResult, any, error) {
-	res, err := sendToEngine(args.Target, "metrics", "GET", nil); if err != nil { return nil, nil, err }; return wrapForensicResult(res), nil, nil
+	res, err := sendToEngine(ctx, args.Target, "metrics", "GET", nil); if err != nil { return nil, nil, err }; return wrapForensicResult(res), nil, nil
 }
 
 func sync_material(ctx context.Context, req *mcp.CallToolRequest, args SyncMaterialArgs) (*mcp.CallToolResult, any, error) {
-	if err := checkHumanLock(args.ObjectID); err != nil { return nil, nil, err }
-	data := map[string]interface{}{"id": args.ObjectID, "properties": args.Props}; journalOperation(map[string]interface{}{"type": "intent", "op": "sync_material", "id": args.ObjectID}); sendToEngine("unity", "material/update", "POST", data); sendToEngine("blender", "material/update", "POST", data)
+	g, err := checkGrant(args.Grant, []string{"unity", "blender"}, OpMaterial, []string{args.ObjectID}); if err != nil { return nil, nil, err }
+	if err := checkLocks(g.Holder, args.ObjectID); err != nil { return nil, nil, err }
+	data := map[string]interface{}{"id": args.ObjectID, "properties": args.Props}; class := classifyIntent(OpMaterial, "material/update", data)
+	if err := auditPayload(data); err != nil { return nil, nil, noteDeviation(g.Holder, err) }
+	if err := checkPerimeter(class, "sync_material"); err != nil { return nil, nil, err }
+	if err := chargeEntropy(g.Holder, class); err != nil { return nil, nil, err }
+	bctx, finish, err := budgetContext(ctx, g.IntentID); if err != nil { return nil, nil, err }
+	defer finish()
+	rec := newUndoRecord("sync_material", OpMaterial, g.IntentID, g.Holder)
+	if err := admitMutation(ctx, newConflictClaim(g.IntentID, g.Holder, class, []string{args.ObjectID}, predictedProperties(data), rec)); err != nil { return nil, nil, err }
+	journalOperation(map[string]interface{}{"type": "intent", "op": "sync_material", "id": args.ObjectID, "class": class, "agent": g.Holder})
+	sendMutation(bctx, rec, "unity", "material/update", data); sendMutation(bctx, rec, "blender", "material/update", data); sealUndoRecord(rec)
+	if bctx.Err() != nil { return nil, nil, budgetError(bctx) }
 	return wrapForensicResult("OK"), nil, nil
 }
 
@@ -1046,8 +1140,9 @@ func normalizeCoordinate(target string, value float64) float64 {
 }
 
 func sync_transform(ctx context.Context, req *mcp.CallToolRequest, args SyncTransformArgs) (*mcp.CallToolResult, any, error) {
-	if err := checkHumanLock(args.ObjectID); err != nil { return nil, nil, err }
-	for _, v := range append(append(args.Position, args.Rotation...), args.Scale...) { if math.IsNaN(v) || math.IsInf(v, 0) { return nil, nil, fmt.Errorf("NUMERICAL_INSTABILITY") } }
+	g, err := checkGrant(args.Grant, []string{"unity", "blender"}, OpTransform, []string{args.ObjectID}); if err != nil { return nil, nil, err }
+	if err := checkLocks(g.Holder, args.ObjectID); err != nil { return nil, nil, err }
+	if err := auditPayload([][]float64{args.Position, args.Rotation, args.Scale}); err != nil { return nil, nil, noteDeviation(g.Holder, err) }
 	
 	// Apply Unit Normalization
 	normalizedPos := make([]float64, 3)
@@ -1055,39 +1150,65 @@ func sync_transform(ctx context.Context, req *mcp.CallToolRequest, args SyncTran
 	
 	data := map[string]interface{}{"id": args.ObjectID, "transform": map[string]interface{}{"pos": normalizedPos, "rot": args.Rotation, "sca": args.Scale}}
 	
-	// Mechanical Floor: Buffer the intent for coalescing
-	bufferSpeculativeIntent(args.ObjectID, "sync_transform", data)
-	
-	// Speculative Execution: Background send to engines
-	go sendToEngine("unity", "transform/set", "POST", data)
-	go sendToEngine("blender", "transform/set", "POST", data)
+	// Mechanical Floor: Buffer the intent for coalescing. The batch is sent,
+	// verified and finalized by flushIntentBuffer once its window closes.
+	class := classifyIntent(OpTransform, "transform/set", data)
+	if err := checkPerimeter(class, "sync_transform"); err != nil { return nil, nil, err }
+	if err := chargeEntropy(g.Holder, class); err != nil { return nil, nil, err }
+	bctx, finish, err := budgetContext(ctx, g.IntentID); if err != nil { return nil, nil, err } // A batched send is charged by budgetBatch
+	defer finish()
+	// Batched mutations stake their claim now and are bound to the batch's
+	// undo record when it is sent.
+	var rec *UndoRecord
+	if classProfiles[class].BatchWindow == 0 { rec = newUndoRecord("sync_transform", OpTransform, g.IntentID, g.Holder) }
+	claim := newConflictClaim(g.IntentID, g.Holder, class, []string{args.ObjectID}, predictedProperties(data), rec)
+	if err := admitMutation(ctx, claim); err != nil { return nil, nil, err }
+	if !bufferSpeculativeIntent(args.ObjectID, "sync_transform", "transform/set", data, class, claim) {
+		for _, t := range []string{"unity", "blender"} {
+			if _, err := sendMutation(bctx, rec, t, "transform/set", data); err != nil {
+				if bctx.Err() != nil { return nil, nil, budgetError(bctx) }
+				return nil, nil, err
+			}
+		}
+		sealUndoRecord(rec)
+	}
 	
 	journalOperation(map[string]interface{}{
 		"type": "intent", 
 		"op": "sync_transform", 
 		"id": args.ObjectID, 
 		"phase": PhaseProvisional,
-		"class": ClassCosmetic,
+		"class": class,
+		"agent": g.Holder,
 	})
 	
 	return wrapForensicResult("PROVISIONAL_OK"), nil, nil
 }
 
 func sync_camera(ctx context.Context, req *mcp.CallToolRequest, args SyncCameraArgs) (*mcp.CallToolResult, any, error) {
-	t := "unity"; if args.Source == "unity" { t = "blender" }; res, err := sendToEngine(args.Source, "camera/get", "GET", nil); if err != nil { return nil, nil, err }; sendToEngine(t, "camera/set", "POST", res)
+	t := "unity"; if args.Source == "unity" { t = "blender" }
+	if _, err := checkGrant(args.Grant, []string{t}, OpSystem, nil); err != nil { return nil, nil, err }
+	res, err := sendToEngine(ctx, args.Source, "camera/get", "GET", nil); if err != nil { return nil, nil, err }; sendToEngine(ctx, t, "camera/set", "POST", res)
 	return wrapForensicResult("OK"), nil, nil
 }
 
 func sync_selection(ctx context.Context, req *mcp.CallToolRequest, args SyncSelectionArgs) (*mcp.CallToolResult, any, error) {
-	t := "unity"; if args.Source == "unity" { t = "blender" }; sendToEngine(t, "selection/set", "POST", map[string]interface{}{"ids": args.IDs})
+	t := "unity"; if args.Source == "unity" { t = "blender" }
+	if _, err := checkGrant(args.Grant, []string{t}, OpSystem, args.IDs); err != nil { return nil, nil, err }
+	sendToEngine(ctx, t, "selection/set", "POST", map[string]interface{}{"ids": args.IDs})
 	return wrapForensicResult("OK"), nil, nil
 }
 
 func sync_asset_atomic(ctx context.Context, req *mcp.CallToolRequest, args SyncAssetAtomicArgs) (*mcp.CallToolResult, any, error) {
+	g, err := checkGrant(args.Grant, []string{"unity", "blender"}, OpIO, []string{args.AssetPath}); if err != nil { return nil, nil, err }
+	if err := checkPerimeter(classifyIntent(OpIO, "import", nil), "sync_asset_atomic"); err != nil { return nil, nil, err }
+	bctx, finish, err := budgetContext(ctx, g.IntentID); if err != nil { return nil, nil, err }
+	defer finish()
 	updateBridgeActivity("KERNEL: SYNCING_ASSET_ATOMIC")
-	pre, _ := sendToEngine("blender", "preflight/run", "POST", map[string]interface{}{"path": args.AssetPath}); ex, _ := sendToEngine("blender", "export", "POST", map[string]interface{}{"path": args.AssetPath}); sendToEngine("unity", "import", "POST", map[string]interface{}{"path": args.AssetPath, "meta": ex["meta"], "mode": "sandbox"}); val, _ := sendToEngine("unity", "validate", "POST", map[string]interface{}{"path": args.AssetPath})
-	if fmt.Sprintf("%v", pre["hash"]) != fmt.Sprintf("%v", val["hash"]) { sendToEngine("unity", "rollback", "POST", map[string]interface{}{"path": args.AssetPath}); stateMu.Lock(); for n := range engines { engines[n].State = StateDesync }; stateMu.Unlock(); updateBridgeActivity("KERNEL: DESYNC"); return nil, nil, fmt.Errorf("HASH_MISMATCH") }
-	sendToEngine("unity", "commit", "POST", map[string]interface{}{"path": args.AssetPath})
+	pre, _ := sendToEngine(bctx, "blender", "preflight/run", "POST", map[string]interface{}{"path": args.AssetPath}); ex, _ := sendToEngine(bctx, "blender", "export", "POST", map[string]interface{}{"path": args.AssetPath}); sendToEngine(bctx, "unity", "import", "POST", map[string]interface{}{"path": args.AssetPath, "meta": ex["meta"], "mode": "sandbox"}); val, _ := sendToEngine(bctx, "unity", "validate", "POST", map[string]interface{}{"path": args.AssetPath})
+	if bctx.Err() != nil { sendToEngine(context.Background(), "unity", "rollback", "POST", map[string]interface{}{"path": args.AssetPath}); updateBridgeActivity("KERNEL: READY"); return nil, nil, budgetError(bctx) }
+	if fmt.Sprintf("%v", pre["hash"]) != fmt.Sprintf("%v", val["hash"]) { sendToEngine(ctx, "unity", "rollback", "POST", map[string]interface{}{"path": args.AssetPath}); stateMu.Lock(); for n := range engines { engines[n].State = StateDesync }; stateMu.Unlock(); updateBridgeActivity("KERNEL: DESYNC"); return nil, nil, fmt.Errorf("HASH_MISMATCH") }
+	sendToEngine(ctx, "unity", "commit", "POST", map[string]interface{}{"path": args.AssetPath})
 	updateBridgeActivity("KERNEL: READY")
 	return wrapForensicResult("SYNCED"), nil, nil
 }
@@ -1097,8 +1218,9 @@ func get_operation_journal(ctx context.Context, req *mcp.CallToolRequest, args s
 	return wrapForensicResult(strings.Join(out, "\n")), nil, nil
 }
 
-func control_playback(ctx context.Context, req *mcp.CallToolRequest, args struct { Action string; Time float64 }) (*mcp.CallToolResult, any, error) {
-	d := map[string]interface{}{"action": args.Action, "time": args.Time}; sendToEngine("unity", "playback/control", "POST", d); sendToEngine("blender", "playback/control", "POST", d)
+func control_playback(ctx context.Context, req *mcp.CallToolRequest, args struct { Action string; Time float64; Grant string `json:"grant"` }) (*mcp.CallToolResult, any, error) {
+	g, err := checkGrant(args.Grant, []string{"unity", "blender"}, OpSystem, nil); if err != nil { return nil, nil, err }
+	d := map[string]interface{}{"action": args.Action, "time": args.Time}; if err := auditPayload(d); err != nil { return nil, nil, noteDeviation(g.Holder, err) }; sendToEngine(ctx, "unity", "playback/control", "POST", d); sendToEngine(ctx, "blender", "playback/control", "POST", d)
 	return wrapForensicResult("OK"), nil, nil
 }
 
@@ -1108,7 +1230,13 @@ func global_id_map_resolve(ctx context.Context, req *mcp.CallToolRequest, args M
 
 func vibe_multiplex(ctx context.Context, req *mcp.CallToolRequest, args MultiplexCallArgs) (*mcp.CallToolResult, any, error) {
 	allowed, ok := drivers[args.SensorID]; if !ok { return nil, nil, fmt.Errorf("DRIVER_UNREGISTERED") }; isOk := false; for _, ep := range allowed { if ep == args.Endpoint { isOk = true; break } }; if !isOk { return nil, nil, fmt.Errorf("DENIED") }
-	res, err := sendToEngine(args.Target, args.Endpoint, "POST", args.Payload); if err != nil { return nil, nil, err }; return wrapForensicResult(res), nil, nil
+	// Every multiplexed call is a POST, so it is gated like any other mutation
+	uuids := dryRunScope(IntentEnvelope{}, args.Payload)
+	if ids, ok := args.Payload["ids"].([]interface{}); ok { for _, id := range ids { if s, ok := id.(string); ok { uuids = append(uuids, s) } } }
+	g, err := checkGrant(args.Grant, []string{args.Target}, OpSystem, uuids); if err != nil { return nil, nil, err }
+	if err := auditPayload(args.Payload); err != nil { return nil, nil, noteDeviation(g.Holder, err) }
+	if err := checkPerimeter(classifyIntent(0, args.Endpoint, args.Payload), args.Endpoint); err != nil { return nil, nil, err }
+	res, err := sendToEngine(ctx, args.Target, args.Endpoint, "POST", args.Payload); if err != nil { return nil, nil, err }; return wrapForensicResult(res), nil, nil
 }
 
 func set_engine_state(ctx context.Context, req *mcp.CallToolRequest, args SetEngineStateArgs) (*mcp.CallToolResult, any, error) {
@@ -1138,52 +1266,21 @@ func invoke_specialist(ctx context.Context, req *mcp.CallToolRequest, args Invok
 	return wrapForensicResult(p), nil, nil
 }
 
-func apply_lock(ctx context.Context, req *mcp.CallToolRequest, args ApplyLockArgs) (*mcp.CallToolResult, any, error) {
-	lockMu.Lock()
-	defer lockMu.Unlock()
-	lockTable[args.UUID] = &VibeLock{
-		UUID:      args.UUID,
-		Type:      args.LockType,
-		Timestamp: time.Now(),
-		ExpiresAt: time.Now().Add(30 * time.Second),
-	}
-	updateBridgeActivity(fmt.Sprintf("KERNEL: LOCK_%s", args.UUID))
-	return wrapForensicResult("LOCKED"), nil, nil
-}
-
-func release_lock(ctx context.Context, req *mcp.CallToolRequest, args ReleaseLockArgs) (*mcp.CallToolResult, any, error) {
-	lockMu.Lock()
-	defer lockMu.Unlock()
-	delete(lockTable, args.UUID)
-	updateBridgeActivity("KERNEL: READY")
-	return wrapForensicResult("RELEASED"), nil, nil
-}
-
-func perimeter_lock(ctx context.Context, req *mcp.CallToolRequest, args struct{Locked bool `json:"locked"`}) (*mcp.CallToolResult, any, error) {
-	lockMu.Lock()
-	defer lockMu.Unlock()
+// perimeter_lock takes, renews or releases the creation perimeter. Taking or
+// renewing it is a human decision and must be signed by a Human approver.
+func perimeter_lock(ctx context.Context, req *mcp.CallToolRequest, args PerimeterLockArgs) (*mcp.CallToolResult, any, error) {
 	if args.Locked {
-		lockTable["GLOBAL_PERIMETER"] = &VibeLock{
-			UUID: "GLOBAL_PERIMETER",
-			Type: LockPerimeter,
-			Actor: ActorSystem,
-			Timestamp: time.Now(),
-			ExpiresAt: time.Now().Add(60 * time.Second),
-		}
-		updateBridgeActivity("KERNEL: PERIMETER_LOCKED")
-	} else {
-		delete(lockTable, "GLOBAL_PERIMETER")
+		if err := verifyHuman(args.Approver, args.Signature, "perimeter_lock", args); err != nil { return nil, nil, err }
+	}
+	l, err := setPerimeter(args); if err != nil { return nil, nil, err }
+	kickLockMirror()
+	if l == nil {
+		releaseHeldWorkOrders()
 		updateBridgeActivity("KERNEL: READY")
+		return wrapForensicResult("RELEASED"), nil, nil
 	}
-	return wrapForensicResult("OK"), nil, nil
-}
-
-func ingest_forensic_logs(ctx context.Context, req *mcp.CallToolRequest, args struct{LogHash string `json:"log_hash"`}) (*mcp.CallToolResult, any, error) {
-	logIngestMu.Lock()
-	lastLogIngestedHash = args.LogHash
-	logIngestMu.Unlock()
-	log.Printf("📜 Log-Driven Governance: AI has ingested history at hash %s", args.LogHash)
-	return wrapForensicResult("INGESTED"), nil, nil
+	updateBridgeActivity("KERNEL: PERIMETER_LOCKED")
+	return wrapForensicResult(map[string]interface{}{"status": "LOCKED", "lease": l.Lease, "expires_at": l.ExpiresAt}), nil, nil
 }
 
 func reset_terminal_state(ctx context.Context, req *mcp.CallToolRequest, args struct{Signature string `json:"failure_signature"`}) (*mcp.CallToolResult, any, error) {
@@ -1211,30 +1308,84 @@ func get_bridge_heartbeat(ctx context.Context, req *mcp.CallToolRequest, args st
 }
 
 func get_bridge_handshake_state(ctx context.Context, req *mcp.CallToolRequest, args struct{ AssetID string `json:"asset_id"` }) (*mcp.CallToolResult, any, error) {
-	u, _ := sendToEngine("unity", "state/get", "GET", nil); b, _ := sendToEngine("blender", "state/get", "GET", nil); m := fmt.Sprintf("%v", u["hash"]) == fmt.Sprintf("%v", b["hash"])
+	u, _ := sendToEngine(ctx, "unity", "state/get", "GET", nil); b, _ := sendToEngine(ctx, "blender", "state/get", "GET", nil); m := fmt.Sprintf("%v", u["hash"]) == fmt.Sprintf("%v", b["hash"])
 	res := BridgeHandshakeState{AssetID: args.AssetID, BlenderExportHash: fmt.Sprintf("%v", b["hash"]), UnityImportHash: fmt.Sprintf("%v", u["hash"]), HashMatch: m, LastVerified: time.Now().Format(time.RFC3339)}
 	return wrapForensicResult(res), nil, nil
 }
 
 func get_bridge_wal_state(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
-	res := BridgeWalState{WalHead: monotonicID, WalHash: lastWalHash, LastCommittedOp: "UNKNOWN", PendingOps: 0, RollbackAvailable: true, Reversible: true}
+	walMu.Lock(); stateHead := lastStateHash; walMu.Unlock()
+	res := BridgeWalState{WalHead: monotonicID, WalHash: lastWalHash, StateHead: stateHead, LastCommittedOp: "UNKNOWN", PendingOps: 0, RollbackAvailable: true, Reversible: true}
 	return wrapForensicResult(res), nil, nil
 }
 
-func get_bridge_commit_requirements(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
-	u, _ := sendToEngine("unity", "state/get", "GET", nil); b, _ := sendToEngine("blender", "state/get", "GET", nil); h := map[string]string{"wal": lastWalHash, "blender": fmt.Sprintf("%v", b["hash"]), "unity": fmt.Sprintf("%v", u["hash"])}
-	res := BridgeCommitRequirements{RequiredHashes: h, RationaleRequired: true, CommitAllowed: h["blender"] == h["unity"]}
+// get_bridge_commit_requirements observes the three layers and, for an intent
+// with an open transaction, issues the binding its ProofOfWork must carry.
+func get_bridge_commit_requirements(ctx context.Context, req *mcp.CallToolRequest, args CommitRequirementsArgs) (*mcp.CallToolResult, any, error) {
+	obs := observeCommitState(ctx)
+	h := map[string]string{"wal": lastWalHash, "blender": obs.BlenderExportHash, "unity": obs.UnityImportHash, "bridge": obs.BridgeVerificationHash}
+	res := BridgeCommitRequirements{RequiredHashes: h, RationaleRequired: true, CommitAllowed: h["blender"] != "" && h["blender"] == h["unity"] && h["unity"] == h["bridge"]}
+	if args.IntentID != "" {
+		txMu.Lock(); tx := transactions[args.IntentID]; txMu.Unlock()
+		if tx == nil { return nil, nil, fmt.Errorf("TX_NOT_FOUND: No open transaction for intent %s", args.IntentID) }
+		res.TransactionID, res.Binding = tx.ID, proofBinding(tx.ID, obs.BlenderExportHash, obs.UnityImportHash, obs.BridgeVerificationHash)
+	}
 	return wrapForensicResult(res), nil, nil
 }
 
 func execute_governed_mutation(ctx context.Context, req *mcp.CallToolRequest, args MutateArgs) (*mcp.CallToolResult, any, error) {
-	if err := auditPayload(args.OpSpec); err != nil { return nil, nil, err }
 	t, _ := args.OpSpec["target"].(string); e, _ := args.OpSpec["endpoint"].(string)
 	targetHash := fmt.Sprintf("%x", sha256.Sum256([]byte(fmt.Sprintf("%v", args.OpSpec["payload"]))))
-	if err := checkInvariants(args.IdempotencyKey, targetHash); err != nil { return nil, nil, err }
-	res, err := sendToEngine(t, e, "POST", args.OpSpec["payload"]); if err != nil { return nil, nil, err }
-	time.Sleep(200 * time.Millisecond); v, _ := sendToEngine(t, "state/get", "GET", nil)
-	r := map[string]interface{}{"engine_response": res, "verified_hash": "FAIL"}; if v != nil { r["verified_hash"] = v["hash"] }
+
+	// Classification is mechanical; the intent's own opcode and policy rule
+	// decide whether this class of operation is admissible at all.
+	intent, err := lookupIntent(args.IntentID)
+	if err != nil { return nil, nil, fmt.Errorf("%v: execute_governed_mutation needs its submitted intent (%s)", err, args.IntentID) }
+	txMu.Lock(); env, status, reason := intent.Envelope, intent.Status, intent.Reason; txMu.Unlock()
+	if status != IntentValidated && status != IntentApproved && status != IntentExecuting {
+		return nil, nil, fmt.Errorf("INTENT_%s: execute_governed_mutation only runs admitted intents %s", status, reason)
+	}
+	if env.Opcode == 0 { return nil, nil, fmt.Errorf("CAPABILITY_DENIED: intent %s names no opcode", args.IntentID) }
+	payload, _ := splitOpSpec(args.OpSpec)
+	g, err := checkGrant(args.Grant, []string{t}, env.Opcode, dryRunScope(env, payload)); if err != nil { return nil, nil, err }
+	if g.IntentID != args.IntentID { return nil, nil, fmt.Errorf("CAPABILITY_DENIED: Grant %s was minted for intent %q, not %s", g.ID, g.IntentID, args.IntentID) }
+	if err := auditPayload(args.OpSpec); err != nil { return nil, nil, noteDeviation(g.Holder, err) }
+	class := classifyOpSpec(env.Opcode, args.OpSpec)
+	if d := evaluatePolicy(env, class); !d.Allowed { return nil, nil, fmt.Errorf("%s", d.Reason) }
+	if err := checkPerimeter(class, e); err != nil { return nil, nil, err }
+	if err := checkOpSpecHierarchy(ctx, args.OpSpec); err != nil { return nil, nil, err }
+	if err := checkInvariants(g.Holder, class, args.IdempotencyKey, targetHash); err != nil { return nil, nil, noteDeviation(g.Holder, err) }
+	depths, err := mutationIdentities(t, payload); if err != nil { return nil, nil, err }
+	profile := classProfiles[class]
+
+	rec := newUndoRecord("execute_governed_mutation", env.Opcode, args.IntentID, g.Holder)
+	if profile.SnapshotRequired {
+		ref, err := safetySnapshot("Pre-Destructive: " + args.IntentID)
+		if err != nil { return nil, nil, err }
+		undoMu.Lock(); rec.SnapshotRef = ref; undoMu.Unlock()
+	}
+	claim := newConflictClaim(args.IntentID, g.Holder, class, dryRunScope(env, payload), predictedProperties(payload), rec)
+	if err := admitMutation(ctx, claim); err != nil { return nil, nil, err }
+	bctx, finish, err := budgetContext(ctx, args.IntentID); if err != nil { return nil, nil, err }
+	defer finish()
+	res, err := sendMutation(bctx, rec, t, e, args.OpSpec["payload"]); if err != nil { return nil, nil, err }
+	sealUndoRecord(rec)
+
+	r := map[string]interface{}{"engine_response": res, "intent_class": class, "verification": profile.Verification}
+	if profile.Verification == "deferred" {
+		// Fast path: answer now, verify in the background (Deferred Finality)
+		go verifyDeferred(t, rec.ID, args.IntentID, depths)
+		r["verified_hash"] = "PROVISIONAL"
+		return wrapForensicResult(r), nil, nil
+	}
+	time.Sleep(200 * time.Millisecond); v, _ := sendToEngine(bctx, t, "state/get", "GET", nil)
+	r["verified_hash"] = "FAIL"
+	if v != nil && v["hash"] != nil {
+		h, err := verifyAtDepth(v, depths)
+		if err != nil { quarantineTransaction(args.IntentID, t, err); return nil, nil, err }
+		r["verified_hash"] = h
+	}
+	if bctx.Err() != nil { r["verified_hash"] = "BUDGET_EXHAUSTED" }
 	return wrapForensicResult(r), nil, nil
 }
 
@@ -1267,10 +1418,54 @@ func execCommand(command string) (string, error) {
 	return out.String(), err
 }
 
+// auditOnlyJournalTypes are WAL entries that record what the orchestrator
+// observed or decided without changing either scene. They extend the hash
+// chain but do not move the state head that intents are pinned to.
+var auditOnlyJournalTypes = map[string]bool{
+	"engine_call":         true,
+	"policy_decision":     true,
+	"approval_decision":   true,
+	"verification":        true,
+	"grant_issued":        true,
+	"plan_decision":       true,
+	"entropy_grant":       true,
+	"protocol_deviation":  true,
+	"drift_remediation":   true,
+	"fact_recorded":       true,
+	"fact_invalidated":    true,
+	"lock_forced_release": true,
+	"conflict":            true,
+	"human_activity":      true,
+	"delete_closure":      true,
+	"escalation":          true,
+	"deadlock":            true,
+	"deadlock_broken":     true,
+}
+
 func journalOperation(op map[string]interface{}) {
-	walMu.Lock(); defer walMu.Unlock(); if activeTransaction != nil { op["tid"] = activeTransaction.ID }
+	walMu.Lock(); defer walMu.Unlock()
+	// Callers that know the verified agent name it; only anonymous entries fall back to the open transaction's agent.
+	if activeTransaction != nil {
+		if _, ok := op["tid"]; !ok { op["tid"] = activeTransaction.ID }
+		if _, ok := op["agent"]; !ok && activeTransaction.Agent != "" { op["agent"] = activeTransaction.Agent }
+	}
 	op["prev_hash"] = lastWalHash; data, _ := json.Marshal(op); h := sha256.New(); h.Write(data); lastWalHash = hex.EncodeToString(h.Sum(nil)); op["hash"] = lastWalHash
-	final, _ := json.Marshal(op); if info, err := os.Stat(WalFile); err == nil && info.Size() > MaxWalSize { os.Rename(WalFile, WalFile+".old") }; f, _ := os.OpenFile(WalFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); defer f.Close(); f.Write(final); f.Write([]byte("\n"))
+	t, _ := op["type"].(string); if !auditOnlyJournalTypes[t] { lastStateHash = lastWalHash }
+	final, _ := json.Marshal(op); if info, err := os.Stat(WalFile); err == nil && info.Size() > MaxWalSize { os.Rename(WalFile, WalFile+".old"); walIdx.rotate() }; f, _ := os.OpenFile(WalFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); defer f.Close(); f.Write(final); f.Write([]byte("\n"))
+	var about walSubjects; json.Unmarshal(final, &about); walIdx.add(lastWalHash, t, about.list())
+}
+
+// rollbackTransactionLocked abandons an open transaction: its undo records are
+// dropped, the owning intent is rejected and both engines are told to roll
+// back. txMu must be held.
+func rollbackTransactionLocked(id string, tx *VibeTransaction, reason string) {
+	delete(transactions, id)
+	discardTransactionUndo(tx.ID)
+	if rec, ok := intents[id]; ok { transitionIntentLocked(rec, IntentRejected, reason) }
+	if activeTransaction == tx { activeTransaction = nil }
+	for name := range engines {
+		go sendToEngine(context.Background(), name, "rollback", "POST", map[string]interface{}{"reason": reason})
+	}
 }
 
 func startTransactionGC() {
@@ -1289,21 +1484,7 @@ func startTransactionGC() {
 
 				log.Printf("🚨 VibeSync: Transaction Timeout (%s) - Auto-Rolling Back", tx.ID)
 
-				delete(transactions, id)
-
-				if activeTransaction == tx {
-
-					activeTransaction = nil
-
-				}
-
-				// Force engine rollback
-
-				for name := range engines {
-
-					go sendToEngine(name, "rollback", "POST", map[string]interface{}{"reason": "TX_TIMEOUT"})
-
-				}
+				rollbackTransactionLocked(id, tx, "TX_TIMEOUT")
 
 			}
 
@@ -1339,22 +1520,60 @@ func main() {
 
 	mcp.AddTool(server, &mcp.Tool{Name: "human_approve_intent", Description: "ISA 5b"}, human_approve_intent)
 
+	mcp.AddTool(server, &mcp.Tool{Name: "human_reject_intent", Description: "ISA 5b: Reject"}, human_reject_intent)
+
+	mcp.AddTool(server, &mcp.Tool{Name: "list_pending_approvals", Description: "ISA 5b: Approval Queue"}, list_pending_approvals)
+
+	mcp.AddTool(server, &mcp.Tool{Name: "get_intent_status", Description: "ISA 5c: Intent Lifecycle"}, get_intent_status)
+
+	mcp.AddTool(server, &mcp.Tool{Name: "list_agents", Description: "ISA 5d: Agent Key Registry"}, list_agents)
+
+	mcp.AddTool(server, &mcp.Tool{Name: "mint_capability_grant", Description: "ISA 5e: Capability Grant"}, mint_capability_grant)
+
+	mcp.AddTool(server, &mcp.Tool{Name: "delegate_capability_grant", Description: "ISA 5f: Grant Delegation"}, delegate_capability_grant)
+
+	mcp.AddTool(server, &mcp.Tool{Name: "get_entropy_budgets", Description: "ISA 5g: Entropy Budgets"}, get_entropy_budgets)
+
+	mcp.AddTool(server, &mcp.Tool{Name: "human_grant_entropy", Description: "ISA 5g: Entropy Top-Up"}, human_grant_entropy)
+
+	mcp.AddTool(server, &mcp.Tool{Name: "get_drift_status", Description: "ISA 5h: Drift Budget"}, get_drift_status)
+
+	mcp.AddTool(server, &mcp.Tool{Name: "human_remediate_drift", Description: "ISA 5h: Drift Remediation"}, human_remediate_drift)
+
+	mcp.AddTool(server, &mcp.Tool{Name: "record_fact", Description: "ISA 5i: Fact Ledger"}, record_fact)
+
+	mcp.AddTool(server, &mcp.Tool{Name: "observe_fact", Description: "ISA 5i: Fact Observation"}, observe_fact)
+
+	mcp.AddTool(server, &mcp.Tool{Name: "get_facts", Description: "ISA 5i: Fact Query"}, get_facts)
+
 	mcp.AddTool(server, &mcp.Tool{Name: "begin_atomic_operation", Description: "ISA 6"}, begin_atomic_operation)
 
 	mcp.AddTool(server, &mcp.Tool{Name: "commit_atomic_operation", Description: "ISA 7"}, commit_atomic_operation)
 
 	mcp.AddTool(server, &mcp.Tool{Name: "abort_atomic_operation", Description: "ISA 8"}, abort_atomic_operation)
 
+	mcp.AddTool(server, &mcp.Tool{Name: "undo", Description: "ISA 8b: Cross-Engine Undo"}, undo)
+
+	mcp.AddTool(server, &mcp.Tool{Name: "redo", Description: "ISA 8c: Cross-Engine Redo"}, redo)
+
 	mcp.AddTool(server, &mcp.Tool{Name: "emit_diag_bundle", Description: "ISA 10"}, emit_diag_bundle)
 
 	mcp.AddTool(server, &mcp.Tool{Name: "lock_object", Description: "Locking"}, lock_object)
 
 	mcp.AddTool(server, &mcp.Tool{Name: "apply_lock", Description: "Human Lock"}, apply_lock)
 
-	mcp.AddTool(server, &mcp.Tool{Name: "perimeter_lock", Description: "Creation Perimeter Lock"}, perimeter_lock)
+	mcp.AddTool(server, &mcp.Tool{Name: "perimeter_lock", Description: "Creation Perimeter Lock (a renewable lease; renew or release with its lease)"}, perimeter_lock)
 
 	mcp.AddTool(server, &mcp.Tool{Name: "release_lock", Description: "Release Lock"}, release_lock)
 
+	mcp.AddTool(server, &mcp.Tool{Name: "renew_lock", Description: "Renew Lock Lease"}, renew_lock)
+
+	mcp.AddTool(server, &mcp.Tool{Name: "inspect_locks", Description: "Lock Inspection"}, inspect_locks)
+	mcp.AddTool(server, &mcp.Tool{Name: "get_hierarchy", Description: "Mirrored Hierarchy (DAG)"}, get_hierarchy)
+	mcp.AddTool(server, &mcp.Tool{Name: "delete_objects", Description: "Closure-Aware Destructive Delete"}, delete_objects)
+	mcp.AddTool(server, &mcp.Tool{Name: "inspect_wait_graph", Description: "Wait-For Graph"}, inspect_wait_graph)
+	mcp.AddTool(server, &mcp.Tool{Name: "break_deadlock", Description: "Human Deadlock Break"}, break_deadlock)
+
 	mcp.AddTool(server, &mcp.Tool{Name: "get_metrics", Description: "Metrics"}, get_metrics)
 
 	mcp.AddTool(server, &mcp.Tool{Name: "sync_transform", Description: "ISA 21*"}, sync_transform)
@@ -1385,6 +1604,8 @@ func main() {
 
 	mcp.AddTool(server, &mcp.Tool{Name: "reconstruct_state", Description: "Forensic"}, reconstruct_state)
 
+	mcp.AddTool(server, &mcp.Tool{Name: "get_forensic_log_window", Description: "Log-as-State: Window"}, get_forensic_log_window)
+
 	mcp.AddTool(server, &mcp.Tool{Name: "ingest_forensic_logs", Description: "Log-as-State"}, ingest_forensic_logs)
 
 	mcp.AddTool(server, &mcp.Tool{Name: "invoke_specialist", Description: "Delegate"}, invoke_specialist)
@@ -1411,6 +1632,13 @@ func main() {
 	mcp.AddTool(server, &mcp.Tool{Name: "generate_sitrep", Description: "Reality: Affordance Map"}, generate_sitrep)
 	mcp.AddTool(server, &mcp.Tool{Name: "verify_mutation_integrity", Description: "Reality: Integrity Stress Test"}, verify_mutation_integrity)
 	mcp.AddTool(server, &mcp.Tool{Name: "propose_strategic_plan", Description: "Reality: Multi-Step Architect"}, propose_strategic_plan)
+	mcp.AddTool(server, &mcp.Tool{Name: "human_approve_plan", Description: "Reality: Plan Approval"}, human_approve_plan)
+	mcp.AddTool(server, &mcp.Tool{Name: "human_reject_plan", Description: "Reality: Plan Rejection"}, human_reject_plan)
+	mcp.AddTool(server, &mcp.Tool{Name: "run_strategic_plan", Description: "Reality: Plan Execution"}, run_strategic_plan)
+	mcp.AddTool(server, &mcp.Tool{Name: "pause_strategic_plan", Description: "Reality: Plan Pause"}, pause_strategic_plan)
+	mcp.AddTool(server, &mcp.Tool{Name: "resume_strategic_plan", Description: "Reality: Plan Resume"}, resume_strategic_plan)
+	mcp.AddTool(server, &mcp.Tool{Name: "abort_strategic_plan", Description: "Reality: Plan Abort & Rollback"}, abort_strategic_plan)
+	mcp.AddTool(server, &mcp.Tool{Name: "get_plan_status", Description: "Reality: Plan Progress"}, get_plan_status)
 		mcp.AddTool(server, &mcp.Tool{Name: "generate_forensic_snapshot", Description: "Reality: Forensic Black Box"}, generate_forensic_snapshot)
 	
 		mcp.AddTool(server, &mcp.Tool{Name: "reset_terminal_state", Description: "Authority: Clear Terminal Lock"}, reset_terminal_state)
@@ -1448,16 +1676,14 @@ func main() {
 				hash := lastWalHash
 				walMu.Unlock()
 		
-				entropyMu.Lock()
-				eUsed := sessionEntropy
-				entropyMu.Unlock()
+				eUsed, eLimit := entropyTotals()
 		
 				status := map[string]interface{}{
 					"kernel":   "READY",
 					"unity":    map[string]interface{}{"state": uState, "port": unityPort, "token": token},
-					"blender":  map[string]interface{}{"state": bState, "port": BlenderPort},
+					"blender":  map[string]interface{}{"state": bState, "port": blenderPort},
 					"wal_hash": hash,
-					"entropy":  fmt.Sprintf("%d/%d", eUsed, maxEntropy),
+					"entropy":  fmt.Sprintf("%d/%d", eUsed, eLimit),
 					"uptime":   time.Since(startTime).String(),
 				}
 		
@@ -1474,6 +1700,8 @@ func main() {
 				log.Printf("🛡️ VibeSync: Manual Recovery Triggered - All engines reset to STOPPED")
 				w.Write([]byte("RECOVERY_INITIATED"))
 			})
+
+			mux.HandleFunc("/editor/activity", handleEditorActivity)
 					mux.HandleFunc("/activity", func(w http.ResponseWriter, r *http.Request) {
 	
 				data, _ := os.ReadFile(ActivityFile)
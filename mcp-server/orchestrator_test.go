@@ -16,20 +16,144 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
 	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// TestMain runs the suite in a scratch working directory so it never reads or
+// appends to the real .vibesync state, WAL or bridge activity file, and drops
+// whatever init loaded from them.
+func TestMain(m *testing.M) {
+	wd, _ := os.Getwd()
+	dir, err := os.MkdirTemp("", "vibesync-test-")
+	if err != nil { fmt.Println(err); os.Exit(1) }
+	policy, _ := os.ReadFile(filepath.Join(wd, PolicyFile))
+	os.WriteFile(filepath.Join(dir, PolicyFile), policy, 0644)
+	os.MkdirAll(filepath.Join(dir, "metadata"), 0755)
+	os.Chdir(dir)
+	preparePersistence()
+	loadPolicy()
+
+	stateMu.Lock()
+	for _, e := range engines { e.State = StateStopped }
+	lastWalHash, lastStateHash = "", ""
+	walIdx = newWalIndex()
+	stateMu.Unlock()
+	txMu.Lock(); intents = make(map[string]*IntentRecord); transactions = make(map[string]*VibeTransaction); txMu.Unlock()
+	planMu.Lock(); plans = make(map[string]*PlanRecord); planMu.Unlock()
+	approvalMu.Lock(); approvalQueue = make(map[string]*ApprovalRequest); approvalMu.Unlock()
+	entropyMu.Lock(); entropyBudgets = make(map[string]*EntropyBudget); entropyMu.Unlock()
+	driftMu.Lock(); driftRecords = make(map[string]*DriftRecord); driftMu.Unlock()
+	factMu.Lock(); facts = make(map[string]*Fact); factMu.Unlock()
+	agentMu.Lock(); agentKeys = map[string]AgentKey{}; agentMu.Unlock()
+
+	code := m.Run()
+	os.Chdir(wd)
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// usePolicy installs p for the rest of the test and puts the previous policy
+// back afterwards.
+func usePolicy(t *testing.T, p GovernancePolicy) {
+	policyMu.Lock()
+	saved := activePolicy
+	activePolicy = p
+	policyMu.Unlock()
+	t.Cleanup(func() { policyMu.Lock(); activePolicy = saved; policyMu.Unlock() })
+}
+
+// fakeEngine stands in for one adapter. handle answers a call by path and
+// decoded body; a nil reply is served as {"status": "ok"}. Every call is
+// recorded as "METHOD /path".
+type fakeEngine struct {
+	mu     sync.Mutex
+	calls  []string
+	bodies []map[string]interface{}
+	handle func(path string, body map[string]interface{}) map[string]interface{}
+}
+
+func (f *fakeEngine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+	f.mu.Lock()
+	f.calls = append(f.calls, r.Method+" "+r.URL.Path)
+	f.bodies = append(f.bodies, body)
+	handle := f.handle
+	f.mu.Unlock()
+	var res map[string]interface{}
+	if handle != nil { res = handle(r.URL.Path, body) }
+	if res == nil { res = map[string]interface{}{"status": "ok"} }
+	json.NewEncoder(w).Encode(res)
+}
+
+// count returns how many calls reached path.
+func (f *fakeEngine) count(call string) int {
+	f.mu.Lock(); defer f.mu.Unlock()
+	n := 0
+	for _, c := range f.calls { if c == call { n++ } }
+	return n
+}
+
+// startFakeEngines points the orchestrator's Unity and Blender ports at two
+// fake adapters for the rest of the test.
+func startFakeEngines(t *testing.T) (unity, blender *fakeEngine) {
+	unity, blender = &fakeEngine{}, &fakeEngine{}
+	us, bs := httptest.NewServer(unity), httptest.NewServer(blender)
+	port := func(s *httptest.Server) int { p, _ := strconv.Atoi(s.URL[strings.LastIndex(s.URL, ":")+1:]); return p }
+	stateMu.Lock()
+	savedU, savedB := unityPort, blenderPort
+	unityPort, blenderPort = port(us), port(bs)
+	stateMu.Unlock()
+	t.Cleanup(func() {
+		stateMu.Lock(); unityPort, blenderPort = savedU, savedB; stateMu.Unlock()
+		us.Close(); bs.Close()
+	})
+	return unity, blender
+}
+
+// agentSign returns agentID's signature over a decision; agentID must already
+// be registered.
+func agentSign(agentID, action string, args interface{}) string {
+	agentMu.Lock(); secret := agentKeys[agentID].Secret; agentMu.Unlock()
+	return hmacHex(secret, canonicalDecision(action, args))
+}
+
+// humanSign registers approver as a Human key (if it is not already) and
+// returns its signature over a decision.
+func humanSign(approver, action string, args interface{}) string {
+	agentMu.Lock()
+	if _, ok := agentKeys[approver]; !ok { agentKeys[approver] = AgentKey{AgentID: approver, Role: RoleHuman, Secret: "human-" + approver} }
+	secret := agentKeys[approver].Secret
+	agentMu.Unlock()
+	return hmacHex(secret, canonicalDecision(action, args))
+}
+
 func TestIntentConfidenceGate(t *testing.T) {
 	// Initialize some state
-	intents = make(map[string]IntentEnvelope)
+	intents = make(map[string]*IntentRecord)
 	
 	// 1. Test High Confidence
 	highID := uuid.New().String()
-	intents[highID] = IntentEnvelope{
+	intents[highID] = newIntentRecord(highID, IntentEnvelope{
 		Rationale: "Testing high confidence",
 		Confidence: 0.9,
-	}
+	})
 	
 	_, _, err := validate_intent(context.Background(), nil, struct{ID string `json:"intent_id"`}{ID: highID})
 	if err != nil {
@@ -38,15 +162,21 @@ func TestIntentConfidenceGate(t *testing.T) {
 }
 
 func TestNumericalSafety(t *testing.T) {
-	// Test auditPayload for NaN/Inf
-	// Using a string payload that contains "nan" to trigger the auditPayload check
-	badData := map[string]interface{}{
-		"val": "this is nan",
+	// Only numbers are checked for NaN/Inf; words that merely contain the
+	// letters pass.
+	for _, ok := range []interface{}{
+		map[string]interface{}{"unit": "Infantry", "prop": "Banana", "val": "this is nan"},
+		[]float64{0, 1.5, -2},
+	} {
+		if err := auditPayload(ok); err != nil { t.Errorf("Expected %v to pass the audit, got %v", ok, err) }
 	}
-	
-	err := auditPayload(badData)
-	if err == nil {
-		t.Error("Expected error for NaN payload, got nil")
+	for _, bad := range []interface{}{
+		map[string]interface{}{"pos": []interface{}{0.0, math.NaN(), 0.0}},
+		[][]float64{{1}, {math.Inf(-1)}},
+	} {
+		if err := auditPayload(bad); err == nil || !strings.HasPrefix(err.Error(), "NUMERICAL_INSTABILITY") {
+			t.Errorf("Expected %v rejected as numerically unstable, got %v", bad, err)
+		}
 	}
 }
 
@@ -61,3 +191,1903 @@ func TestSecurityAudit(t *testing.T) {
 		t.Error("Expected error for malicious payload, got nil")
 	}
 }
+
+func TestDryRunDoesNotStoreIntent(t *testing.T) {
+	intents = make(map[string]*IntentRecord)
+	_, blender := startFakeEngines(t)
+
+	env := IntentEnvelope{
+		Rationale:  "Preview a light tweak",
+		Provenance: "test",
+		Confidence: 0.5,
+		Intent:     IntentLight,
+		Opcode:     OpTransform, // Not permitted for LIGHT
+		Scope:      []string{"Crate_01"},
+		DryRun:     true,
+	}
+	report := dryRunIntent(context.Background(), env, map[string]interface{}{"target": "blender", "payload": map[string]interface{}{"id": "Crate_01", "energy": 10.0}})
+
+	if report.WouldCommit {
+		t.Error("Expected dry run with failing checks to report would_commit=false")
+	}
+	failed := make(map[string]bool)
+	for _, c := range report.Checks {
+		if !c.Passed { failed[c.Name] = true }
+	}
+	if !failed["policy"] || !failed["approval"] {
+		t.Errorf("Expected policy and approval to fail, got %+v", report.Checks)
+	}
+	if len(report.Predicted) != 1 || report.Predicted[0].Engine != "blender" || report.Predicted[0].Properties[0] != "energy" {
+		t.Errorf("Unexpected predicted diff: %+v", report.Predicted)
+	}
+	// The envelope is unsigned, so no engine was asked to preflight it
+	if !failed["signature"] || !failed["preflight:blender"] || blender.count("POST /preflight/run") != 0 {
+		t.Errorf("Expected an unsigned dry run to skip preflights, got %+v (%d calls)", report.Checks, blender.count("POST /preflight/run"))
+	}
+	if _, _, err := submit_intent(context.Background(), nil, SubmitIntentArgs{Envelope: env}); err == nil || !strings.HasPrefix(err.Error(), "SIGNATURE_REQUIRED") {
+		t.Errorf("Expected an unsigned dry run submission to be refused, got %v", err)
+	}
+
+	agentMu.Lock(); agentKeys["operator.light"] = AgentKey{AgentID: "operator.light", Role: RoleOperator, Engine: "blender", Secret: "l1ght"}; agentMu.Unlock()
+	env.AgentID = "operator.light"
+	env.Signature = signEnvelope("l1ght", env)
+	if _, _, err := submit_intent(context.Background(), nil, SubmitIntentArgs{Envelope: env, OpSpec: map[string]interface{}{"target": "blender", "payload": map[string]interface{}{"id": "Crate_01"}}}); err != nil {
+		t.Errorf("Dry run submission should not error, got %v", err)
+	}
+	if blender.count("POST /preflight/run") != 1 {
+		t.Errorf("Expected a signed dry run to preflight, got %d calls", blender.count("POST /preflight/run"))
+	}
+	if len(intents) != 0 {
+		t.Errorf("Dry run must not store intents, found %d", len(intents))
+	}
+}
+
+func TestUndoGroupsTransactionMutations(t *testing.T) {
+	undoStack, redoStack = nil, nil
+	pendingUndo = make(map[string]*UndoRecord)
+
+	txMu.Lock()
+	activeTransaction = &VibeTransaction{ID: "tx-undo", IntentID: "intent-undo"}
+	txMu.Unlock()
+
+	first := newUndoRecord("sync_material", OpMaterial, "", "")
+	second := newUndoRecord("sync_transform", OpTransform, "", "")
+	if first != second {
+		t.Fatal("Expected mutations inside a transaction to share one undo record")
+	}
+	first.Steps = append(first.Steps, UndoStep{Engine: "unity", Endpoint: "material/update", UndoToken: "u-1"})
+	second.Steps = append(second.Steps, UndoStep{Engine: "blender", Endpoint: "transform/set"})
+
+	sealUndoRecord(first)
+	if len(undoStack) != 0 {
+		t.Fatal("Transaction records must not be pushed before commit")
+	}
+	commitTransactionUndo("tx-undo")
+
+	txMu.Lock()
+	activeTransaction = nil
+	txMu.Unlock()
+
+	if len(undoStack) != 1 || len(undoStack[0].Steps) != 2 {
+		t.Fatalf("Expected one record with two steps, got %+v", undoStack)
+	}
+
+	// The blender step has neither a token nor an inverse, so undo must refuse
+	// before touching any engine.
+	testGrant("operator.undo", nil, nil, nil)
+	grant := encodeGrant(CapabilityGrant{ID: "g-undo", Holder: "operator.undo", IntentID: "intent-undo", Opcodes: []VibeOpcode{OpMaterial, OpTransform}, Engines: []string{"unity", "blender"}, ExpiresAt: time.Now().Add(time.Minute)})
+	if _, _, err := undo(context.Background(), nil, UndoArgs{Grant: grant}); err == nil || !strings.HasPrefix(err.Error(), "NON_REVERSIBLE") {
+		t.Errorf("Expected NON_REVERSIBLE error, got %v", err)
+	}
+	if len(undoStack) != 1 {
+		t.Error("Failed undo must leave the stack intact")
+	}
+}
+
+func TestUndoRewindsBothEnginesThroughAdapters(t *testing.T) {
+	undoStack, redoStack = nil, nil
+	unity, blender := startFakeEngines(t)
+
+	// Unity answers with undo tokens and keeps the prior value per token;
+	// Blender answers with an explicit inverse.
+	var sceneMu sync.Mutex
+	scene := map[string]interface{}{"unity": "home", "blender": "home"}
+	tokens := map[string]interface{}{}
+	unity.handle = func(path string, body map[string]interface{}) map[string]interface{} {
+		sceneMu.Lock(); defer sceneMu.Unlock()
+		switch path {
+		case "/transform/set":
+			tokens["u-1"] = scene["unity"]
+			scene["unity"] = body["pos"]
+			return map[string]interface{}{"status": "ok", "undo_token": "u-1"}
+		case "/undo":
+			prev, ok := tokens[body["undo_token"].(string)]
+			if !ok { return map[string]interface{}{"error": "unknown undo token"} }
+			scene["unity"] = prev
+		}
+		return nil
+	}
+	blender.handle = func(path string, body map[string]interface{}) map[string]interface{} {
+		sceneMu.Lock(); defer sceneMu.Unlock()
+		if path != "/transform/set" { return nil }
+		prev := scene["blender"]
+		scene["blender"] = body["pos"]
+		return map[string]interface{}{"status": "ok", "inverse": map[string]interface{}{"endpoint": "transform/set", "payload": map[string]interface{}{"id": "Crate_01", "pos": prev}}}
+	}
+
+	ctx := context.Background()
+	rec := newUndoRecord("sync_transform", OpTransform, "intent-rewind", "operator.unity")
+	newConflictClaim("intent-rewind", "operator.unity", ClassCosmetic, []string{"Crate_01"}, []string{"pos"}, rec)
+	for _, target := range []string{"unity", "blender"} {
+		if _, err := sendMutation(ctx, rec, target, "transform/set", map[string]interface{}{"id": "Crate_01", "pos": "dock"}); err != nil {
+			t.Fatalf("Expected %s to accept the mutation, got %v", target, err)
+		}
+	}
+	sealUndoRecord(rec)
+	if err := isReversible(rec); err != nil {
+		t.Fatalf("Expected adapter replies to make the record reversible, got %v", err)
+	}
+
+	// Undo is gated like the mutation: a grant for the record's intent
+	// covering its scope, and no human working on it.
+	if _, _, err := undo(ctx, nil, UndoArgs{}); err == nil || !strings.HasPrefix(err.Error(), "CAPABILITY_REQUIRED") {
+		t.Fatalf("Expected an ungranted undo to be refused, got %v", err)
+	}
+	mint := func(intentID string, uuids []string) string {
+		return encodeGrant(CapabilityGrant{ID: uuid.New().String(), Holder: "operator.unity", IntentID: intentID, UUIDs: uuids, Opcodes: []VibeOpcode{OpTransform}, Engines: []string{"unity", "blender"}, ExpiresAt: time.Now().Add(time.Minute)})
+	}
+	testGrant("operator.unity", nil, nil, nil)
+	if _, _, err := undo(ctx, nil, UndoArgs{Grant: mint("intent-other", []string{"Crate_01"})}); err == nil || !strings.HasPrefix(err.Error(), "CAPABILITY_DENIED") {
+		t.Fatalf("Expected a grant for another intent to be refused, got %v", err)
+	}
+	if _, _, err := undo(ctx, nil, UndoArgs{Grant: mint("intent-rewind", []string{"Rock_01"})}); err == nil || !strings.HasPrefix(err.Error(), "CAPABILITY_DENIED") {
+		t.Fatalf("Expected a grant outside the record's scope to be refused, got %v", err)
+	}
+	lockMu.Lock(); lockTable["Crate_01"] = &VibeLock{UUID: "Crate_01", Type: LockHumanActive, Actor: ActorHuman, Owner: "artist", ExpiresAt: time.Now().Add(time.Minute)}; lockMu.Unlock()
+	if _, _, err := undo(ctx, nil, UndoArgs{Grant: mint("intent-rewind", []string{"Crate_01"})}); err == nil || unity.count("POST /undo") != 0 {
+		t.Fatalf("Expected an undo under a human lock to be refused, got %v", err)
+	}
+	lockMu.Lock(); delete(lockTable, "Crate_01"); lockMu.Unlock()
+	if _, _, err := undo(ctx, nil, UndoArgs{Grant: mint("intent-rewind", []string{"Crate_01"})}); err != nil {
+		t.Fatalf("Expected undo to succeed, got %v", err)
+	}
+	sceneMu.Lock()
+	if scene["unity"] != "home" || scene["blender"] != "home" {
+		t.Errorf("Expected both scenes restored, got %v", scene)
+	}
+	sceneMu.Unlock()
+	if unity.count("POST /undo") != 1 || blender.count("POST /transform/set") != 2 {
+		t.Errorf("Expected a Unity undo call and a Blender inverse, got %v / %v", unity.calls, blender.calls)
+	}
+	if len(undoStack) != 0 || len(redoStack) != 1 {
+		t.Errorf("Expected the record to move to the redo stack, got %d/%d", len(undoStack), len(redoStack))
+	}
+	if _, _, err := redo(ctx, nil, UndoArgs{}); err == nil || len(redoStack) != 1 {
+		t.Errorf("Expected an ungranted redo to be refused, got %v", err)
+	}
+	conflictMu.Lock(); conflictClaims = nil; conflictMu.Unlock()
+
+	// A refusal in the reply body is a failed mutation, not a silent success.
+	unity.handle = func(string, map[string]interface{}) map[string]interface{} { return map[string]interface{}{"error": "Unknown UUID: Ghost"} }
+	if _, err := sendMutation(ctx, newUndoRecord("sync_transform", OpTransform, "", ""), "unity", "transform/set", map[string]interface{}{"id": "Ghost"}); err == nil {
+		t.Error("Expected an adapter error reply to fail the mutation")
+	}
+}
+
+func TestProofOfWorkRequiresCrossLayerEcho(t *testing.T) {
+	tx := &VibeTransaction{ID: "tx-proof"}
+	observed := EchoTriplet{BlenderExportHash: "h1", UnityImportHash: "h1", BridgeVerificationHash: "h1"}
+	proof := ProofOfWork{EchoTriplet: observed, TransactionID: tx.ID, Binding: proofBinding(tx.ID, "h1", "h1", "h1")}
+
+	if err := verifyProofOfWork(tx, proof, observed); err != nil {
+		t.Errorf("Expected matching proof to pass, got %v", err)
+	}
+
+	replayed := proof
+	replayed.TransactionID = "tx-other"
+	if err := verifyProofOfWork(tx, replayed, observed); err == nil {
+		t.Error("Expected proof bound to another transaction to fail")
+	}
+
+	stale := observed
+	stale.UnityImportHash = "h2"
+	if err := verifyProofOfWork(tx, proof, stale); err == nil {
+		t.Error("Expected proof to fail when fresh Unity state differs")
+	}
+
+	if err := verifyProofOfWork(tx, ProofOfWork{TransactionID: tx.ID}, observed); err == nil {
+		t.Error("Expected empty proof to fail")
+	}
+
+	// The binding is keyed: a digest the agent computes itself is refused.
+	sum := sha256.Sum256([]byte(tx.ID + "|h1|h1|h1"))
+	forged := proof
+	forged.Binding = hex.EncodeToString(sum[:])
+	if err := verifyProofOfWork(tx, forged, observed); err == nil {
+		t.Error("Expected an unkeyed binding to fail")
+	}
+}
+
+func TestCommitRequirementsIssueBindingFromIndependentLayers(t *testing.T) {
+	unity, blender := startFakeEngines(t)
+	blender.handle = func(path string, body map[string]interface{}) map[string]interface{} {
+		if path == "/export" { return map[string]interface{}{"status": "OK", "hash": "scene-1"} }
+		return nil
+	}
+	unity.handle = func(path string, body map[string]interface{}) map[string]interface{} {
+		if path == "/validate" { return map[string]interface{}{"status": "OK", "hash": "scene-1"} }
+		return nil
+	}
+	recordRefereeHash("unity", "scene-1"); recordRefereeHash("blender", "scene-1")
+	t.Cleanup(func() { refereeMu.Lock(); refereeHashes = make(map[string]string); refereeMu.Unlock() })
+	txMu.Lock(); transactions["intent-echo"] = &VibeTransaction{ID: "tx-echo", IntentID: "intent-echo"}; txMu.Unlock()
+	t.Cleanup(func() { txMu.Lock(); delete(transactions, "intent-echo"); txMu.Unlock() })
+
+	res, _, err := get_bridge_commit_requirements(context.Background(), nil, CommitRequirementsArgs{IntentID: "intent-echo"})
+	if err != nil {
+		t.Fatalf("Expected requirements, got %v", err)
+	}
+	var out struct{ Result BridgeCommitRequirements `json:"result"` }
+	json.Unmarshal([]byte(res.Content[0].(*mcp.TextContent).Text), &out)
+	if !out.Result.CommitAllowed || out.Result.TransactionID != "tx-echo" {
+		t.Fatalf("Expected an allowed commit for tx-echo, got %+v", out.Result)
+	}
+	if blender.count("POST /export") != 1 || unity.count("POST /validate") != 1 || blender.count("GET /state/get") != 0 {
+		t.Errorf("Expected export and validate to be read directly, got %v / %v", blender.calls, unity.calls)
+	}
+	proof := ProofOfWork{EchoTriplet: EchoTriplet{"scene-1", "scene-1", "scene-1"}, TransactionID: "tx-echo", Binding: out.Result.Binding}
+	if err := verifyProofOfWork(&VibeTransaction{ID: "tx-echo"}, proof, observeCommitState(context.Background())); err != nil {
+		t.Errorf("Expected the issued binding to commit, got %v", err)
+	}
+}
+
+func TestIntentDecaysWhenBasisMoves(t *testing.T) {
+	intents = make(map[string]*IntentRecord)
+
+	walMu.Lock()
+	saved := lastStateHash
+	lastStateHash = "wal-a"
+	walMu.Unlock()
+	defer func() { walMu.Lock(); lastStateHash = saved; walMu.Unlock() }()
+
+	rec := newIntentRecord("decay-1", IntentEnvelope{Rationale: "r", Confidence: 0.9, BasedOnHashes: map[string]string{"wal": "wal-a"}})
+	intents[rec.ID] = rec
+	if got, _ := lookupIntent(rec.ID); got.Status != IntentSubmitted {
+		t.Fatalf("Expected intent to stay SUBMITTED, got %s", got.Status)
+	}
+
+	walMu.Lock()
+	lastStateHash = "wal-b"
+	walMu.Unlock()
+
+	got, _ := lookupIntent(rec.ID)
+	if got.Status != IntentExpired {
+		t.Fatalf("Expected intent to expire after WAL head moved, got %s", got.Status)
+	}
+	if _, _, err := validate_intent(context.Background(), nil, struct{ID string `json:"intent_id"`}{ID: rec.ID}); err == nil {
+		t.Error("Expected validation of an expired intent to fail")
+	}
+
+	ttl := newIntentRecord("decay-2", IntentEnvelope{Rationale: "r", Confidence: 0.9})
+	ttl.ExpiresAt = time.Now().Add(-time.Second)
+	intents[ttl.ID] = ttl
+	sweepStaleIntents()
+	if ttl.Status != IntentExpired || ttl.Reason != "TTL_ELAPSED" {
+		t.Errorf("Expected TTL expiry, got %s (%s)", ttl.Status, ttl.Reason)
+	}
+
+	if err := transitionIntent(ttl.ID, IntentExecuting, "late"); err == nil {
+		t.Error("Expected terminal intent to refuse further transitions")
+	}
+	if _, _, err := begin_atomic_operation(context.Background(), nil, AtomicOpArgs{IntentID: "never-submitted"}); err == nil {
+		t.Error("Expected a transaction for an unknown intent to be refused")
+	}
+	if _, ok := transactions["never-submitted"]; ok {
+		t.Error("Refused transaction must not be opened")
+	}
+
+	// Terminal records are kept for audit, then pruned.
+	ttl.UpdatedAt = time.Now().Add(-intentRetention - time.Minute)
+	sweepStaleIntents()
+	if _, ok := intents[ttl.ID]; ok {
+		t.Error("Expected a terminal intent past retention to be pruned")
+	}
+	if _, ok := intents[rec.ID]; !ok {
+		t.Error("Expected a recently finished intent to be kept")
+	}
+}
+
+func TestPolicyDrivesOpcodeBinding(t *testing.T) {
+	usePolicy(t, GovernancePolicy{
+		Version: "test",
+		Default: PolicyRule{ID: "default", AllowedOpcodes: []VibeOpcode{OpAudit}, MinConfidence: 0.8},
+		Rules: []PolicyRule{
+			{ID: "animate", Intent: IntentAnimate, AllowedOpcodes: []VibeOpcode{OpTransform}, UUIDScopes: []string{"Rig_*"}, MinConfidence: 0.5},
+		},
+	})
+
+	d := evaluatePolicy(IntentEnvelope{Intent: IntentAnimate, Opcode: OpTransform, Confidence: 0.6, Scope: []string{"Rig_Arm"}}, "")
+	if !d.Allowed || d.RuleID != "animate" || d.RequiredApprovals != 0 {
+		t.Errorf("Expected animate rule to allow without approval, got %+v", d)
+	}
+	if d := evaluatePolicy(IntentEnvelope{Intent: IntentAnimate, Opcode: OpTransform, Confidence: 0.6, Scope: []string{"Crate_01"}}, ""); d.Allowed {
+		t.Error("Expected UUID outside rule scope to be denied")
+	}
+	if d := evaluatePolicy(IntentEnvelope{Intent: IntentGeneral, Opcode: OpTransform, Confidence: 0.9}, ""); d.Allowed || d.RuleID != "default" {
+		t.Errorf("Expected default rule to deny transforms, got %+v", d)
+	}
+	if d := evaluatePolicy(IntentEnvelope{Intent: IntentGeneral, Opcode: OpAudit, Confidence: 0.5}, ""); d.RequiredApprovals != 1 {
+		t.Errorf("Expected low confidence to require approval, got %+v", d)
+	}
+}
+
+func TestApprovalQueueIsPerIntent(t *testing.T) {
+	intents = make(map[string]*IntentRecord)
+	approvalQueue = make(map[string]*ApprovalRequest)
+
+	held := newIntentRecord("held", IntentEnvelope{Rationale: "risky", Confidence: 0.4, Scope: []string{"Crate_01"}})
+	held.Policy = PolicyDecision{RuleID: "test", RequiredApprovals: 2}
+	other := newIntentRecord("other", IntentEnvelope{Rationale: "safe", Confidence: 0.9})
+	intents[held.ID], intents[other.ID] = held, other
+
+	enqueueApproval(held, DryRunReport{})
+	stateMu.RLock()
+	for name, e := range engines {
+		if e.State == StateHumanReq { t.Errorf("Engine %s must not be frozen by a pending approval", name) }
+	}
+	stateMu.RUnlock()
+
+	if _, err := decideApproval(IntentDecisionArgs{IntentID: held.ID, Approver: "alice"}, true); err == nil {
+		t.Error("Expected a reason to be required")
+	}
+	claimed := IntentDecisionArgs{IntentID: held.ID, Approver: "mallory", Reason: "trust me"}
+	if _, err := decideApproval(claimed, true); err == nil || !strings.HasPrefix(err.Error(), "UNKNOWN_AGENT") {
+		t.Errorf("Expected a self-asserted approver to be refused, got %v", err)
+	}
+	agentMu.Lock(); agentKeys["foreman.unity"] = AgentKey{AgentID: "foreman.unity", Role: RoleForeman, Secret: "f"}; agentMu.Unlock()
+	byAgent := IntentDecisionArgs{IntentID: held.ID, Approver: "foreman.unity", Reason: "self-approve"}
+	byAgent.Signature = hmacHex("f", canonicalDecision("approve_intent", byAgent))
+	if _, err := decideApproval(byAgent, true); err == nil || !strings.HasPrefix(err.Error(), "APPROVER_NOT_HUMAN") {
+		t.Errorf("Expected an agent key to be refused as approver, got %v", err)
+	}
+	first := IntentDecisionArgs{IntentID: held.ID, Approver: "alice", Reason: "looks right"}
+	first.Signature = humanSign("alice", "approve_intent", first)
+	if st, _ := decideApproval(first, true); st != IntentSubmitted {
+		t.Errorf("Expected intent to wait for a second approver, got %s", st)
+	}
+	again := IntentDecisionArgs{IntentID: held.ID, Approver: "alice", Reason: "again"}
+	again.Signature = humanSign("alice", "approve_intent", again)
+	if _, err := decideApproval(again, true); err == nil {
+		t.Error("Expected the same approver to be refused twice")
+	}
+	second := IntentDecisionArgs{IntentID: held.ID, Approver: "bob", Reason: "agreed"}
+	if _, err := decideApproval(second, true); err == nil {
+		t.Error("Expected an unsigned decision to be refused")
+	}
+	second.Signature = humanSign("bob", "approve_intent", second)
+	if st, _ := decideApproval(second, true); st != IntentApproved {
+		t.Errorf("Expected APPROVED after two approvers, got %s", st)
+	}
+	if held.Status != IntentApproved || other.Status != IntentSubmitted {
+		t.Errorf("Only the held intent should change, got held=%s other=%s", held.Status, other.Status)
+	}
+	if len(pendingApprovals()) != 0 {
+		t.Error("Approved intent should leave the queue")
+	}
+}
+
+func TestEnvelopeSignatureIdentifiesAgent(t *testing.T) {
+	intents = make(map[string]*IntentRecord)
+	agentMu.Lock()
+	agentKeys = map[string]AgentKey{"foreman.blender": {AgentID: "foreman.blender", Role: RoleForeman, Engine: "blender", Secret: "s3cret"}}
+	agentMu.Unlock()
+
+	env := IntentEnvelope{
+		Rationale:       "Decimate the rock set",
+		Provenance:      "AI_PROPOSED",
+		Confidence:      0.9,
+		Intent:          IntentOptimize,
+		InstructionHash: "ins-1",
+		PlanHash:        "plan-1",
+		AgentID:         "foreman.blender",
+	}
+	w, logHash := readWindowAsAgent(t)
+	if _, _, err := ingest_forensic_logs(context.Background(), nil, ingestAs("foreman.blender", w, logHash)); err != nil {
+		t.Fatalf("Ingestion failed: %v", err)
+	}
+	env.BasedOnHashes = map[string]string{"log": logHash}
+	env.Signature = signEnvelope("s3cret", env)
+
+	res, _, err := submit_intent(context.Background(), nil, SubmitIntentArgs{Envelope: env})
+	if err != nil {
+		t.Fatalf("Expected signed envelope to be accepted, got %v", err)
+	}
+	var id string
+	for k := range intents { id = k }
+	if rec := intents[id]; rec == nil || rec.Agent.AgentID != "foreman.blender" || rec.Agent.Role != RoleForeman {
+		t.Errorf("Expected verified agent on intent, got %+v (result %v)", intents[id], res)
+	}
+
+	tampered := env
+	tampered.PlanHash = "plan-2"
+	if _, err := verifyEnvelope(tampered); err == nil {
+		t.Error("Expected tampered plan hash to invalidate the signature")
+	}
+	forged := env
+	forged.AgentID = "operator.unity"
+	if _, err := verifyEnvelope(forged); err == nil {
+		t.Error("Expected unknown agent to be refused")
+	}
+	env.Signature = ""
+	if _, _, err := submit_intent(context.Background(), nil, SubmitIntentArgs{Envelope: env}); err == nil {
+		t.Error("Expected unsigned envelope to be refused")
+	}
+
+	// Entries name their agent explicitly rather than inheriting whichever
+	// transaction happens to be open.
+	txMu.Lock(); prev := activeTransaction; activeTransaction = &VibeTransaction{ID: "tx-other", Agent: "operator.unity"}; txMu.Unlock()
+	journalPolicyDecision(id, "foreman.blender", "validate", PolicyDecision{Allowed: true})
+	txMu.Lock(); activeTransaction = prev; txMu.Unlock()
+	if last := lastWalEntry(t); last["agent"] != "foreman.blender" {
+		t.Errorf("Expected the policy entry to keep its verified agent, got %v", last["agent"])
+	}
+}
+
+// testGrant registers holder as an Operator (if it is not already) and mints
+// it a one-minute grant.
+func testGrant(holder string, engines []string, ops []VibeOpcode, uuids []string) string {
+	agentMu.Lock()
+	if _, ok := agentKeys[holder]; !ok { agentKeys[holder] = AgentKey{AgentID: holder, Role: RoleOperator, Secret: "op-" + holder} }
+	agentMu.Unlock()
+	return encodeGrant(CapabilityGrant{ID: uuid.New().String(), Holder: holder, UUIDs: uuids, Opcodes: ops, Engines: engines, ExpiresAt: time.Now().Add(time.Minute)})
+}
+
+// readWindowAsAgent fetches the log window the way an agent does and hashes
+// it with the documented serialization.
+func readWindowAsAgent(t *testing.T) (LogWindow, string) {
+	res, _, err := get_forensic_log_window(context.Background(), nil, struct{}{})
+	if err != nil { t.Fatalf("Expected current window, got %v", err) }
+	text := res.Content[0].(*mcp.TextContent).Text
+	if strings.Contains(text, "log_hash") { t.Fatalf("Expected the window to be handed out without its hash, got %s", text) }
+	var wrapped struct{ Result LogWindow `json:"result"` }
+	json.Unmarshal([]byte(text), &wrapped)
+	w := wrapped.Result
+	sum := sha256.Sum256([]byte("wal\n" + strings.Join(w.Wal, "\n") + "\nevents\n" + strings.Join(w.Events, "\n")))
+	return w, hex.EncodeToString(sum[:])
+}
+
+// humanPerimeter signs a perimeter_lock request as the Human "lead".
+func humanPerimeter(args PerimeterLockArgs) PerimeterLockArgs {
+	args.Approver = "lead"
+	args.Signature = humanSign("lead", "perimeter_lock", args)
+	return args
+}
+
+// ingestAs is the signed ingest_forensic_logs request of a registered agent.
+func ingestAs(agentID string, w LogWindow, logHash string) IngestLogsArgs {
+	args := IngestLogsArgs{LogHash: logHash, WalHead: w.WalHead, EventCount: w.EventCount, AgentID: agentID}
+	args.Signature = agentSign(agentID, "ingest_forensic_logs", args)
+	return args
+}
+
+// lastWalEntry returns the most recent WAL entry.
+func lastWalEntry(t *testing.T) map[string]interface{} {
+	data, err := os.ReadFile(WalFile)
+	if err != nil { t.Fatalf("Reading WAL: %v", err) }
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	var e map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &e); err != nil { t.Fatalf("Parsing WAL entry: %v", err) }
+	return e
+}
+
+func TestIntentClassIsDerivedFromOperation(t *testing.T) {
+	cases := []struct {
+		op       VibeOpcode
+		endpoint string
+		payload  map[string]interface{}
+		want     IntentClass
+	}{
+		{OpTransform, "transform/set", map[string]interface{}{"id": "Crate_01"}, ClassCosmetic},
+		{OpMaterial, "material/update", map[string]interface{}{"id": "Crate_01", "roughness": 0.4}, ClassCosmetic},
+		{OpTransform, "transform/set", map[string]interface{}{"id": "Crate_01", "parent": "Dock"}, ClassStructural},
+		{OpModifier, "modifier/apply", nil, ClassStructural},
+		{0, "object/delete", map[string]interface{}{"id": "Crate_01"}, ClassDestructive},
+		{OpNode, "mesh/set", map[string]interface{}{"overwrite": true}, ClassDestructive},
+		{0, "custom/thing", nil, ClassStructural}, // Unknown operations never take the fast path
+	}
+	for _, c := range cases {
+		if got := classifyIntent(c.op, c.endpoint, c.payload); got != c.want {
+			t.Errorf("classifyIntent(%X, %s, %v) = %s, want %s", c.op, c.endpoint, c.payload, got, c.want)
+		}
+	}
+
+	usePolicy(t, GovernancePolicy{Version: "test", Default: PolicyRule{ID: "default"}, Rules: []PolicyRule{
+		{ID: "light.nodes", Intent: IntentLight, AllowedOpcodes: []VibeOpcode{OpNode}, AllowedClasses: []IntentClass{ClassCosmetic}},
+	}})
+	env := IntentEnvelope{Intent: IntentLight, Opcode: OpNode, Confidence: 1}
+	spec := map[string]interface{}{"target": "blender", "endpoint": "node/delete", "payload": map[string]interface{}{"id": "Key_Light"}}
+	if d := evaluatePolicy(env, classifyOpSpec(env.Opcode, spec)); d.Allowed {
+		t.Error("Expected a destructive node operation to be refused for a cosmetic-only intent")
+	}
+	// The tool itself never skips governance: an unknown intent is refused
+	// outright, and a known one is held to its policy rule.
+	_, blender := startFakeEngines(t)
+	testGrant("operator.light", nil, nil, nil)
+	nodeGrant := func(intentID string) string {
+		return encodeGrant(CapabilityGrant{ID: uuid.New().String(), Holder: "operator.light", IntentID: intentID, UUIDs: []string{"Key_Light"}, Opcodes: []VibeOpcode{OpNode}, Engines: []string{"blender"}, ExpiresAt: time.Now().Add(time.Minute)})
+	}
+	if _, _, err := execute_governed_mutation(context.Background(), nil, MutateArgs{IntentID: "made-up", OpSpec: spec, Grant: nodeGrant("made-up")}); err == nil || !strings.HasPrefix(err.Error(), "UNKNOWN_INTENT") {
+		t.Errorf("Expected an unknown intent to be refused, got %v", err)
+	}
+	lightID := uuid.New().String()
+	light := newIntentRecord(lightID, IntentEnvelope{Intent: IntentLight, Opcode: OpNode, Confidence: 1, Scope: []string{"Key_Light"}})
+	light.Status = IntentValidated
+	txMu.Lock(); intents[lightID] = light; txMu.Unlock()
+	if _, _, err := execute_governed_mutation(context.Background(), nil, MutateArgs{IntentID: lightID, OpSpec: spec, Grant: nodeGrant("another-intent")}); err == nil || !strings.HasPrefix(err.Error(), "CAPABILITY_DENIED") {
+		t.Errorf("Expected a grant minted for another intent to be refused, got %v", err)
+	}
+	txMu.Lock(); light.Status = IntentSubmitted; txMu.Unlock()
+	if _, _, err := execute_governed_mutation(context.Background(), nil, MutateArgs{IntentID: lightID, OpSpec: spec, Grant: nodeGrant(lightID)}); err == nil || !strings.HasPrefix(err.Error(), "INTENT_SUBMITTED") {
+		t.Errorf("Expected an intent that was never admitted to be refused, got %v", err)
+	}
+	txMu.Lock(); light.Status = IntentValidated; txMu.Unlock()
+	if _, _, err := execute_governed_mutation(context.Background(), nil, MutateArgs{IntentID: lightID, OpSpec: spec, Grant: nodeGrant(lightID)}); err == nil || !strings.HasPrefix(err.Error(), "CLASS_FORBIDDEN") {
+		t.Errorf("Expected the intent's policy rule to refuse a destructive node operation, got %v", err)
+	}
+	if n := blender.count("POST /node/delete"); n != 0 {
+		t.Errorf("Expected no refused mutation to reach the engine, got %d calls", n)
+	}
+	txMu.Lock(); delete(intents, lightID); txMu.Unlock()
+
+	bufferMu.Lock(); intentBuffer = make(map[string]*bufferedIntent); bufferMu.Unlock()
+	bufferSpeculativeIntent("Crate_01", "sync_transform", "transform/set", nil, ClassCosmetic, nil)
+	bufferSpeculativeIntent("Crate_02", "execute_governed_mutation", "object/delete", nil, ClassDestructive, nil)
+	flushIntentBuffer()
+	bufferMu.Lock()
+	_, waiting := intentBuffer["Crate_01"]
+	_, batched := intentBuffer["Crate_02"]
+	intentBuffer = make(map[string]*bufferedIntent)
+	bufferMu.Unlock()
+	if !waiting {
+		t.Error("Expected cosmetic intent to wait out its batch window")
+	}
+	if batched {
+		t.Error("Expected batching to be disabled for destructive intents")
+	}
+}
+
+func TestCapabilityGrantDelegation(t *testing.T) {
+	agentMu.Lock()
+	agentKeys = map[string]AgentKey{
+		"foreman.blender":  {AgentID: "foreman.blender", Role: RoleForeman, Engine: "blender", Secret: "f-secret"},
+		"operator.blender": {AgentID: "operator.blender", Role: RoleOperator, Engine: "blender", Secret: "o-secret"},
+	}
+	agentMu.Unlock()
+
+	intents = map[string]*IntentRecord{"intent-grant": {
+		ID:        "intent-grant",
+		Envelope:  IntentEnvelope{Intent: IntentOptimize, Opcode: OpModifier, Scope: []string{"Rock_01", "Rock_02"}},
+		Agent:     AgentIdentity{AgentID: "foreman.blender", Role: RoleForeman, Engine: "blender"},
+		Status:    IntentValidated,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}}
+
+	mint := MintGrantArgs{IntentID: "intent-grant", Engines: []string{"blender"}}
+	if _, _, err := mint_capability_grant(context.Background(), nil, mint); err == nil || !strings.HasPrefix(err.Error(), "SIGNATURE_INVALID") {
+		t.Errorf("Expected an unsigned grant request to be refused, got %v", err)
+	}
+	mint.Signature = agentSign("operator.blender", "mint_capability_grant", mint)
+	if _, _, err := mint_capability_grant(context.Background(), nil, mint); err == nil || !strings.HasPrefix(err.Error(), "SIGNATURE_INVALID") {
+		t.Errorf("Expected a grant request signed by another agent to be refused, got %v", err)
+	}
+	mint.Signature = agentSign("foreman.blender", "mint_capability_grant", mint)
+	if _, _, err := mint_capability_grant(context.Background(), nil, mint); err != nil {
+		t.Fatalf("Expected grant for validated intent, got %v", err)
+	}
+	byHuman := MintGrantArgs{IntentID: "intent-grant", Engines: []string{"blender"}, Approver: "lead"}
+	byHuman.Signature = humanSign("lead", "mint_capability_grant", byHuman)
+	if _, _, err := mint_capability_grant(context.Background(), nil, byHuman); err != nil {
+		t.Errorf("Expected a Human to mint on the agent's behalf, got %v", err)
+	}
+	if _, err := checkGrant(encodeGrant(CapabilityGrant{ID: "z", Holder: "foreman.blender", Opcodes: []VibeOpcode{OpModifier}, Engines: []string{"blender"}, ExpiresAt: time.Now().Add(time.Minute)}), []string{"blender"}, 0, nil); err == nil {
+		t.Error("Expected a grant check without an opcode to be refused")
+	}
+	parent := CapabilityGrant{ID: "p", Holder: "foreman.blender", IntentID: "intent-grant", UUIDs: []string{"Rock_01", "Rock_02"}, Opcodes: []VibeOpcode{OpModifier}, Engines: []string{"blender"}, ExpiresAt: time.Now().Add(time.Minute)}
+	token := encodeGrant(parent)
+
+	if _, err := checkGrant(token, []string{"blender"}, OpModifier, []string{"Rock_02"}); err != nil {
+		t.Errorf("Expected grant to cover Rock_02, got %v", err)
+	}
+	if _, err := checkGrant(token, []string{"unity"}, OpModifier, []string{"Rock_02"}); err == nil {
+		t.Error("Expected grant to refuse an engine it does not name")
+	}
+	if _, err := checkGrant(token, []string{"blender"}, OpTransform, nil); err == nil {
+		t.Error("Expected grant to refuse an opcode it does not name")
+	}
+	if _, err := checkGrant("", []string{"blender"}, OpModifier, nil); err == nil {
+		t.Error("Expected missing grant to be refused")
+	}
+
+	args := DelegateGrantArgs{Grant: token, To: "operator.blender", WorkOrderID: "wo-1", UUIDs: []string{"Rock_01"}}
+	data, _ := json.Marshal(args)
+	args.Signature = hmacHex("f-secret", data)
+	if _, _, err := delegate_capability_grant(context.Background(), nil, args); err != nil {
+		t.Fatalf("Expected Foreman delegation to succeed, got %v", err)
+	}
+
+	child := CapabilityGrant{ID: "c", Holder: "operator.blender", ParentID: "p", WorkOrderID: "wo-1", UUIDs: []string{"Rock_01"}, Opcodes: []VibeOpcode{OpModifier}, Engines: []string{"blender"}, ExpiresAt: time.Now().Add(time.Minute)}
+	childToken := encodeGrant(child)
+	if _, err := checkGrant(childToken, []string{"blender"}, OpModifier, []string{"Rock_02"}); err == nil {
+		t.Error("Expected delegated grant to be limited to Rock_01")
+	}
+
+	wider := DelegateGrantArgs{Grant: token, To: "operator.blender", WorkOrderID: "wo-2", UUIDs: []string{"Tree_01"}}
+	data, _ = json.Marshal(wider)
+	wider.Signature = hmacHex("f-secret", data)
+	if _, _, err := delegate_capability_grant(context.Background(), nil, wider); err == nil {
+		t.Error("Expected delegation beyond the parent scope to be refused")
+	}
+
+	again := DelegateGrantArgs{Grant: childToken, To: "operator.blender", WorkOrderID: "wo-3"}
+	data, _ = json.Marshal(again)
+	again.Signature = hmacHex("o-secret", data)
+	if _, _, err := delegate_capability_grant(context.Background(), nil, again); err == nil {
+		t.Error("Expected an Operator to be unable to delegate")
+	}
+}
+
+func TestStrategicPlanRunsStepByStep(t *testing.T) {
+	intents = make(map[string]*IntentRecord)
+	plans = make(map[string]*PlanRecord)
+	usePolicy(t, GovernancePolicy{Version: "test", Default: PolicyRule{ID: "default", AllowedOpcodes: []VibeOpcode{OpTransform}}})
+	ctx := context.Background()
+
+	agentMu.Lock(); agentKeys["foreman.unity"] = AgentKey{AgentID: "foreman.unity", Role: RoleForeman, Engine: "unity", Secret: "f"}; agentMu.Unlock()
+	plan := StrategicPlan{ID: "plan-1", Title: "Stage the dock", TotalBudget: 60000, AgentID: "foreman.unity", Steps: []PlanStep{
+		{ID: 1, Description: "Move crate", Intent: IntentRig, Opcode: OpTransform, UUID: "Crate_01"},
+		{ID: 2, Description: "Move barrel", Intent: IntentRig, Opcode: OpTransform, UUID: "Barrel_01"},
+	}}
+	if _, _, err := propose_strategic_plan(ctx, nil, plan); err == nil || !strings.HasPrefix(err.Error(), "SIGNATURE_REQUIRED") {
+		t.Fatalf("Expected an unsigned plan to be refused, got %v", err)
+	}
+	plan.Signature = signPlan("f", plan)
+	if _, _, err := propose_strategic_plan(ctx, nil, plan); err != nil {
+		t.Fatalf("Expected plan to be stored, got %v", err)
+	}
+	if _, _, err := run_strategic_plan(ctx, nil, PlanControlArgs{PlanID: "plan-1"}); err == nil {
+		t.Fatal("Expected unapproved plan to refuse to run")
+	}
+	decision := PlanDecisionArgs{PlanID: "plan-1", Approver: "lead", Reason: "looks right"}
+	decision.Signature = humanSign("lead", "approve_plan", decision)
+	if _, _, err := human_approve_plan(ctx, nil, decision); err != nil {
+		t.Fatalf("Approval failed: %v", err)
+	}
+	if _, _, err := run_strategic_plan(ctx, nil, PlanControlArgs{PlanID: "plan-1"}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	p := plans["plan-1"]
+	first := p.Steps[0]
+	if first.Status != "DISPATCHED" || p.Steps[1].Status != "PENDING" {
+		t.Fatalf("Expected only the first step to be dispatched, got %+v", p.Steps)
+	}
+	if rec := intents[first.IntentID]; rec == nil || rec.Status != IntentExecuting || rec.Envelope.PlanHash != p.Hash || rec.Agent.AgentID != "foreman.unity" {
+		t.Fatalf("Expected an executing intent bound to the plan hash and its proposer, got %+v", rec)
+	}
+	stepMint := MintGrantArgs{IntentID: first.IntentID}
+	stepMint.Signature = agentSign("foreman.unity", "mint_capability_grant", stepMint)
+	if _, _, err := mint_capability_grant(ctx, nil, stepMint); err != nil {
+		t.Errorf("Expected a grant for the step intent, got %v", err)
+	}
+
+	advancePlan(WorkResult{WorkOrderID: first.WorkOrderID, Status: "SUCCESS"})
+	if p.Steps[0].Status != "DONE" || p.Steps[1].Status != "DISPATCHED" || intents[first.IntentID].Status != IntentDone {
+		t.Fatalf("Expected step 1 done and step 2 dispatched, got %+v", p.Steps)
+	}
+
+	pause_strategic_plan(ctx, nil, PlanControlArgs{PlanID: "plan-1"})
+	advancePlan(WorkResult{WorkOrderID: p.Steps[1].WorkOrderID, Status: "SUCCESS"})
+	if p.Status != PlanPaused {
+		t.Fatalf("Expected paused plan to stay paused, got %s", p.Status)
+	}
+	resume_strategic_plan(ctx, nil, PlanControlArgs{PlanID: "plan-1"})
+	if p.Status != PlanCompleted {
+		t.Errorf("Expected plan to complete on resume, got %s (%s)", p.Status, p.Reason)
+	}
+
+	// A failed step pauses the plan; abort rolls back the completed steps.
+	plan.ID = "plan-2"
+	plan.Signature = signPlan("f", plan)
+	propose_strategic_plan(ctx, nil, plan)
+	decision = PlanDecisionArgs{PlanID: "plan-2", Approver: "lead", Reason: "ok"}
+	decision.Signature = humanSign("lead", "approve_plan", decision)
+	human_approve_plan(ctx, nil, decision)
+	run_strategic_plan(ctx, nil, PlanControlArgs{PlanID: "plan-2"})
+	p = plans["plan-2"]
+	advancePlan(WorkResult{WorkOrderID: p.Steps[0].WorkOrderID, Status: "SUCCESS"})
+	advancePlan(WorkResult{WorkOrderID: p.Steps[1].WorkOrderID, Status: "FAILURE", Error: "MISSING_OBJECT"})
+	if p.Status != PlanPaused || p.Steps[1].Status != "FAILED" {
+		t.Fatalf("Expected failed step to pause the plan, got %s %+v", p.Status, p.Steps)
+	}
+	// Step 1 reported SUCCESS without leaving an undo record: nothing proves
+	// it can be reversed, so the abort stops for a human.
+	if _, _, err := abort_strategic_plan(ctx, nil, PlanControlArgs{PlanID: "plan-2", Reason: "give up"}); err == nil || !strings.HasPrefix(err.Error(), "HUMAN_REVIEW") {
+		t.Fatalf("Expected an unrecorded step to stop the rollback, got %v", err)
+	}
+	if p.Status != PlanPaused || p.Steps[0].Status != "DONE" {
+		t.Fatalf("Expected the plan paused with the step left DONE, got %s %+v", p.Status, p.Steps)
+	}
+
+	unity, _ := startFakeEngines(t)
+	undoMu.Lock()
+	undoStack = append(undoStack, &UndoRecord{ID: "plan-step-1", Op: "execute_governed_mutation", IntentID: p.Steps[0].IntentID, Steps: []UndoStep{{Engine: "unity", Endpoint: "transform/set", UndoToken: "unity-1"}}})
+	undoMu.Unlock()
+	if _, _, err := abort_strategic_plan(ctx, nil, PlanControlArgs{PlanID: "plan-2", Reason: "give up"}); err != nil {
+		t.Fatalf("Abort failed: %v", err)
+	}
+	if p.Status != PlanAborted || p.Steps[0].Status != "ROLLED_BACK" || unity.count("POST /undo") != 1 {
+		t.Errorf("Expected aborted plan with rolled back steps, got %s %+v", p.Status, p.Steps)
+	}
+}
+
+func TestIntentBudgetBoundsEngineCalls(t *testing.T) {
+	intents = map[string]*IntentRecord{"intent-budget": {
+		ID:        "intent-budget",
+		Envelope:  IntentEnvelope{Intent: IntentRig, Opcode: OpTransform, BudgetMS: 50},
+		Status:    IntentExecuting,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}}
+
+	bctx, finish, err := budgetContext(context.Background(), "intent-budget")
+	if err != nil {
+		t.Fatalf("Expected a budgeted context, got %v", err)
+	}
+	start := time.Now()
+	_, err = sendToEngine(bctx, "blender", "state/get", "GET", nil)
+	finish()
+	if err == nil || !strings.HasPrefix(err.Error(), "BUDGET_EXHAUSTED") {
+		t.Fatalf("Expected engine call to be cut off by the budget, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Expected the deadline to stop retries, took %v", time.Since(start))
+	}
+
+	rec := intents["intent-budget"]
+	if rec.Status != IntentRejected || rec.Reason != "BUDGET_EXHAUSTED" || rec.BudgetUsedMS < 50 {
+		t.Fatalf("Expected intent rejected with its budget charged, got %s (%s) %dms", rec.Status, rec.Reason, rec.BudgetUsedMS)
+	}
+	if _, _, err := budgetContext(context.Background(), "intent-budget"); err == nil {
+		t.Error("Expected an exhausted intent to be refused further engine time")
+	}
+	for _, b := range intentBudgets() {
+		if b["intent_id"] == "intent-budget" {
+			t.Error("Expected terminal intent to drop out of the forensic budget report")
+		}
+	}
+}
+
+func TestEntropyBudgetsArePerActor(t *testing.T) {
+	entropyMu.Lock()
+	entropyBudgets = map[string]*EntropyBudget{"operator.a": {Actor: "operator.a", Limit: 16, RefilledAt: time.Now()}}
+	entropyMu.Unlock()
+
+	if err := chargeEntropy("operator.a", ClassDestructive); err != nil {
+		t.Fatalf("Expected first destructive charge to fit, got %v", err)
+	}
+	if err := chargeEntropy("operator.a", ClassStructural); err != nil {
+		t.Fatalf("Expected structural charge to fit, got %v", err)
+	}
+	if !entropyBudgets["operator.a"].LowNotified {
+		t.Error("Expected a low-budget notice once 80% is spent")
+	}
+	if err := chargeEntropy("operator.a", ClassDestructive); err == nil {
+		t.Fatal("Expected destructive charge to exceed the budget")
+	}
+	if err := chargeEntropy("operator.b", ClassDestructive); err != nil {
+		t.Fatalf("Expected another actor to have its own budget, got %v", err)
+	}
+
+	// Spent entropy trickles back over time.
+	entropyMu.Lock()
+	entropyBudgets["operator.a"].RefilledAt = time.Now().Add(-5 * entropyRefillInterval)
+	entropyMu.Unlock()
+	for _, b := range entropySnapshot() {
+		if b.Actor == "operator.a" && b.Used != 8 {
+			t.Errorf("Expected 5 points replenished (8 used), got %d", b.Used)
+		}
+	}
+
+	if _, _, err := human_grant_entropy(context.Background(), nil, EntropyGrantArgs{Actor: "operator.a", Amount: 50}); err == nil {
+		t.Error("Expected top-up without approver to be refused")
+	}
+	grant := EntropyGrantArgs{Actor: "operator.a", Amount: 50, Limit: 40, Approver: "lead", Reason: "long session"}
+	grant.Signature = humanSign("lead", "grant_entropy", grant)
+	if _, _, err := human_grant_entropy(context.Background(), nil, grant); err != nil {
+		t.Fatalf("Top-up failed: %v", err)
+	}
+	if b := entropyBudgets["operator.a"]; b.Used != 0 || b.Limit != 40 || b.LowNotified {
+		t.Errorf("Expected a fresh 40-point budget, got %+v", b)
+	}
+}
+
+func TestPayloadDeviationChargesGrantHolder(t *testing.T) {
+	unity, blender := startFakeEngines(t)
+	driftMu.Lock(); driftRecords = make(map[string]*DriftRecord); driftMu.Unlock()
+	charged := func(agent string) int { driftMu.Lock(); defer driftMu.Unlock(); if r, ok := driftRecords[agent]; ok { return r.Count }; return 0 }
+
+	// Background traffic is refused but charges nobody, not even the agent
+	// whose transaction happens to be open.
+	txMu.Lock(); saved := activeTransaction; activeTransaction = &VibeTransaction{ID: "tx-bystander", Agent: "operator.bystander"}; txMu.Unlock()
+	defer func() { txMu.Lock(); activeTransaction = saved; txMu.Unlock() }()
+	// The intercept costs Blender trust; give it back for the tests after this one
+	stateMu.Lock(); trust, state := engines["blender"].TrustScore, engines["blender"].State; stateMu.Unlock()
+	defer func() { stateMu.Lock(); engines["blender"].TrustScore, engines["blender"].State = trust, state; stateMu.Unlock() }()
+	if _, err := sendToEngine(context.Background(), "blender", "facts/verify", "POST", map[string]interface{}{"cmd": "os.system"}); err == nil || !strings.HasPrefix(err.Error(), "SECURITY_VIOLATION") { // skip-security-gate
+		t.Fatalf("Expected the engine call refused, got %v", err)
+	}
+	if n := charged("operator.bystander"); n != 0 {
+		t.Errorf("Expected background traffic not to charge the open transaction's agent, got %d", n)
+	}
+
+	mat := SyncMaterialArgs{ObjectID: "Crate_01", Props: map[string]interface{}{"shader": "eval(payload)"}, Grant: testGrant("operator.unity", []string{"unity", "blender"}, []VibeOpcode{OpMaterial}, []string{"Crate_01"})} // skip-security-gate
+	if _, _, err := sync_material(context.Background(), nil, mat); err == nil || !strings.HasPrefix(err.Error(), "SECURITY_VIOLATION") {
+		t.Fatalf("Expected the material edit refused, got %v", err)
+	}
+	if charged("operator.unity") != 1 || charged("operator.bystander") != 0 {
+		t.Errorf("Expected the grant holder charged, got %d for it and %d for the bystander", charged("operator.unity"), charged("operator.bystander"))
+	}
+	if unity.count("POST /material/update") != 0 || blender.count("POST /material/update") != 0 {
+		t.Error("Expected the refused edit never to reach an engine")
+	}
+}
+
+func TestDriftBudgetLocksAgent(t *testing.T) {
+	agentMu.Lock()
+	agentKeys = map[string]AgentKey{"operator.drift": {AgentID: "operator.drift", Role: RoleOperator, Engine: "blender", Secret: "d-secret"}}
+	agentMu.Unlock()
+	driftMu.Lock()
+	driftRecords = map[string]*DriftRecord{"operator.drift": {AgentID: "operator.drift", Allowed: 2}}
+	driftMu.Unlock()
+
+	noteDeviation("operator.drift", fmt.Errorf("ENGINE_ERROR | connection refused"))
+	if driftRecords["operator.drift"].Count != 0 {
+		t.Fatal("Expected engine failures not to count as protocol drift")
+	}
+	noteDeviation("operator.drift", fmt.Errorf("LOG_INGESTION_REQUIRED: Intent must be grounded in latest forensic logs"))
+	noteDeviation("operator.drift", fmt.Errorf("INTENT_MISMATCH: Opcode 3 not permitted"))
+	token := encodeGrant(CapabilityGrant{ID: "g", Holder: "operator.drift", UUIDs: []string{"Rock_01"}, Opcodes: []VibeOpcode{OpTransform}, Engines: []string{"blender"}, ExpiresAt: time.Now().Add(time.Minute)})
+	if _, err := checkGrant(token, []string{"blender"}, OpTransform, []string{"Rock_01"}); err != nil {
+		t.Fatalf("Expected agent within budget to keep its grant, got %v", err)
+	}
+
+	noteDeviation("operator.drift", fmt.Errorf("SECURITY_VIOLATION: os.system"))
+	if r := driftRecords["operator.drift"]; !r.Locked || len(r.Recent) != 3 || r.Recent[2].Kind != DriftSecurityIntercept {
+		t.Fatalf("Expected agent locked after third deviation, got %+v", r)
+	}
+	if _, err := checkGrant(token, []string{"blender"}, OpTransform, []string{"Rock_01"}); err == nil || !strings.HasPrefix(err.Error(), "DRIFT_LOCKED") {
+		t.Fatalf("Expected locked agent's grant to be refused, got %v", err)
+	}
+
+	fix := DriftRemediationArgs{AgentID: "operator.drift", Approver: "lead", Reason: "retrained prompt"}
+	fix.Signature = humanSign("lead", "remediate_drift", fix)
+	if _, _, err := human_remediate_drift(context.Background(), nil, fix); err != nil {
+		t.Fatalf("Remediation failed: %v", err)
+	}
+	if _, err := checkGrant(token, []string{"blender"}, OpTransform, []string{"Rock_01"}); err != nil {
+		t.Errorf("Expected remediation to restore mutation rights, got %v", err)
+	}
+}
+
+func TestFactLedgerTracksWitnessesAndDecay(t *testing.T) {
+	ctx := context.Background()
+	factMu.Lock(); facts = map[string]*Fact{}; factMu.Unlock()
+	refereeMu.Lock(); refereeHashes["unity"], refereeHashes["blender"] = "scene-h1", "scene-h1"; refereeMu.Unlock()
+	testGrant("operator.facts", nil, nil, nil); testGrant("operator.unity", nil, nil, nil)
+	signed := func(a RecordFactArgs) RecordFactArgs { a.AgentID = "operator.facts"; a.Signature = agentSign("operator.facts", "record_fact", a); return a }
+
+	if _, _, err := record_fact(ctx, nil, signed(RecordFactArgs{Subject: "Crate_01", Claim: "material fixed"})); err == nil {
+		t.Fatal("Expected a fact without evidence to be refused")
+	}
+	journalOperation(map[string]interface{}{"type": "mutation", "id": "Crate_01", "endpoint": "material/update"})
+	walMu.Lock(); fixHash := lastWalHash; walMu.Unlock()
+
+	claim := RecordFactArgs{Subject: "Crate_01", Claim: "material fixed", Evidence: []FactEvidence{{Source: "wal", Hash: fixHash}}}
+	if _, _, err := record_fact(ctx, nil, claim); err == nil || !strings.HasPrefix(err.Error(), "SIGNATURE_REQUIRED") {
+		t.Fatalf("Expected an unsigned fact to be refused, got %v", err)
+	}
+	forged := signed(claim); forged.AgentID = "operator.unity"
+	if _, _, err := record_fact(ctx, nil, forged); err == nil || !strings.HasPrefix(err.Error(), "SIGNATURE_INVALID") {
+		t.Fatalf("Expected a fact attributed to another agent to be refused, got %v", err)
+	}
+	journalOperation(map[string]interface{}{"type": "mutation", "id": "Barrel_01"})
+	walMu.Lock(); barrelHash := lastWalHash; walMu.Unlock()
+	if _, _, err := record_fact(ctx, nil, signed(RecordFactArgs{Subject: "Crate_01", Claim: "material fixed", Evidence: []FactEvidence{{Source: "wal", Hash: barrelHash}}})); err == nil || !strings.Contains(err.Error(), "does not cover Crate_01") {
+		t.Fatalf("Expected a WAL entry about another object to be refused as evidence, got %v", err)
+	}
+	journalOperation(map[string]interface{}{"type": "batch", "phase": PhaseFinal, "entries": []WalEntry{{Scope: WalScope{UUIDs: []string{"Barrel_01"}}}, {Scope: WalScope{UUIDs: []string{"Crate_01"}}}}})
+	walMu.Lock(); batchHash := lastWalHash; walMu.Unlock()
+	if _, _, err := record_fact(ctx, nil, signed(RecordFactArgs{Subject: "Crate_01", Claim: "batched", Evidence: []FactEvidence{{Source: "wal", Hash: batchHash}}})); err != nil {
+		t.Fatalf("Expected a WAL entry whose scope names the subject to be accepted, got %v", err)
+	}
+	factMu.Lock(); facts = map[string]*Fact{}; factMu.Unlock()
+	journalOperation(map[string]interface{}{"type": "mutation", "id": "Crate_01", "endpoint": "material/update"})
+	walMu.Lock(); claim.Evidence = []FactEvidence{{Source: "wal", Hash: lastWalHash}}; walMu.Unlock()
+
+	res, _, err := record_fact(ctx, nil, signed(claim))
+	if err != nil {
+		t.Fatalf("Expected fact backed by a WAL entry, got %v", err)
+	}
+	var wrapped struct{ Result Fact `json:"result"` }
+	json.Unmarshal([]byte(res.Content[0].(*mcp.TextContent).Text), &wrapped)
+	f := wrapped.Result
+	if f.Status != FactUnconfirmed || f.Confidence != 0.5 {
+		t.Fatalf("Expected single-witness fact to be UNCONFIRMED at 0.5, got %s %.2f", f.Status, f.Confidence)
+	}
+
+	observe_fact(ctx, nil, ObserveFactArgs{FactID: f.ID, Evidence: []FactEvidence{{Source: "bridge", Hash: "scene-h1"}}})
+	if facts[f.ID].Status != FactConfirmed {
+		t.Fatalf("Expected second independent witness to confirm the fact, got %s", facts[f.ID].Status)
+	}
+
+	for i := 0; i < factHalfLifeOps; i++ {
+		journalOperation(map[string]interface{}{"type": "mutation", "id": "Barrel_01"})
+	}
+	revalidateFacts(ctx)
+	if c := facts[f.ID].Confidence; math.Abs(c-0.5) > 1e-9 {
+		t.Errorf("Expected confidence halved after %d unobserved operations, got %.3f", factHalfLifeOps, c)
+	}
+
+	journalOperation(map[string]interface{}{"type": "mutation", "id": "Crate_01", "endpoint": "material/update"})
+	revalidateFacts(ctx)
+	if facts[f.ID].Status != FactInvalidated || facts[f.ID].Confidence != 0 {
+		t.Errorf("Expected fact invalidated once its subject changed, got %s", facts[f.ID].Status)
+	}
+
+	// WAL rotation: evidence in wal.jsonl.old still holds; evidence rotated
+	// out of both files decays instead of being invalidated.
+	journalOperation(map[string]interface{}{"type": "mutation", "id": "Lamp_01"})
+	walMu.Lock(); lampHash := lastWalHash; walMu.Unlock()
+	res, _, err = record_fact(ctx, nil, signed(RecordFactArgs{Subject: "Lamp_01", Claim: "lit", Evidence: []FactEvidence{{Source: "wal", Hash: lampHash}}}))
+	if err != nil {
+		t.Fatalf("Expected fact backed by a WAL entry, got %v", err)
+	}
+	json.Unmarshal([]byte(res.Content[0].(*mcp.TextContent).Text), &wrapped)
+	lamp := wrapped.Result.ID
+	walMu.Lock(); walIdx.rotate(); walMu.Unlock()
+	journalOperation(map[string]interface{}{"type": "mutation", "id": "Barrel_01"})
+	revalidateFacts(ctx)
+	if facts[lamp].Status != FactUnconfirmed || facts[lamp].Confidence == 0 {
+		t.Fatalf("Expected evidence in the rotated file to hold, got %s %.3f (%s)", facts[lamp].Status, facts[lamp].Confidence, facts[lamp].Reason)
+	}
+	walMu.Lock(); walIdx.rotate(); walMu.Unlock()
+	revalidateFacts(ctx)
+	if facts[lamp].Status != FactUnconfirmed || facts[lamp].Confidence != 0 {
+		t.Fatalf("Expected rotated-out evidence to decay, got %s %.3f (%s)", facts[lamp].Status, facts[lamp].Confidence, facts[lamp].Reason)
+	}
+	if _, _, err := observe_fact(ctx, nil, ObserveFactArgs{FactID: lamp, Evidence: []FactEvidence{{Source: "wal", Hash: lampHash}}}); err == nil {
+		t.Error("Expected rotated-out evidence to be refused as a new observation")
+	}
+	journalOperation(map[string]interface{}{"type": "mutation", "id": "Lamp_01"})
+	revalidateFacts(ctx)
+	if facts[lamp].Status != FactInvalidated {
+		t.Errorf("Expected a later change to invalidate the fact, got %s", facts[lamp].Status)
+	}
+}
+
+func TestLogIngestionRequiresRealWindow(t *testing.T) {
+	ctx := context.Background()
+	journalOperation(map[string]interface{}{"type": "mutation", "id": "Crate_01"})
+	w, logHash := readWindowAsAgent(t)
+	testGrant("operator.reader", nil, nil, nil); testGrant("operator.other", nil, nil, nil)
+	if _, _, err := ingest_forensic_logs(ctx, nil, IngestLogsArgs{LogHash: logHash, WalHead: w.WalHead, EventCount: w.EventCount}); err == nil || !strings.HasPrefix(err.Error(), "SIGNATURE_REQUIRED") {
+		t.Fatalf("Expected an unsigned ingestion to be refused, got %v", err)
+	}
+	if _, _, err := ingest_forensic_logs(ctx, nil, ingestAs("operator.reader", w, "whatever-the-ai-says")); err == nil || strings.Contains(err.Error(), logHash) {
+		t.Fatalf("Expected a made-up log hash to be refused without revealing the real one, got %v", err)
+	}
+
+	// Audit-only churn after reading the window does not invalidate it.
+	journalOperation(map[string]interface{}{"type": "engine_call", "target": "unity"})
+	dispatchVibeEvent(LevelInfo, "noise", "", "", nil)
+	if _, _, err := ingest_forensic_logs(ctx, nil, ingestAs("operator.reader", w, logHash)); err != nil {
+		t.Fatalf("Expected anchored window to ingest, got %v", err)
+	}
+	env := IntentEnvelope{AgentID: "operator.reader", BasedOnHashes: map[string]string{"log": logHash}}
+	if err := checkLogIngestion(env); err != nil {
+		t.Fatalf("Expected intent grounded in ingested window to pass, got %v", err)
+	}
+	if err := checkLogIngestion(IntentEnvelope{AgentID: "operator.other", BasedOnHashes: env.BasedOnHashes}); err == nil || !strings.HasPrefix(err.Error(), "LOG_INGESTION_REQUIRED") {
+		t.Errorf("Expected another agent's reading not to ground this agent's intent, got %v", err)
+	}
+
+	// A new state change moves the WAL head past what was ingested.
+	journalOperation(map[string]interface{}{"type": "mutation", "id": "Barrel_01"})
+	if err := checkLogIngestion(env); err == nil || !strings.Contains(err.Error(), "WAL head") {
+		t.Errorf("Expected stale ingestion to be refused, got %v", err)
+	}
+	if _, _, err := ingest_forensic_logs(ctx, nil, ingestAs("operator.reader", w, logHash)); err == nil {
+		t.Error("Expected re-ingesting an outdated window to be refused")
+	}
+}
+
+func TestLockLeasesAreOwnedAndHierarchical(t *testing.T) {
+	lockMu.Lock(); lockTable = map[string]*VibeLock{}; lockMu.Unlock()
+
+	root, err := acquireLock("Dock_Collection", LockHumanActive, ActorHuman, "artist", "", []string{"Crate_01", "Barrel_01"}, time.Minute)
+	if err != nil {
+		t.Fatalf("Expected lock on collection, got %v", err)
+	}
+	if err := checkHumanLock("Crate_01"); err == nil {
+		t.Error("Expected collection lock to cover its descendants")
+	}
+	if _, err := acquireLock("Barrel_01", LockAISpeculative, ActorAI, "operator.blender", "", nil, time.Minute); err == nil {
+		t.Error("Expected another owner to be refused a covered descendant")
+	}
+
+	if _, err := acquireLock("Dock_Collection", LockAISpeculative, ActorAI, "artist", "", nil, time.Minute); err == nil {
+		t.Error("Expected a refresh without the lease to be refused")
+	}
+	if root.Type != LockHumanActive || root.Renewals != 0 {
+		t.Fatalf("Expected the refused refresh to leave the lock alone, got %+v", root)
+	}
+	if _, err := renewLock("Dock_Collection", "operator.blender", root.Lease, time.Minute); err == nil {
+		t.Error("Expected renewal by a non-owner to fail")
+	}
+	if _, err := renewLock("Dock_Collection", "artist", root.Lease, 2*time.Minute); err != nil || root.Renewals != 1 {
+		t.Errorf("Expected owner to renew its lease, got %v", err)
+	}
+
+	if err := releaseLock(ReleaseLockArgs{UUID: "Dock_Collection", Owner: "operator.blender"}); err == nil {
+		t.Error("Expected release by a non-owner to be refused")
+	}
+	if err := releaseLock(ReleaseLockArgs{UUID: "Dock_Collection", Owner: "operator.blender", Force: true}); err == nil {
+		t.Error("Expected forced release without approver to be refused")
+	}
+	if err := releaseLock(ReleaseLockArgs{UUID: "Dock_Collection", Owner: "operator.blender", Force: true, Approver: "lead", Reason: "artist went home"}); err != nil {
+		t.Fatalf("Expected approved forced release, got %v", err)
+	}
+	if err := checkHumanLock("Crate_01"); err != nil {
+		t.Errorf("Expected descendants free after release, got %v", err)
+	}
+
+	// apply_lock derives what a lock covers from the mirrored hierarchy, and
+	// mutations by anyone but the owner are refused while it is held.
+	hierarchyMu.Lock()
+	hierarchies["unity"] = &hierarchyGraph{Parent: map[string]string{"Dock_Collection": "", "Crate_01": "Dock_Collection", "Lid_01": "Crate_01"}, Refreshed: time.Now()}
+	hierarchies["blender"] = &hierarchyGraph{Parent: map[string]string{"Dock_Collection": ""}, Refreshed: time.Now()}
+	hierarchyMu.Unlock()
+	defer func() { hierarchyMu.Lock(); delete(hierarchies, "unity"); delete(hierarchies, "blender"); hierarchyMu.Unlock() }()
+	if _, _, err := apply_lock(context.Background(), nil, ApplyLockArgs{UUID: "Dock_Collection", LockType: LockHumanActive, Owner: "operator.unity"}); err == nil || !strings.HasPrefix(err.Error(), "LOCK_TYPE_DENIED") {
+		t.Fatalf("Expected an agent's HUMAN_ACTIVE lock to be refused, got %v", err)
+	}
+	if _, _, err := apply_lock(context.Background(), nil, ApplyLockArgs{UUID: "Dock_Collection", LockType: LockAISpeculative, Actor: ActorHuman, Owner: "operator.unity"}); err == nil || !strings.HasPrefix(err.Error(), "LOCK_TYPE_DENIED") {
+		t.Fatalf("Expected an agent posing as a human to be refused, got %v", err)
+	}
+	if _, _, err := apply_lock(context.Background(), nil, ApplyLockArgs{UUID: "Dock_Collection", LockType: LockAISpeculative, Owner: "operator.unity"}); err != nil {
+		t.Fatalf("Expected a lock on the collection, got %v", err)
+	}
+	if err := checkLocks("operator.blender", "Lid_01"); err == nil || !strings.HasPrefix(err.Error(), "LOCK_HELD") {
+		t.Errorf("Expected a leased descendant to refuse another agent's mutation, got %v", err)
+	}
+	if err := checkLocks("operator.unity", "Lid_01"); err != nil {
+		t.Errorf("Expected the lease owner to mutate its descendants, got %v", err)
+	}
+
+	short, _ := acquireLock("Rock_01", LockAISpeculative, ActorAI, "operator.blender", "", nil, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, err := acquireLock("Rock_01", LockHumanActive, ActorHuman, "artist", "", nil, time.Minute); err != nil {
+		t.Errorf("Expected an expired lease (%s) not to block, got %v", short.ExpiresAt, err)
+	}
+}
+
+func TestConflictResolverAppliesPolicyMatrix(t *testing.T) {
+	conflictMu.Lock(); conflictClaims = nil; conflictMu.Unlock()
+	lockMu.Lock(); lockTable = map[string]*VibeLock{}; lockMu.Unlock()
+	ctx := context.Background()
+	claim := func(id string, class IntentClass, props ...string) *conflictClaim {
+		return newConflictClaim(id, "operator.blender", class, []string{"Crate_01"}, props, nil)
+	}
+	live := func() int { conflictMu.Lock(); defer conflictMu.Unlock(); return len(conflictClaims) }
+
+	if err := admitMutation(ctx, claim("c1", ClassCosmetic, "color")); err != nil {
+		t.Fatalf("Expected first claim admitted, got %v", err)
+	}
+	if err := admitMutation(ctx, claim("c2", ClassCosmetic, "position")); err != nil || live() != 2 {
+		t.Errorf("Expected disjoint cosmetic claims to merge, got %v with %d live", err, live())
+	}
+	c3 := claim("c3", ClassCosmetic, "color")
+	if err := admitMutation(ctx, c3); err != nil || live() != 2 {
+		t.Errorf("Expected higher ID to override the older color write, got %v with %d live", err, live())
+	}
+	if meta, losers := resolveConflict(c3, claim("c4", ClassCosmetic, "color")); meta.Resolution != ResolutionOverride || losers[0] != c3 {
+		t.Errorf("Expected the newer cosmetic writer to win, got %+v", meta)
+	}
+
+	if err := admitMutation(ctx, claim("s1", ClassStructural, "parent")); err != nil || live() != 1 {
+		t.Errorf("Expected structural to roll back the cosmetic claims, got %v with %d live", err, live())
+	}
+	if err := admitMutation(ctx, claim("s2", ClassStructural, "parent")); err == nil || !strings.HasPrefix(err.Error(), "CONFLICT_QUARANTINE") || live() != 0 {
+		t.Errorf("Expected competing structural claims quarantined, got %v with %d live", err, live())
+	}
+	if meta, losers := resolveConflict(claim("c5", ClassCosmetic, "color"), claim("d1", ClassDestructive)); meta.Reason != ConflictDestructive || len(losers) != 2 {
+		t.Errorf("Expected destructive dominance to quarantine both, got %+v", meta)
+	}
+
+	acquireLock("Crate_01", LockHumanActive, ActorHuman, "artist", "", nil, time.Minute)
+	if err := admitMutation(ctx, claim("c6", ClassCosmetic, "color")); err == nil || !strings.HasPrefix(err.Error(), "WAIT_HUMAN_LOCK") {
+		t.Errorf("Expected AI claim to wait for the human, got %v", err)
+	}
+	lockMu.Lock(); lockTable = map[string]*VibeLock{}; lockMu.Unlock()
+}
+
+func TestSyncMutationsStakeConflictClaims(t *testing.T) {
+	conflictMu.Lock(); conflictClaims = nil; conflictMu.Unlock()
+	lockMu.Lock(); lockTable = map[string]*VibeLock{}; lockMu.Unlock()
+	bufferMu.Lock(); intentBuffer = make(map[string]*bufferedIntent); bufferMu.Unlock()
+	startFakeEngines(t)
+	ctx := context.Background()
+	move := func(holder string, x float64) SyncTransformArgs {
+		return SyncTransformArgs{ObjectID: "Crate_01", Position: []float64{x, 0, 0}, Grant: testGrant(holder, []string{"unity", "blender"}, []VibeOpcode{OpTransform}, []string{"Crate_01"})}
+	}
+	live := func() int { conflictMu.Lock(); defer conflictMu.Unlock(); return len(conflictClaims) }
+
+	if _, _, err := sync_transform(ctx, nil, move("operator.unity", 1)); err != nil {
+		t.Fatalf("Expected first transform buffered, got %v", err)
+	}
+	sync_transform(ctx, nil, move("operator.unity", 2))
+	if live() != 1 {
+		t.Errorf("Expected the same claimant's transforms to share one claim, got %d live", live())
+	}
+	if _, _, err := sync_transform(ctx, nil, move("operator.blender", 3)); err != nil {
+		t.Fatalf("Expected the newer transform to win, got %v", err)
+	}
+	bufferMu.Lock()
+	b := intentBuffer["Crate_01"]
+	ops, winner := len(b.Ops), b.Claims[0].Holder
+	bufferMu.Unlock()
+	if ops != 1 || winner != "operator.blender" || live() != 1 {
+		t.Errorf("Expected the overridden transform withdrawn unsent, got %d ops from %s with %d live", ops, winner, live())
+	}
+
+	mat := SyncMaterialArgs{ObjectID: "Crate_01", Props: map[string]interface{}{"color": "red"}, Grant: testGrant("operator.unity", []string{"unity", "blender"}, []VibeOpcode{OpMaterial}, []string{"Crate_01"})}
+	if _, _, err := sync_material(ctx, nil, mat); err != nil || live() != 2 {
+		t.Errorf("Expected a material edit to merge with the pending transform, got %v with %d live", err, live())
+	}
+	bufferMu.Lock(); intentBuffer = make(map[string]*bufferedIntent); bufferMu.Unlock()
+	conflictMu.Lock(); conflictClaims = nil; conflictMu.Unlock()
+}
+
+func TestIntentBufferCoalescesAndFinalizesBatches(t *testing.T) {
+	coalesceRun.Lock(); defer coalesceRun.Unlock() // Flush by hand, not from the background loop
+	bufferMu.Lock(); intentBuffer = make(map[string]*bufferedIntent); bufferMu.Unlock()
+	move := func(pos, rot []float64) map[string]interface{} {
+		return map[string]interface{}{"id": "Crate_01", "transform": map[string]interface{}{"pos": pos, "rot": rot}}
+	}
+	bufferSpeculativeIntent("Crate_01", "sync_transform", "transform/set", move([]float64{0, 0, 0}, []float64{0, 90, 0}), ClassCosmetic, nil)
+	bufferSpeculativeIntent("Crate_01", "sync_transform", "transform/set", move([]float64{1, 2, 3}, nil), ClassCosmetic, nil)
+	bufferSpeculativeIntent("Crate_02", "sync_transform", "transform/set", map[string]interface{}{"id": "Crate_02"}, ClassCosmetic, nil)
+	if ok := bufferSpeculativeIntent("Crate_03", "execute_governed_mutation", "object/delete", nil, ClassDestructive, nil); ok {
+		t.Error("Expected destructive mutations to bypass the buffer")
+	}
+
+	bufferMu.Lock()
+	b := intentBuffer["Crate_01"]
+	bufferMu.Unlock()
+	if len(b.Ops) != 1 || b.Merged != 1 {
+		t.Fatalf("Expected successive transforms to merge into one op, got %d ops, %d merged", len(b.Ops), b.Merged)
+	}
+	tr := b.Ops[0].Payload["transform"].(map[string]interface{})
+	if fmt.Sprint(tr["pos"]) != "[1 2 3]" || fmt.Sprint(tr["rot"]) != "[0 90 0]" {
+		t.Errorf("Expected latest position and retained rotation, got %v", tr)
+	}
+
+	if entries := flushIntentBuffer(); entries != nil {
+		t.Error("Expected nothing to flush inside the batch window")
+	}
+	time.Sleep(classProfiles[ClassCosmetic].BatchWindow)
+	entries := flushIntentBuffer()
+	if len(entries) != 2 {
+		t.Fatalf("Expected both objects in one batch, got %d entries", len(entries))
+	}
+	for _, e := range entries {
+		// No engine is listening, so the batch cannot be applied or verified
+		if e.Phase != PhaseRolledBack {
+			t.Errorf("Expected unverifiable batch to roll back, got %s for %v", e.Phase, e.Scope.UUIDs)
+		}
+	}
+}
+
+func TestBatchAppliesOnceAndSettlesItsIntents(t *testing.T) {
+	coalesceRun.Lock(); defer coalesceRun.Unlock() // Flush by hand, not from the background loop
+	conflictMu.Lock(); conflictClaims = nil; conflictMu.Unlock()
+	bufferMu.Lock(); intentBuffer = make(map[string]*bufferedIntent); bufferMu.Unlock()
+	ctx := context.Background()
+	unity, blender := startFakeEngines(t)
+	for _, e := range []*fakeEngine{unity, blender} {
+		e.handle = func(path string, body map[string]interface{}) map[string]interface{} {
+			if path == "/state/get" { return map[string]interface{}{"hash": "scene-1"} }
+			return nil
+		}
+	}
+	submit := func(obj string) string {
+		id := uuid.New().String()
+		txMu.Lock(); rec := newIntentRecord(id, IntentEnvelope{Scope: []string{obj}}); rec.Status = IntentApproved; intents[id] = rec; txMu.Unlock()
+		c := newConflictClaim(id, "operator.unity", ClassCosmetic, []string{obj}, []string{"transform"}, nil)
+		if err := admitMutation(ctx, c); err != nil { t.Fatalf("Expected claim admitted, got %v", err) }
+		bufferSpeculativeIntent(obj, "sync_transform", "transform/set", map[string]interface{}{"id": obj}, ClassCosmetic, c)
+		return id
+	}
+
+	submit("Crate_01"); submit("Crate_02")
+	time.Sleep(classProfiles[ClassCosmetic].BatchWindow)
+	// A transaction that happens to be open does not absorb the batch
+	undoMu.Lock(); undoStack, pendingUndo = nil, map[string]*UndoRecord{}; undoMu.Unlock()
+	txMu.Lock(); saved := activeTransaction; activeTransaction = &VibeTransaction{ID: "tx-bystander", IntentID: "intent-bystander", Agent: "operator.bystander"}; txMu.Unlock()
+	entries := flushIntentBuffer()
+	txMu.Lock(); activeTransaction = saved; txMu.Unlock()
+	if len(entries) != 2 || entries[0].Phase != PhaseFinal || entries[1].Phase != PhaseFinal {
+		t.Fatalf("Expected both entries promoted to FINAL, got %+v", entries)
+	}
+	undoMu.Lock(); pending, top := len(pendingUndo), topRecord(undoStack); undoMu.Unlock()
+	if pending != 0 || top == nil || top.Op != "batch" || top.TransactionID != "" || top.IntentID != "" {
+		t.Errorf("Expected the batch sealed as its own record owned by no single intent, got %d pending and %+v", pending, top)
+	}
+	if unity.count("POST /batch/apply") != 1 || blender.count("POST /batch/apply") != 1 {
+		t.Errorf("Expected one batch/apply per engine, got %d and %d", unity.count("POST /batch/apply"), blender.count("POST /batch/apply"))
+	}
+	if e := lastWalEntry(t); e["type"] != "batch" || e["phase"] != string(PhaseFinal) || len(e["intents"].([]interface{})) != 2 {
+		t.Errorf("Expected the batch journaled with its intents, got %v", e)
+	}
+
+	blender.handle = func(path string, body map[string]interface{}) map[string]interface{} {
+		if path == "/batch/apply" { return map[string]interface{}{"error": "busy"} }
+		return nil
+	}
+	id := submit("Crate_03")
+	time.Sleep(classProfiles[ClassCosmetic].BatchWindow)
+	if entries := flushIntentBuffer(); len(entries) != 1 || entries[0].Phase != PhaseRolledBack {
+		t.Fatalf("Expected refused batch rolled back, got %+v", entries)
+	}
+	txMu.Lock(); status := intents[id].Status; txMu.Unlock()
+	if status != IntentRejected {
+		t.Errorf("Expected the submitting intent rejected with its batch, got %s", status)
+	}
+	conflictMu.Lock(); conflictClaims = nil; conflictMu.Unlock()
+}
+
+func TestPerimeterLockBlocksStructuralChanges(t *testing.T) {
+	lockMu.Lock(); lockTable = map[string]*VibeLock{}; heldWorkOrders = nil; lockMu.Unlock()
+	if _, _, err := perimeter_lock(context.Background(), nil, PerimeterLockArgs{Locked: true}); err == nil || !strings.HasPrefix(err.Error(), "APPROVER_IDENTITY_REQUIRED") {
+		t.Fatalf("Expected an unsigned perimeter to be refused, got %v", err)
+	}
+	if _, _, err := apply_lock(context.Background(), nil, ApplyLockArgs{UUID: perimeterKey, LockType: LockPerimeter, Owner: "operator.unity"}); err == nil || !strings.HasPrefix(err.Error(), "LOCK_TYPE_DENIED") {
+		t.Fatalf("Expected apply_lock not to take the perimeter, got %v", err)
+	}
+	res, _, err := perimeter_lock(context.Background(), nil, humanPerimeter(PerimeterLockArgs{Locked: true}))
+	if err != nil {
+		t.Fatalf("Expected the perimeter locked, got %v", err)
+	}
+	var locked struct{ Result struct{ Lease string `json:"lease"` } `json:"result"` }
+	json.Unmarshal([]byte(res.Content[0].(*mcp.TextContent).Text), &locked)
+	lease := locked.Result.Lease
+	if _, _, err := perimeter_lock(context.Background(), nil, humanPerimeter(PerimeterLockArgs{Locked: true})); err == nil || !strings.HasPrefix(err.Error(), "LOCK_HELD") {
+		t.Errorf("Expected a second perimeter without the lease to be refused, got %v", err)
+	}
+	before := perimeterLock().ExpiresAt
+	if _, _, err := perimeter_lock(context.Background(), nil, humanPerimeter(PerimeterLockArgs{Locked: true, Lease: lease, TTLSeconds: 300})); err != nil || !perimeterLock().ExpiresAt.After(before) {
+		t.Errorf("Expected the holder to renew the perimeter, got %v", err)
+	}
+
+	if err := checkPerimeter(ClassCosmetic, "transform/set"); err != nil {
+		t.Errorf("Expected cosmetic edits to continue inside the perimeter, got %v", err)
+	}
+	if err := checkPerimeter(ClassStructural, "hierarchy/reparent"); err == nil || !strings.HasPrefix(err.Error(), "PERIMETER_LOCKED") {
+		t.Errorf("Expected structural change refused, got %v", err)
+	}
+
+	order := WorkOrder{ID: "perimeter-test", Opcode: OpModifier, UUID: "Crate_01"}
+	if _, _, err := dispatch_work_order(context.Background(), nil, order); err == nil || !strings.HasPrefix(err.Error(), "CAPABILITY_REQUIRED") {
+		t.Errorf("Expected an ungranted work order to be refused, got %v", err)
+	}
+	order.Grant = testGrant("operator.blender", []string{"unity"}, []VibeOpcode{OpModifier}, []string{"Crate_01"})
+	if _, _, err := dispatch_work_order(context.Background(), nil, order); err == nil || !strings.HasPrefix(err.Error(), "CAPABILITY_DENIED") {
+		t.Errorf("Expected a grant for the wrong engine to be refused, got %v", err)
+	}
+	order.Grant = testGrant("operator.blender", []string{"blender"}, []VibeOpcode{OpModifier}, []string{"Crate_01"})
+	res, _, _ = dispatch_work_order(context.Background(), nil, order)
+	if text := res.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "HELD_BY_PERIMETER") {
+		t.Errorf("Expected structural work order to be held, got %s", text)
+	}
+	path := filepath.Join(QueueDir, "blender/inbox", "order_perimeter-test.json")
+	defer os.Remove(path)
+	if _, err := os.Stat(path); err == nil {
+		t.Fatal("Expected held order not to reach the inbox")
+	}
+
+	// A restart keeps both the perimeter and the orders held behind it
+	lockMu.Lock(); lockTable = map[string]*VibeLock{}; heldWorkOrders = nil; lockMu.Unlock()
+	loadPerimeter()
+	lockMu.Lock(); held := len(heldWorkOrders); lockMu.Unlock()
+	if perimeterLock() == nil || held != 1 {
+		t.Errorf("Expected the perimeter and its held order restored, got %d held", held)
+	}
+
+	if _, _, err := perimeter_lock(context.Background(), nil, PerimeterLockArgs{Locked: false}); err == nil || !strings.HasPrefix(err.Error(), "LOCK_NOT_OWNER") {
+		t.Errorf("Expected a release without the lease to be refused, got %v", err)
+	}
+	perimeter_lock(context.Background(), nil, PerimeterLockArgs{Locked: false, Lease: lease})
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected held order dispatched on release, got %v", err)
+	}
+
+	// A forced release is persisted too, so a restart does not bring it back
+	perimeter_lock(context.Background(), nil, humanPerimeter(PerimeterLockArgs{Locked: true}))
+	force := ReleaseLockArgs{UUID: perimeterKey, Owner: "lead", Force: true, Approver: "lead", Reason: "stuck creation run"}
+	force.Signature = humanSign("lead", "force_release_lock", force)
+	if _, _, err := release_lock(context.Background(), nil, force); err != nil {
+		t.Fatalf("Expected the perimeter force-released, got %v", err)
+	}
+	lockMu.Lock(); lockTable = map[string]*VibeLock{}; lockMu.Unlock()
+	loadPerimeter()
+	if perimeterLock() != nil {
+		t.Error("Expected a force-released perimeter to stay released after a restart")
+	}
+}
+
+func TestEditorActivityTakesHumanLocks(t *testing.T) {
+	lockMu.Lock(); lockTable = map[string]*VibeLock{}; lockMu.Unlock()
+	conflictMu.Lock(); conflictClaims = nil; conflictMu.Unlock()
+	bufferMu.Lock(); intentBuffer = make(map[string]*bufferedIntent); bufferMu.Unlock()
+	ctx := context.Background()
+
+	id := uuid.New().String()
+	txMu.Lock(); rec := newIntentRecord(id, IntentEnvelope{Scope: []string{"Crate_01"}}); rec.Status = IntentApproved; intents[id] = rec; txMu.Unlock()
+	if err := admitMutation(ctx, newConflictClaim(id, "operator.blender", ClassCosmetic, []string{"Crate_01"}, []string{"color"}, nil)); err != nil {
+		t.Fatalf("Expected AI claim admitted, got %v", err)
+	}
+	bufferSpeculativeIntent("Crate_01", "sync_transform", "transform/set", map[string]interface{}{"id": "Crate_01"}, ClassCosmetic, nil)
+	acquireLock("Crate_01", LockAISpeculative, ActorAI, "operator.blender", "", nil, time.Minute)
+
+	// Another human's lock is reported, and the AI lease under it is not preempted for nothing
+	acquireLock("Barrel_01", LockAISpeculative, ActorAI, "operator.blender", "", nil, time.Minute)
+	lockMu.Lock(); lockTable["Barrel_01_Root"] = &VibeLock{UUID: "Barrel_01_Root", Type: LockHumanActive, Actor: ActorHuman, Owner: "human:unity:rigger", Covers: []string{"Barrel_01"}, ExpiresAt: time.Now().Add(time.Minute)}; lockMu.Unlock()
+	if _, _, err := acquireHumanLock("Barrel_01", "human:blender:artist", time.Minute); err == nil || !strings.HasPrefix(err.Error(), "LOCK_HELD") {
+		t.Errorf("Expected another human's lock to be reported, got %v", err)
+	}
+	lockMu.Lock(); _, kept := lockTable["Barrel_01"]; lockMu.Unlock()
+	if !kept {
+		t.Error("Expected the AI lease kept when the human lock could not be taken")
+	}
+
+	drag := EditorActivity{Engine: "blender", Kind: "gizmo_drag", UUIDs: []string{"Crate_01"}, User: "artist"}
+	if _, err := reportEditorActivity(ctx, drag); err != nil {
+		t.Fatalf("Expected activity accepted, got %v", err)
+	}
+	if err := checkHumanLock("Crate_01"); err == nil {
+		t.Error("Expected the drag to take a HUMAN_ACTIVE lock, preempting the AI lease")
+	}
+	txMu.Lock(); status := intents[id].Status; txMu.Unlock()
+	if status != IntentWaitHuman {
+		t.Errorf("Expected provisional AI intent moved to WAIT_HUMAN_LOCK, got %s", status)
+	}
+	bufferMu.Lock(); _, pending := intentBuffer["Crate_01"]; bufferMu.Unlock()
+	if pending {
+		t.Error("Expected buffered AI mutation withdrawn")
+	}
+	if _, err := reportEditorActivity(ctx, EditorActivity{Engine: "blender", Kind: "teleport"}); err == nil {
+		t.Error("Expected unknown activity kinds to be refused")
+	}
+
+	drag.Kind = "idle"
+	reportEditorActivity(ctx, drag)
+	sweepStaleIntents()
+	txMu.Lock(); status = intents[id].Status; delete(intents, id); txMu.Unlock()
+	if status != IntentApproved {
+		t.Errorf("Expected waiting intent to resume once the human is idle, got %s", status)
+	}
+}
+
+func TestEditorToolsRequireGrants(t *testing.T) {
+	ctx := context.Background()
+	unity, _ := startFakeEngines(t)
+	if _, _, err := sync_camera(ctx, nil, SyncCameraArgs{Source: "blender"}); err == nil || !strings.HasPrefix(err.Error(), "CAPABILITY_REQUIRED") {
+		t.Errorf("Expected sync_camera without a grant to be refused, got %v", err)
+	}
+	if _, _, err := control_playback(ctx, nil, struct { Action string; Time float64; Grant string `json:"grant"` }{Action: "play"}); err == nil {
+		t.Error("Expected control_playback without a grant to be refused")
+	}
+	if _, _, err := vibe_multiplex(ctx, nil, MultiplexCallArgs{SensorID: "selection_mcp", Target: "unity", Endpoint: "selection/set", Payload: map[string]interface{}{"ids": []interface{}{"Rock_01"}}}); err == nil {
+		t.Error("Expected vibe_multiplex without a grant to be refused")
+	}
+
+	grant := testGrant("operator.unity", []string{"unity"}, []VibeOpcode{OpSystem}, []string{"Rock_*"})
+	if _, _, err := sync_selection(ctx, nil, SyncSelectionArgs{Source: "blender", IDs: []string{"Tree_01"}, Grant: grant}); err == nil || !strings.HasPrefix(err.Error(), "CAPABILITY_DENIED") {
+		t.Errorf("Expected a selection outside the grant to be refused, got %v", err)
+	}
+	if unity.count("POST /selection/set") != 0 {
+		t.Fatal("Expected refused calls not to reach the engine")
+	}
+	if _, _, err := sync_selection(ctx, nil, SyncSelectionArgs{Source: "blender", IDs: []string{"Rock_01"}, Grant: grant}); err != nil || unity.count("POST /selection/set") != 1 {
+		t.Errorf("Expected a granted selection to reach Unity, got %v (%d calls)", err, unity.count("POST /selection/set"))
+	}
+}
+
+func TestLockMirrorFlattensCentralTable(t *testing.T) {
+	lockMu.Lock(); lockTable = map[string]*VibeLock{}; lockMu.Unlock()
+	if _, _, err := lock_object(context.Background(), nil, LockObjectArgs{Target: "blender", ObjectID: "Lamp_01", Locked: true}); err == nil {
+		t.Error("Expected lock_object without a grant to be refused")
+	}
+	grant := testGrant("operator.blender", []string{"blender"}, []VibeOpcode{OpSystem}, []string{"Lamp_01"})
+	res, _, err := lock_object(context.Background(), nil, LockObjectArgs{Target: "blender", ObjectID: "Lamp_01", Locked: true, Grant: grant})
+	if err != nil {
+		t.Fatalf("Expected lock_object to take a central lock, got %v", err)
+	}
+	var locked struct{ Result struct{ Lease string `json:"lease"` } `json:"result"` }
+	json.Unmarshal([]byte(res.Content[0].(*mcp.TextContent).Text), &locked)
+	if _, _, err := lock_object(context.Background(), nil, LockObjectArgs{Target: "blender", ObjectID: "Lamp_01", Locked: false, Grant: grant}); err == nil {
+		t.Error("Expected a release without the lease to be refused")
+	}
+	acquireLock("Dock_Collection", LockHumanActive, ActorHuman, "artist", "", []string{"Crate_01"}, time.Minute)
+	perimeter_lock(context.Background(), nil, humanPerimeter(PerimeterLockArgs{Locked: true}))
+
+	flags := map[string]MirroredLock{}
+	for _, m := range lockMirrorSnapshot() { flags[m.UUID] = m }
+	if flags["Lamp_01"].Label != "AI working on this" || flags["Crate_01"].Via != "Dock_Collection" || flags[perimeterKey].Type != LockPerimeter {
+		t.Errorf("Expected operator, covered and perimeter flags, got %+v", flags)
+	}
+	before := lockMirrorDigest(lockMirrorSnapshot())
+
+	if _, _, err := lock_object(context.Background(), nil, LockObjectArgs{Target: "blender", ObjectID: "Lamp_01", Locked: false, Lease: locked.Result.Lease, Grant: grant}); err != nil {
+		t.Fatalf("Expected the owner to release with its lease, got %v", err)
+	}
+	if lockMirrorDigest(lockMirrorSnapshot()) == before {
+		t.Error("Expected a released lock to change the mirrored table")
+	}
+	// No editor is listening: nothing may be recorded as mirrored
+	if errs := pushLockMirror(context.Background()); len(errs) == 0 {
+		t.Error("Expected unreachable engines to be reported")
+	}
+	lockMirrorMu.Lock(); n := len(mirroredDigests); lockMirrorMu.Unlock()
+	if n != 0 {
+		t.Errorf("Expected no engine marked in sync, got %d", n)
+	}
+
+	// Adapters hold the last table they were sent and echo its digest
+	unity, blender := startFakeEngines(t)
+	var heldMu sync.Mutex
+	held := map[*fakeEngine]string{}
+	for _, e := range []*fakeEngine{unity, blender} {
+		e := e
+		e.handle = func(path string, body map[string]interface{}) map[string]interface{} {
+			heldMu.Lock(); defer heldMu.Unlock()
+			if path == "/locks/sync" { held[e], _ = body["digest"].(string) }
+			if path == "/health" { return map[string]interface{}{"status": "ok", "lock_digest": held[e]} }
+			return nil
+		}
+	}
+	pushLockMirror(context.Background())
+	pushLockMirror(context.Background())
+	acquireLock("Dock_Collection", LockHumanActive, ActorHuman, "artist", lockTable["Dock_Collection"].Lease, []string{"Crate_01"}, 2*time.Minute)
+	pushLockMirror(context.Background())
+	if unity.count("POST /locks/sync") != 1 || blender.count("POST /locks/sync") != 1 {
+		t.Errorf("Expected one push per engine for an unchanged table, got %d and %d", unity.count("POST /locks/sync"), blender.count("POST /locks/sync"))
+	}
+	heldMu.Lock(); held[blender] = ""; heldMu.Unlock() // Blender restarted
+	reconcileLockMirror(context.Background())
+	pushLockMirror(context.Background())
+	if unity.count("POST /locks/sync") != 1 || blender.count("POST /locks/sync") != 2 {
+		t.Errorf("Expected only the restarted editor resynced, got %d and %d", unity.count("POST /locks/sync"), blender.count("POST /locks/sync"))
+	}
+	lockMirrorMu.Lock(); mirroredDigests = make(map[string]string); lockMirrorMu.Unlock()
+	lockMu.Lock(); lockTable = map[string]*VibeLock{}; lockMu.Unlock()
+}
+
+func TestHierarchyRejectsCycles(t *testing.T) {
+	if _, err := buildHierarchy([]HierarchyNode{{UUID: "A", Parent: "B"}, {UUID: "B", Parent: "A"}}); err == nil {
+		t.Error("Expected a cyclic snapshot to be refused")
+	}
+	g, err := buildHierarchy([]HierarchyNode{{UUID: "Root"}, {UUID: "Dock", Parent: "Root"}, {UUID: "Crate_01", Parent: "Dock"}})
+	if err != nil { t.Fatal(err) }
+	hierarchyMu.Lock(); hierarchies["unity"] = g; hierarchyMu.Unlock()
+	defer func() { hierarchyMu.Lock(); delete(hierarchies, "unity"); hierarchyMu.Unlock() }()
+
+	ctx := context.Background()
+	if err := checkReparent(ctx, []string{"unity"}, "Crate_01", "Root"); err != nil {
+		t.Errorf("Expected moving a leaf up to be admissible, got %v", err)
+	}
+	if err := checkReparent(ctx, []string{"unity"}, "Dock", "Dock"); err == nil {
+		t.Error("Expected self-parenting to be refused")
+	}
+
+	// Root under its own grandchild: refused at validation, before any engine call
+	intents = make(map[string]*IntentRecord)
+	rec := newIntentRecord("cycle-1", IntentEnvelope{Rationale: "Nest the scene", Confidence: 0.9})
+	rec.OpSpec = map[string]interface{}{"target": "unity", "endpoint": "hierarchy/reparent", "payload": map[string]interface{}{"id": "Root", "parent": "Crate_01"}}
+	intents[rec.ID] = rec
+	_, _, err = validate_intent(ctx, nil, struct{ID string `json:"intent_id"`}{ID: rec.ID})
+	if err == nil || !strings.HasPrefix(err.Error(), "GRAPH_INVALIDITY") {
+		t.Fatalf("Expected GRAPH_INVALIDITY, got %v", err)
+	}
+	if rec.Status != IntentRejected {
+		t.Errorf("Expected cyclic intent to be rejected, got %s", rec.Status)
+	}
+
+	// Accepted mutations move the mirror; unpredictable ones mark it stale
+	observeHierarchyMutation("unity", "hierarchy/reparent", map[string]interface{}{"id": "Crate_01", "parent": "Root"})
+	hierarchyMu.Lock(); up, _ := g.ancestors("Crate_01"); hierarchyMu.Unlock()
+	if len(up) != 1 || up[0] != "Root" {
+		t.Errorf("Expected Crate_01 directly under Root, got %v", up)
+	}
+	observeHierarchyMutation("unity", "object/delete", map[string]interface{}{"id": "Dock"})
+	hierarchyMu.Lock(); stale := g.Refreshed.IsZero(); hierarchyMu.Unlock()
+	if !stale {
+		t.Error("Expected a delete to mark the mirror stale")
+	}
+	delete(intents, rec.ID)
+}
+
+func TestDeleteClosureCoversDescendantsAndDependents(t *testing.T) {
+	g, err := buildHierarchy([]HierarchyNode{
+		{UUID: "Dock"},
+		{UUID: "Crate_01", Parent: "Dock"},
+		{UUID: "Lid_01", Parent: "Crate_01"},
+		{UUID: "Lamp_01", Dependents: []string{"Lamp_01.TrackTo"}},
+		{UUID: "Rope_01", Parent: "Dock", Dependents: []string{"Hook_01.Constraint"}},
+	})
+	if err != nil { t.Fatal(err) }
+
+	closure, err := deleteClosure(g, []string{"Dock"})
+	if err != nil { t.Fatal(err) }
+	want := []string{"Crate_01", "Dock", "Hook_01.Constraint", "Lid_01", "Rope_01"}
+	if strings.Join(closure, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected closure %v, got %v", want, closure)
+	}
+	if _, err := deleteClosure(g, []string{"Ghost_01"}); err == nil || !strings.HasPrefix(err.Error(), "DELETE_CLOSURE_UNKNOWN") {
+		t.Errorf("Expected an unknown root to be refused, got %v", err)
+	}
+
+	// Nothing was sent, so nothing needs rewinding
+	if phase := restoreDelete(context.Background(), newUndoRecord("delete_objects", OpDelete, "", ""), []string{"unity"}); phase != PhaseRolledBack {
+		t.Errorf("Expected an unsent delete to end ROLLED_BACK, got %s", phase)
+	}
+	if _, _, err := delete_objects(context.Background(), nil, DeleteArgs{}); err == nil || !strings.HasPrefix(err.Error(), "DELETE_EMPTY") {
+		t.Errorf("Expected an empty delete to be refused, got %v", err)
+	}
+}
+
+func TestDeletePartlyFailingIsRewoundOnTheEngines(t *testing.T) {
+	usePolicy(t, GovernancePolicy{Version: "test", Default: PolicyRule{ID: "default"}, Rules: []PolicyRule{
+		{ID: "setup.delete", Intent: IntentSceneSetup, AllowedOpcodes: []VibeOpcode{OpDelete, OpTransform}},
+	}})
+	saved := safetySnapshot
+	safetySnapshot = func(string) (string, error) { return "snap-1", nil }
+	defer func() { safetySnapshot = saved }()
+	conflictMu.Lock(); conflictClaims = nil; conflictMu.Unlock()
+	unity, blender := startFakeEngines(t)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	deleted := map[string]bool{}
+	hierarchy := func(engine string) map[string]interface{} {
+		mu.Lock(); defer mu.Unlock()
+		nodes := []interface{}{}
+		if !deleted[engine] {
+			nodes = append(nodes,
+				map[string]interface{}{"uuid": "Dock"},
+				map[string]interface{}{"uuid": "Crate_01", "parent": "Dock", "dependents": []interface{}{"Hook_01/constraint/TrackTo"}},
+				map[string]interface{}{"uuid": "Hook_01"})
+		}
+		return map[string]interface{}{"nodes": nodes}
+	}
+	undoFails := false
+	blender.handle = func(path string, body map[string]interface{}) map[string]interface{} {
+		switch path {
+		case "/hierarchy/get":
+			return hierarchy("blender")
+		case "/object/delete":
+			mu.Lock(); deleted["blender"] = true; mu.Unlock()
+			return map[string]interface{}{"status": "ok", "undo_token": "b-1"}
+		case "/undo":
+			if undoFails { return map[string]interface{}{"error": "UNDO_EXPIRED"} }
+			mu.Lock(); deleted["blender"] = false; mu.Unlock()
+		}
+		return nil
+	}
+	unity.handle = func(path string, body map[string]interface{}) map[string]interface{} {
+		switch path {
+		case "/hierarchy/get":
+			return hierarchy("unity")
+		case "/object/delete":
+			return map[string]interface{}{"error": "OBJECT_LOCKED"}
+		}
+		return nil
+	}
+
+	submit := func(op VibeOpcode) string {
+		id := uuid.New().String()
+		rec := newIntentRecord(id, IntentEnvelope{Intent: IntentSceneSetup, Opcode: op, Confidence: 1, Scope: []string{"Dock"}})
+		rec.Status = IntentValidated
+		txMu.Lock(); intents[id] = rec; txMu.Unlock()
+		return id
+	}
+	testGrant("operator.unity", nil, nil, nil)
+	grant := func(intentID string, ops ...VibeOpcode) string {
+		return encodeGrant(CapabilityGrant{ID: uuid.New().String(), Holder: "operator.unity", IntentID: intentID, UUIDs: []string{"Dock", "Crate_01", "Hook_01/constraint/*"}, Opcodes: ops, Engines: []string{"unity", "blender"}, ExpiresAt: time.Now().Add(time.Minute)})
+	}
+
+	// A grant scoped over the closure is not enough without the delete's own
+	// intent and the delete opcode.
+	if _, _, err := delete_objects(ctx, nil, DeleteArgs{UUIDs: []string{"Dock"}, Grant: grant("", OpTransform)}); err == nil || !strings.Contains(err.Error(), "UNKNOWN_INTENT") {
+		t.Errorf("Expected a delete without an intent to be refused, got %v", err)
+	}
+	moveID := submit(OpTransform)
+	if _, _, err := delete_objects(ctx, nil, DeleteArgs{IntentID: moveID, UUIDs: []string{"Dock"}, Grant: grant(moveID, OpTransform)}); err == nil || !strings.HasPrefix(err.Error(), "CAPABILITY_DENIED") {
+		t.Errorf("Expected a transform intent to be refused a delete, got %v", err)
+	}
+	deleteID := submit(OpDelete)
+	if _, _, err := delete_objects(ctx, nil, DeleteArgs{IntentID: deleteID, UUIDs: []string{"Dock"}, Grant: grant(deleteID, OpTransform)}); err == nil || !strings.HasPrefix(err.Error(), "CAPABILITY_DENIED") {
+		t.Errorf("Expected a transform grant to be refused a delete, got %v", err)
+	}
+	if _, _, err := delete_objects(ctx, nil, DeleteArgs{IntentID: deleteID, UUIDs: []string{"Dock"}, Grant: grant(moveID, OpDelete)}); err == nil || !strings.HasPrefix(err.Error(), "CAPABILITY_DENIED") {
+		t.Errorf("Expected another intent's grant to be refused, got %v", err)
+	}
+	if blender.count("POST /object/delete") != 0 {
+		t.Fatal("Expected no refused delete to reach an engine")
+	}
+
+	// Blender deletes, Unity refuses: Blender is rewound through its own undo
+	_, _, err := delete_objects(ctx, nil, DeleteArgs{IntentID: deleteID, UUIDs: []string{"Dock"}, Grant: grant(deleteID, OpDelete)})
+	if err == nil || !strings.HasPrefix(err.Error(), "DELETE_ROLLED_BACK") {
+		t.Fatalf("Expected the partial delete rolled back, got %v", err)
+	}
+	var sent map[string]interface{}
+	blender.mu.Lock()
+	for i, c := range blender.calls { if c == "POST /undo" { sent = blender.bodies[i] } }
+	blender.mu.Unlock()
+	if blender.count("POST /undo") != 1 || sent["undo_token"] != "b-1" {
+		t.Errorf("Expected Blender's delete undone by its token, got %d undo calls (body %v)", blender.count("POST /undo"), sent)
+	}
+	if !strings.Contains(fmt.Sprint(blender.bodies), "Hook_01/constraint/TrackTo") {
+		t.Error("Expected the closure to carry the constraint that targets a member")
+	}
+
+	// When the engine cannot undo either, the delete is quarantined and the
+	// orchestrator touches nothing else.
+	undoFails = true
+	conflictMu.Lock(); conflictClaims = nil; conflictMu.Unlock()
+	mu.Lock(); deleted["blender"] = false; mu.Unlock()
+	retryID := submit(OpDelete)
+	_, _, err = delete_objects(ctx, nil, DeleteArgs{IntentID: retryID, UUIDs: []string{"Dock"}, Grant: grant(retryID, OpDelete)})
+	if err == nil || !strings.HasPrefix(err.Error(), "DELETE_QUARANTINED") {
+		t.Fatalf("Expected a failed rewind to quarantine the delete, got %v", err)
+	}
+	txMu.Lock(); status := intents[retryID].Status; txMu.Unlock()
+	if status != IntentQuarantined {
+		t.Errorf("Expected the delete's intent quarantined, got %s", status)
+	}
+	conflictMu.Lock(); conflictClaims = nil; conflictMu.Unlock()
+}
+
+func TestPrefabDepthMismatchQuarantinesTransaction(t *testing.T) {
+	if _, err := buildHierarchy([]HierarchyNode{{UUID: "Crate_01", Kind: "VARIANT"}}); err == nil {
+		t.Error("Expected an unknown object kind to be refused")
+	}
+	g, err := buildHierarchy([]HierarchyNode{
+		{UUID: "CratePrefab", Kind: KindPrefabDef},
+		{UUID: "Crate_01", Kind: KindPrefabInstance, PrefabDepth: 2},
+		{UUID: "Lamp_01"},
+	})
+	if err != nil { t.Fatal(err) }
+	hierarchyMu.Lock(); hierarchies["unity"] = g; hierarchyMu.Unlock()
+	defer func() { hierarchyMu.Lock(); delete(hierarchies, "unity"); hierarchyMu.Unlock() }()
+
+	targets, err := mutationIdentities("unity", map[string]interface{}{"id": "Crate_01", "position": []float64{0, 1, 0}})
+	if err != nil || len(targets) != 1 || targets[0].PrefabDepth != 2 || targets[0].Kind != KindPrefabInstance {
+		t.Fatalf("Expected the mirrored depth to be recorded, got %+v (%v)", targets, err)
+	}
+	if same, err := mutationIdentities("unity", map[string]interface{}{"id": "Crate_01", "prefab_depth": float64(2), "kind": "PREFAB_INSTANCE"}); err != nil || same[0].PrefabDepth != 2 {
+		t.Errorf("Expected a payload agreeing with the mirror to pass, got %+v (%v)", same, err)
+	}
+	if _, err := mutationIdentities("unity", map[string]interface{}{"id": "Crate_01", "prefab_depth": float64(0)}); err == nil || !strings.HasPrefix(err.Error(), "IDENTITY_DEPTH_MISMATCH") {
+		t.Errorf("Expected a payload depth contradicting the mirror to be refused, got %v", err)
+	}
+	if _, err := mutationIdentities("unity", map[string]interface{}{"id": "Crate_01", "kind": "PREFAB_DEF"}); err == nil {
+		t.Error("Expected a payload kind contradicting the mirror to be refused")
+	}
+	if unseen, err := mutationIdentities("unity", map[string]interface{}{"id": "Barrel_09", "prefab_depth": float64(1)}); err != nil || len(unseen) != 1 || unseen[0].PrefabDepth != 1 {
+		t.Errorf("Expected a payload depth to name an object the mirror has not seen, got %+v (%v)", unseen, err)
+	}
+	unity, _ := startFakeEngines(t)
+	if _, err := sendMutation(context.Background(), newDetachedUndoRecord("test", OpTransform, "", ""), "unity", "object/update", map[string]interface{}{"id": "Crate_01", "prefab_depth": float64(0)}); err == nil || unity.count("POST /object/update") != 0 {
+		t.Errorf("Expected a contradicting payload to be refused before reaching the engine, got %v", err)
+	}
+
+	state := func(depth int) map[string]interface{} {
+		return map[string]interface{}{"hash": "H1", "identities": []interface{}{
+			map[string]interface{}{"uuid": "Crate_01", "kind": "PREFAB_INSTANCE", "prefab_depth": float64(depth)},
+		}}
+	}
+	h, err := verifyAtDepth(state(2), targets)
+	if err != nil || h == "H1" {
+		t.Errorf("Expected a depth-masked hash, got %q (%v)", h, err)
+	}
+	if _, err := verifyAtDepth(state(0), targets); err == nil || !strings.HasPrefix(err.Error(), "IDENTITY_DEPTH_MISMATCH") {
+		t.Errorf("Expected an instance override not to verify against its definition, got %v", err)
+	}
+	if _, err := verifyAtDepth(map[string]interface{}{"hash": "H1"}, targets); err == nil {
+		t.Error("Expected a prefab target without an identity report to be unverifiable")
+	}
+	lamp, _ := mutationIdentities("unity", map[string]interface{}{"id": "Lamp_01"})
+	if h, err := verifyAtDepth(map[string]interface{}{"hash": "H1"}, lamp); err != nil || h == "" {
+		t.Errorf("Expected plain objects to verify without a report, got %v", err)
+	}
+
+	intents = make(map[string]*IntentRecord)
+	rec := newIntentRecord("depth-1", IntentEnvelope{Rationale: "Tint the crate", Confidence: 0.9})
+	intents[rec.ID] = rec
+	transitionIntent(rec.ID, IntentValidated, "POLICY_ALLOW")
+	begin_atomic_operation(context.Background(), nil, AtomicOpArgs{IntentID: rec.ID})
+	_, err = verifyAtDepth(state(0), targets)
+	quarantineTransaction(rec.ID, "unity", err)
+
+	txMu.Lock(); tx, status := transactions[rec.ID], rec.Status; txMu.Unlock()
+	if status != IntentQuarantined {
+		t.Errorf("Expected the intent to be quarantined, got %s", status)
+	}
+	if err := verifyProofOfWork(tx, ProofOfWork{TransactionID: tx.ID}, EchoTriplet{}); err == nil || !strings.HasPrefix(err.Error(), "TX_QUARANTINED") {
+		t.Errorf("Expected a quarantined transaction to refuse commit, got %v", err)
+	}
+	txMu.Lock(); delete(transactions, rec.ID); activeTransaction = nil; delete(intents, rec.ID); txMu.Unlock()
+}
+
+func TestAdapterIdentityReportsDriveDepthVerification(t *testing.T) {
+	unity, blender := startFakeEngines(t)
+	ctx := context.Background()
+	defer func() { hierarchyMu.Lock(); delete(hierarchies, "unity"); delete(hierarchies, "blender"); hierarchyMu.Unlock() }()
+
+	// Replies shaped as the adapters build them: hierarchy nodes carry the
+	// identity fields plus parent, state/get carries the same identities.
+	var mu sync.Mutex
+	unityDepth, blenderKind := 2, "PREFAB_INSTANCE"
+	report := func(engine string) []interface{} {
+		mu.Lock(); defer mu.Unlock()
+		if engine == "unity" {
+			return []interface{}{
+				map[string]interface{}{"uuid": "Dock", "kind": "OBJECT", "prefab_depth": 0, "parent": ""},
+				map[string]interface{}{"uuid": "Crate_01", "kind": "PREFAB_INSTANCE", "prefab_depth": unityDepth, "parent": "Dock"},
+			}
+		}
+		return []interface{}{
+			map[string]interface{}{"uuid": "Rock_Lib", "kind": "PREFAB_DEF", "prefab_depth": 0, "parent": ""},
+			map[string]interface{}{"uuid": "Forest_Set", "kind": blenderKind, "prefab_depth": 1, "parent": ""},
+		}
+	}
+	serve := func(engine string) func(string, map[string]interface{}) map[string]interface{} {
+		return func(path string, body map[string]interface{}) map[string]interface{} {
+			switch path {
+			case "/hierarchy/get":
+				return map[string]interface{}{"nodes": report(engine)}
+			case "/state/get":
+				return map[string]interface{}{"hash": "H-" + engine, "identities": report(engine)}
+			}
+			return nil
+		}
+	}
+	unity.handle, blender.handle = serve("unity"), serve("blender")
+
+	for _, e := range []string{"unity", "blender"} {
+		if _, err := refreshHierarchy(ctx, e); err != nil { t.Fatalf("Expected the %s report to load, got %v", e, err) }
+	}
+	crate, _ := mutationIdentities("unity", map[string]interface{}{"id": "Crate_01"})
+	forest, _ := mutationIdentities("blender", map[string]interface{}{"id": "Forest_Set"})
+	if len(crate) != 1 || crate[0].Kind != KindPrefabInstance || crate[0].PrefabDepth != 2 {
+		t.Fatalf("Expected Unity's nested instance mirrored at depth 2, got %+v", crate)
+	}
+	if len(forest) != 1 || forest[0].Kind != KindPrefabInstance || forest[0].PrefabDepth != 1 {
+		t.Fatalf("Expected Blender's collection instance mirrored at depth 1, got %+v", forest)
+	}
+	if id, _ := identityOf("blender", "Rock_Lib"); id.Kind != KindPrefabDef {
+		t.Errorf("Expected linked library data mirrored as a definition, got %+v", id)
+	}
+
+	verify := func(engine string, targets []ObjectIdentity) (string, error) {
+		v, err := sendToEngine(ctx, engine, "state/get", "GET", nil)
+		if err != nil { return "", err }
+		return verifyAtDepth(v, targets)
+	}
+	h, err := verify("unity", crate)
+	if err != nil || h == "H-unity" { t.Fatalf("Expected Unity's report to verify at depth, got %q (%v)", h, err) }
+	if _, _, err := verify_engine_state(ctx, nil, VerifyStateArgs{Target: "unity", ExpectedHash: h, Identities: crate}); err != nil {
+		t.Errorf("Expected the depth-folded hash to verify, got %v", err)
+	}
+	if _, err := verify("blender", forest); err != nil { t.Errorf("Expected Blender's report to verify, got %v", err) }
+
+	// The instance was unpacked one level, or realized into plain objects
+	mu.Lock(); unityDepth, blenderKind = 1, "OBJECT"; mu.Unlock()
+	if _, _, err := verify_engine_state(ctx, nil, VerifyStateArgs{Target: "unity", ExpectedHash: h, Identities: crate}); err == nil || !strings.HasPrefix(err.Error(), "IDENTITY_DEPTH_MISMATCH") {
+		t.Errorf("Expected Unity's changed depth to be caught, got %v", err)
+	}
+	if _, err := verify("blender", forest); err == nil || !strings.HasPrefix(err.Error(), "IDENTITY_DEPTH_MISMATCH") {
+		t.Errorf("Expected Blender's realized instance to be caught, got %v", err)
+	}
+}
+
+func TestWaitForGraphDetectsAndBreaksDeadlocks(t *testing.T) {
+	// The escalation mutex is handed on instead of being held forever. Results
+	// arrive as outbox files, which are consumed as they are processed.
+	result := func(engine string, res WorkResult) {
+		path := filepath.Join(t.TempDir(), res.WorkOrderID+".json")
+		data, _ := json.Marshal(res)
+		os.WriteFile(path, data, 0644)
+		processWorkResult(engine, path)
+		if _, err := os.Stat(path); err == nil { t.Fatalf("Expected %s's result file consumed", res.WorkOrderID) }
+	}
+	registryMu.Lock(); failureRegistry = make(map[string]int); registryMu.Unlock()
+	result("unity", WorkResult{ID: "r1", WorkOrderID: "wo-a", Status: "FAILURE", Error: "E_A"})
+	result("blender", WorkResult{ID: "r2", WorkOrderID: "wo-b", Status: "FAILURE", Error: "E_B"})
+	if e := lastWalEntry(t); e["order_id"] != "wo-b" || e["phase"] != string(PhaseWaitHuman) {
+		t.Fatalf("Expected wo-b queued behind the mutex, got %v", e)
+	}
+	edges := buildWaitGraph(time.Now())
+	if len(edges) != 1 || edges[0].Waiter != "pipeline:wo-b" || edges[0].Holder != "pipeline:wo-a" {
+		t.Errorf("Expected wo-b waiting on wo-a, got %+v", edges)
+	}
+	result("unity", WorkResult{ID: "r3", WorkOrderID: "wo-a", Status: "SUCCESS"})
+	escalationMu.Lock(); holder := escalatedPipeline; escalationMu.Unlock()
+	if holder != "wo-b" {
+		t.Errorf("Expected the mutex to pass to wo-b, got %q", holder)
+	}
+	// With its result file gone, wo-b learns of the handoff from the WAL and
+	// the event stream.
+	if e := lastWalEntry(t); e["order_id"] != "wo-b" || e["phase"] != string(PhaseHalted) || e["engine"] != "blender" || e["permissions_mask"] == nil {
+		t.Errorf("Expected wo-b journaled HALTED with a holder's permissions, got %v", e)
+	}
+	events, _ := os.ReadFile(EventFile)
+	handoff := false
+	for _, line := range strings.Split(strings.TrimSpace(string(events)), "\n") {
+		var ev VibeEvent
+		json.Unmarshal([]byte(line), &ev)
+		if ev.Type == "escalation_handoff" && ev.Payload["order_id"] == "wo-b" && ev.Payload["engine"] == "blender" && ev.NextStep == "HANDLE_FAILURE" { handoff = true }
+	}
+	if !handoff { t.Error("Expected the handoff to be announced to wo-b's workers") }
+	settleEscalation("wo-b", string(PhaseTerminal))
+
+	// agent-x holds a transaction on Crate_01 and waits for Lamp_01;
+	// agent-y holds Lamp_01 and waits for Crate_01.
+	lockMu.Lock(); lockTable = map[string]*VibeLock{}; lockMu.Unlock()
+	intents = make(map[string]*IntentRecord)
+	mk := func(id, agent, scope string, status IntentStatus) *IntentRecord {
+		rec := newIntentRecord(id, IntentEnvelope{Rationale: "r", Confidence: 0.9, Scope: []string{scope}})
+		rec.Agent.AgentID, rec.Status = agent, status
+		intents[id] = rec
+		return rec
+	}
+	mk("x-tx", "agent-x", "Crate_01", IntentExecuting)
+	mk("x-wait", "agent-x", "Lamp_01", IntentWaitHuman)
+	y := mk("y-wait", "agent-y", "Crate_01", IntentWaitHuman)
+	txMu.Lock(); transactions["x-tx"] = &VibeTransaction{ID: "tx-x", IntentID: "x-tx", Agent: "agent-x", Status: "OPEN"}; txMu.Unlock()
+	if _, err := acquireLock("Lamp_01", LockAISpeculative, ActorAI, "agent-y", "", nil, time.Minute); err != nil { t.Fatal(err) }
+
+	r := scanDeadlocks()
+	if len(r.Cycles) != 1 || len(r.Cycles[0]) != 2 || r.Cycles[0][0].Waiter != "agent-x" {
+		t.Fatalf("Expected one two-party deadlock, got %+v", r.Cycles)
+	}
+	if _, err := breakDeadlock(context.Background(), BreakDeadlockArgs{Victim: "agent-y"}); err == nil {
+		t.Error("Expected a break without approver to be refused")
+	}
+	offChain := BreakDeadlockArgs{Victim: "agent-z", Approver: "lead", Reason: "test"}
+	offChain.Signature = humanSign("lead", "break_deadlock", offChain)
+	if _, err := breakDeadlock(context.Background(), offChain); err == nil || !strings.HasPrefix(err.Error(), "DEADLOCK_NOT_FOUND") {
+		t.Errorf("Expected a victim off the chain to be refused, got %v", err)
+	}
+	yields := BreakDeadlockArgs{Victim: "agent-y", Approver: "lead", Reason: "agent-y yields"}
+	yields.Signature = humanSign("lead", "break_deadlock", yields)
+	if _, err := breakDeadlock(context.Background(), yields); err != nil {
+		t.Fatal(err)
+	}
+	if y.Status != IntentRejected || len(waitGraphReport(time.Now()).Cycles) != 0 {
+		t.Errorf("Expected agent-y withdrawn and the cycle gone, got %s", y.Status)
+	}
+	txMu.Lock(); delete(transactions, "x-tx"); intents = make(map[string]*IntentRecord); txMu.Unlock()
+	lockMu.Lock(); lockTable = map[string]*VibeLock{}; lockMu.Unlock()
+}
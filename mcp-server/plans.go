@@ -0,0 +1,355 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Strategic Plans
+//
+// A proposed plan is stored and locked until a human approves it. Once run,
+// the orchestrator executes one step at a time: each step becomes an intent
+// (carrying the plan hash) and a work order, and the next step is only
+// dispatched after the previous work result came back SUCCESS. Plans are
+// signed by the agent that proposes them, and step intents are recorded as that
+// agent's so it can be minted grants for them. Wall-clock time spent on steps
+// is charged against TotalBudget. Aborting rewinds the undo records of every
+// completed step, newest first; a completed step that left nothing to rewind
+// stops the rollback for human review.
+//
+// Lock order: planMu before txMu, walMu and undoRunMu.
+const PlanFile = PersistenceDir + "/plans.json"
+
+var (
+	plans  = make(map[string]*PlanRecord)
+	planMu sync.Mutex
+)
+
+func planHash(p StrategicPlan) string {
+	p.Signature = ""
+	data, _ := json.Marshal(p)
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func isPlanTerminal(s PlanStatus) bool {
+	return s == PlanCompleted || s == PlanAborted || s == PlanRejected
+}
+
+func setPlanStatusLocked(p *PlanRecord, to PlanStatus, reason string) {
+	from := p.Status
+	p.Status, p.Reason, p.UpdatedAt = to, reason, time.Now()
+	level := LevelInfo
+	if to == PlanPaused || to == PlanAborted { level = LevelWarn }
+	dispatchVibeEvent(level, "plan_transition", "", string(to), map[string]interface{}{"plan_id": p.Plan.ID, "from": from, "to": to, "reason": reason, "budget_used_ms": p.BudgetUsedMS})
+	savePlansLocked()
+}
+
+// dispatchNextStepLocked turns the step at the cursor into an intent and a
+// work order. Caller holds planMu.
+func dispatchNextStepLocked(p *PlanRecord) {
+	if p.Cursor >= len(p.Plan.Steps) { setPlanStatusLocked(p, PlanCompleted, "ALL_STEPS_DONE"); return }
+	if p.BudgetUsedMS >= p.Plan.TotalBudget {
+		setPlanStatusLocked(p, PlanPaused, fmt.Sprintf("BUDGET_EXHAUSTED: %dms of %dms used", p.BudgetUsedMS, p.Plan.TotalBudget))
+		return
+	}
+
+	step := p.Plan.Steps[p.Cursor]
+	st := &p.Steps[p.Cursor]
+	env := IntentEnvelope{
+		Rationale:  step.Description,
+		Provenance: "PLAN:" + p.Plan.ID,
+		PlanHash:   p.Hash,
+		AgentID:    p.Agent.AgentID,
+		Confidence: 1,
+		Scope:      []string{step.UUID},
+		BudgetMS:   p.Plan.TotalBudget - p.BudgetUsedMS,
+		Intent:     step.Intent,
+		Opcode:     step.Opcode,
+	}
+	class := classifyIntent(step.Opcode, "", nil)
+	if err := checkPerimeter(class, fmt.Sprintf("step %d", step.ID)); err != nil {
+		// Not the step's fault: leave it pending so resume_strategic_plan retries it.
+		setPlanStatusLocked(p, PlanPaused, err.Error())
+		return
+	}
+	intentID := uuid.New().String()
+	d := evaluatePolicy(env, class)
+	journalPolicyDecision(intentID, p.Agent.AgentID, "plan_step", d)
+	if !d.Allowed {
+		st.Status, st.Error = "FAILED", d.Reason
+		setPlanStatusLocked(p, PlanPaused, fmt.Sprintf("STEP_REFUSED: step %d: %s", step.ID, d.Reason))
+		return
+	}
+
+	// The human already reviewed this step as part of the plan.
+	rec := newIntentRecord(intentID, env); rec.Class, rec.Policy, rec.Agent = class, d, p.Agent
+	txMu.Lock()
+	intents[intentID] = rec
+	transitionIntentLocked(rec, IntentApproved, "PLAN_APPROVED by "+p.Approver)
+	transitionIntentLocked(rec, IntentExecuting, "WORK_ORDER_DISPATCHED")
+	txMu.Unlock()
+
+	order := WorkOrder{
+		ID:          uuid.New().String(),
+		MonotonicID: nextMonotonicID(),
+		Intent:      step.Intent,
+		Opcode:      step.Opcode,
+		UUID:        step.UUID,
+		Description: step.Description,
+		Context:     map[string]interface{}{"plan_id": p.Plan.ID, "plan_hash": p.Hash, "step_id": step.ID, "intent_id": intentID},
+	}
+	if err := writeWorkOrder(order); err != nil {
+		transitionIntent(intentID, IntentRejected, "WORK_ORDER_FAILED")
+		st.Status, st.Error = "FAILED", err.Error()
+		setPlanStatusLocked(p, PlanPaused, "WORK_ORDER_FAILED")
+		return
+	}
+
+	*st = PlanStepState{StepID: step.ID, Status: "DISPATCHED", IntentID: intentID, WorkOrderID: order.ID, StartedAt: time.Now()}
+	p.UpdatedAt = time.Now()
+	log.Printf("🗺️ Plan %s: Step %d/%d dispatched (%s)", p.Plan.ID, p.Cursor+1, len(p.Plan.Steps), step.Description)
+	savePlansLocked()
+}
+
+// advancePlan applies a work result to the plan step that issued the order,
+// if any, and dispatches the next step while the plan is running.
+func advancePlan(res WorkResult) {
+	if res.Status == "BUSY" { return } // Worker will pick the order up again
+	planMu.Lock()
+	defer planMu.Unlock()
+
+	for _, p := range plans {
+		if p.Cursor >= len(p.Steps) { continue }
+		st := &p.Steps[p.Cursor]
+		if st.WorkOrderID != res.WorkOrderID || st.Status != "DISPATCHED" { continue }
+
+		st.FinishedAt = time.Now()
+		st.ConsumedMS = int(st.FinishedAt.Sub(st.StartedAt).Milliseconds())
+		p.BudgetUsedMS += st.ConsumedMS
+
+		if res.Status != "SUCCESS" {
+			st.Status, st.Error = "FAILED", res.Error
+			transitionIntent(st.IntentID, IntentRejected, "WORK_RESULT_"+res.Status)
+			setPlanStatusLocked(p, PlanPaused, fmt.Sprintf("STEP_FAILED: step %d: %s", st.StepID, res.Error))
+			return
+		}
+		st.Status = "DONE"
+		transitionIntent(st.IntentID, IntentDone, "WORK_RESULT_SUCCESS")
+		p.Cursor++
+		if p.Status == PlanRunning {
+			dispatchNextStepLocked(p)
+		} else {
+			savePlansLocked()
+		}
+		return
+	}
+}
+
+// rollbackPlanLocked rewinds the completed steps, newest first. On failure
+// the remaining steps stay DONE so a human can see exactly what is left.
+func rollbackPlanLocked(ctx context.Context, p *PlanRecord, reason string) error {
+	for i := len(p.Steps) - 1; i >= 0; i-- {
+		st := &p.Steps[i]
+		if st.Status != "DONE" { continue }
+		if !hasUndoRecords(st.IntentID) {
+			dispatchVibeEvent(LevelError, "plan_rollback_failed", st.IntentID, "HUMAN_REVIEW", map[string]interface{}{"plan_id": p.Plan.ID, "step_id": st.StepID, "error": "NO_UNDO_RECORD"})
+			return fmt.Errorf("HUMAN_REVIEW: step %d completed without a reversible record", st.StepID)
+		}
+		if err := rewindIntent(ctx, st.IntentID, reason); err != nil {
+			dispatchVibeEvent(LevelError, "plan_rollback_failed", st.IntentID, "HUMAN_REVIEW", map[string]interface{}{"plan_id": p.Plan.ID, "step_id": st.StepID, "error": err.Error()})
+			return fmt.Errorf("ROLLBACK_FAILED: step %d: %v", st.StepID, err)
+		}
+		st.Status = "ROLLED_BACK"
+	}
+	return nil
+}
+
+func lookupPlanLocked(id string) (*PlanRecord, error) {
+	p, ok := plans[id]
+	if !ok { return nil, fmt.Errorf("UNKNOWN_PLAN: %s", id) }
+	return p, nil
+}
+
+func savePlansLocked() {
+	data, err := json.MarshalIndent(plans, "", "  ")
+	if err != nil { return }
+	os.WriteFile(PlanFile, data, 0644)
+}
+
+func loadPlans() {
+	data, err := os.ReadFile(PlanFile)
+	if err != nil { return }
+	planMu.Lock()
+	defer planMu.Unlock()
+	json.Unmarshal(data, &plans)
+	for _, p := range plans {
+		// A plan cannot keep running across a restart: its in-flight step's
+		// intent was rejected by loadIntents. A human decides how to continue.
+		if p.Status == PlanRunning { p.Status, p.Reason = PlanPaused, "ORCHESTRATOR_RESTART" }
+	}
+}
+
+func propose_strategic_plan(ctx context.Context, req *mcp.CallToolRequest, args StrategicPlan) (*mcp.CallToolResult, any, error) {
+	if args.Title == "" || len(args.Steps) == 0 { return nil, nil, fmt.Errorf("PLAN_INVALID: A plan needs a title and at least one step") }
+	if args.TotalBudget <= 0 { return nil, nil, fmt.Errorf("PLAN_INVALID: total_budget_ms must be positive") }
+	seen := make(map[int]bool)
+	for _, s := range args.Steps {
+		if seen[s.ID] { return nil, nil, fmt.Errorf("PLAN_INVALID: Duplicate step id %d", s.ID) }
+		seen[s.ID] = true
+	}
+	agent, err := verifyPlanSigner(args)
+	if err != nil { return nil, nil, err }
+	if args.ID == "" { args.ID = uuid.New().String() }
+
+	planMu.Lock()
+	defer planMu.Unlock()
+	if _, exists := plans[args.ID]; exists { return nil, nil, fmt.Errorf("PLAN_EXISTS: %s", args.ID) }
+
+	now := time.Now()
+	p := &PlanRecord{Plan: args, Hash: planHash(args), Agent: agent, Status: PlanProposed, Steps: make([]PlanStepState, len(args.Steps)), CreatedAt: now, UpdatedAt: now}
+	for i, s := range args.Steps { p.Steps[i] = PlanStepState{StepID: s.ID, Status: "PENDING"} }
+	plans[args.ID] = p
+	savePlansLocked()
+
+	log.Printf("📝 Strategic Plan Proposed: %s (%d steps)", args.Title, len(args.Steps))
+	// Forces Human-In-The-Loop review for multi-step tasks
+	return wrapForensicResult(map[string]interface{}{"status": "PLAN_LOCKED_PENDING_GO", "plan_id": args.ID, "plan_hash": p.Hash}), nil, nil
+}
+
+// verifyPlanSigner returns the identity of the agent that signed the plan.
+func verifyPlanSigner(p StrategicPlan) (AgentIdentity, error) {
+	if err := loadAgentKeys(); err != nil && !os.IsNotExist(err) {
+		log.Printf("🚨 Agent Registry: %v (keeping previous keys)", err)
+	}
+	if p.AgentID == "" || p.Signature == "" { return AgentIdentity{}, fmt.Errorf("SIGNATURE_REQUIRED: Plan must carry agent_id and signature") }
+	key, err := activeAgentKey(p.AgentID)
+	if err != nil { return AgentIdentity{}, err }
+	if key.Role == RoleHuman { return AgentIdentity{}, fmt.Errorf("SIGNATURE_INVALID: %s is a human key and cannot propose plans", p.AgentID) }
+	if !hmac.Equal([]byte(p.Signature), []byte(signPlan(key.Secret, p))) {
+		return AgentIdentity{}, fmt.Errorf("SIGNATURE_INVALID: Plan was not signed by %s", p.AgentID)
+	}
+	return AgentIdentity{AgentID: key.AgentID, Role: key.Role, Engine: key.Engine}, nil
+}
+
+func signPlan(secret string, p StrategicPlan) string {
+	p.Signature = ""
+	data, _ := json.Marshal(p)
+	return hmacHex(secret, data)
+}
+
+func decidePlan(args PlanDecisionArgs, approve bool) (*mcp.CallToolResult, any, error) {
+	if args.Reason == "" { return nil, nil, fmt.Errorf("APPROVAL_REASON_REQUIRED") }
+	action := "approve_plan"
+	if !approve { action = "reject_plan" }
+	if err := verifyHuman(args.Approver, args.Signature, action, args); err != nil { return nil, nil, err }
+	planMu.Lock()
+	defer planMu.Unlock()
+	p, err := lookupPlanLocked(args.PlanID)
+	if err != nil { return nil, nil, err }
+	if p.Status != PlanProposed { return nil, nil, fmt.Errorf("PLAN_%s: Only proposed plans can be reviewed", p.Status) }
+
+	to := PlanApproved
+	if !approve { to = PlanRejected }
+	p.Approver = args.Approver
+	journalOperation(map[string]interface{}{"type": "plan_decision", "plan_id": p.Plan.ID, "plan_hash": p.Hash, "approver": args.Approver, "approved": approve, "reason": args.Reason})
+	setPlanStatusLocked(p, to, fmt.Sprintf("%s by %s: %s", to, args.Approver, args.Reason))
+	return wrapForensicResult(to), nil, nil
+}
+
+func human_approve_plan(ctx context.Context, req *mcp.CallToolRequest, args PlanDecisionArgs) (*mcp.CallToolResult, any, error) {
+	return decidePlan(args, true)
+}
+
+func human_reject_plan(ctx context.Context, req *mcp.CallToolRequest, args PlanDecisionArgs) (*mcp.CallToolResult, any, error) {
+	return decidePlan(args, false)
+}
+
+func run_strategic_plan(ctx context.Context, req *mcp.CallToolRequest, args PlanControlArgs) (*mcp.CallToolResult, any, error) {
+	planMu.Lock()
+	defer planMu.Unlock()
+	p, err := lookupPlanLocked(args.PlanID)
+	if err != nil { return nil, nil, err }
+	if p.Status != PlanApproved { return nil, nil, fmt.Errorf("PLAN_%s: Plan must be approved by a human before it runs", p.Status) }
+	setPlanStatusLocked(p, PlanRunning, "RUN")
+	dispatchNextStepLocked(p)
+	return wrapForensicResult(*p), nil, nil
+}
+
+func pause_strategic_plan(ctx context.Context, req *mcp.CallToolRequest, args PlanControlArgs) (*mcp.CallToolResult, any, error) {
+	planMu.Lock()
+	defer planMu.Unlock()
+	p, err := lookupPlanLocked(args.PlanID)
+	if err != nil { return nil, nil, err }
+	if p.Status != PlanRunning { return nil, nil, fmt.Errorf("PLAN_%s: Only running plans can be paused", p.Status) }
+	// An in-flight step still completes; the next one is simply not dispatched.
+	setPlanStatusLocked(p, PlanPaused, "PAUSED: "+args.Reason)
+	return wrapForensicResult(*p), nil, nil
+}
+
+func resume_strategic_plan(ctx context.Context, req *mcp.CallToolRequest, args PlanControlArgs) (*mcp.CallToolResult, any, error) {
+	planMu.Lock()
+	defer planMu.Unlock()
+	p, err := lookupPlanLocked(args.PlanID)
+	if err != nil { return nil, nil, err }
+	if p.Status != PlanPaused { return nil, nil, fmt.Errorf("PLAN_%s: Only paused plans can be resumed", p.Status) }
+	if p.BudgetUsedMS >= p.Plan.TotalBudget { return nil, nil, fmt.Errorf("BUDGET_EXHAUSTED: %dms of %dms used; abort the plan", p.BudgetUsedMS, p.Plan.TotalBudget) }
+
+	setPlanStatusLocked(p, PlanRunning, "RESUMED: "+args.Reason)
+	if p.Cursor < len(p.Steps) && p.Steps[p.Cursor].Status == "DISPATCHED" { return wrapForensicResult(*p), nil, nil }
+	dispatchNextStepLocked(p) // Re-runs a failed step with a fresh intent and work order
+	return wrapForensicResult(*p), nil, nil
+}
+
+func abort_strategic_plan(ctx context.Context, req *mcp.CallToolRequest, args PlanControlArgs) (*mcp.CallToolResult, any, error) {
+	planMu.Lock()
+	defer planMu.Unlock()
+	p, err := lookupPlanLocked(args.PlanID)
+	if err != nil { return nil, nil, err }
+	if isPlanTerminal(p.Status) { return nil, nil, fmt.Errorf("PLAN_%s: Plan already finished", p.Status) }
+
+	if p.Cursor < len(p.Steps) && p.Steps[p.Cursor].Status == "DISPATCHED" {
+		st := &p.Steps[p.Cursor]
+		st.Status, st.Error = "FAILED", "PLAN_ABORTED"
+		transitionIntent(st.IntentID, IntentRejected, "PLAN_ABORTED")
+	}
+	if err := rollbackPlanLocked(ctx, p, "PLAN_ABORTED"); err != nil {
+		setPlanStatusLocked(p, PlanPaused, err.Error())
+		return nil, nil, err
+	}
+	setPlanStatusLocked(p, PlanAborted, "ABORTED: "+args.Reason)
+	return wrapForensicResult(*p), nil, nil
+}
+
+func get_plan_status(ctx context.Context, req *mcp.CallToolRequest, args PlanControlArgs) (*mcp.CallToolResult, any, error) {
+	planMu.Lock()
+	defer planMu.Unlock()
+	p, err := lookupPlanLocked(args.PlanID)
+	if err != nil { return nil, nil, err }
+	return wrapForensicResult(*p), nil, nil
+}
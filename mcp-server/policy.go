@@ -0,0 +1,155 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"sync"
+	"time"
+)
+
+// Declarative Governance Policy
+//
+// The intent -> opcode binding, intent class limits, UUID scopes, approval
+// requirements and confidence thresholds live in PolicyFile rather than in
+// code. The file is polled and hot-reloaded; a file that fails to parse is
+// ignored and the last good policy stays in force.
+const PolicyFile = "governance_policy.json"
+
+var (
+	activePolicy  = builtinPolicy()
+	policyModTime time.Time
+	policyMu      sync.RWMutex
+)
+
+// builtinPolicy mirrors the shipped governance_policy.json default so the
+// orchestrator is never without a policy, even if the file is missing.
+func builtinPolicy() GovernancePolicy {
+	return GovernancePolicy{
+		Version: "builtin",
+		Default: PolicyRule{ID: "default.read_only", AllowedOpcodes: []VibeOpcode{OpSystem, OpAudit}, MinConfidence: 0.8},
+	}
+}
+
+func loadPolicy() error {
+	info, err := os.Stat(PolicyFile)
+	if err != nil { return err }
+
+	policyMu.RLock()
+	unchanged := info.ModTime().Equal(policyModTime)
+	policyMu.RUnlock()
+	if unchanged { return nil }
+
+	data, err := os.ReadFile(PolicyFile)
+	if err != nil { return err }
+	var p GovernancePolicy
+	if err := json.Unmarshal(data, &p); err != nil { return fmt.Errorf("POLICY_PARSE_ERROR: %v", err) }
+	if p.Default.ID == "" { return fmt.Errorf("POLICY_INVALID: default rule requires an id") }
+
+	policyMu.Lock()
+	activePolicy, policyModTime = p, info.ModTime()
+	policyMu.Unlock()
+
+	log.Printf("📜 Governance Policy: Loaded %s (%d rules)", p.Version, len(p.Rules))
+	dispatchVibeEvent(LevelInfo, "policy_reloaded", "", "", map[string]interface{}{"version": p.Version, "rules": len(p.Rules)})
+	return nil
+}
+
+func startPolicyWatcher() {
+	ticker := time.NewTicker(2 * time.Second)
+	for range ticker.C {
+		if err := loadPolicy(); err != nil && !os.IsNotExist(err) {
+			log.Printf("🚨 Governance Policy: %v (keeping previous policy)", err)
+		}
+	}
+}
+
+func policyRuleFor(p GovernancePolicy, intent IntentType) PolicyRule {
+	for _, r := range p.Rules {
+		if r.Intent == intent { return r }
+	}
+	return p.Default
+}
+
+// evaluatePolicy decides whether an envelope is admissible under the current
+// policy. An empty class means the class has not been derived yet and the
+// class restriction is not applied.
+func evaluatePolicy(env IntentEnvelope, class IntentClass) PolicyDecision {
+	policyMu.RLock()
+	p := activePolicy
+	policyMu.RUnlock()
+
+	rule := policyRuleFor(p, env.Intent)
+	d := PolicyDecision{RuleID: rule.ID, PolicyVersion: p.Version, Allowed: true, RequiredApprovals: rule.RequiredApprovals, MinConfidence: rule.MinConfidence}
+	if env.Confidence < rule.MinConfidence && d.RequiredApprovals == 0 {
+		d.RequiredApprovals = 1
+		d.Reason = fmt.Sprintf("LOW_CONFIDENCE: %.2f below %.2f", env.Confidence, rule.MinConfidence)
+	}
+
+	if env.Opcode != 0 && !containsOpcode(rule.AllowedOpcodes, env.Opcode) {
+		d.Allowed, d.Reason = false, fmt.Sprintf("INTENT_MISMATCH: Opcode %X not permitted for intent %s", env.Opcode, env.Intent)
+		return d
+	}
+	if class != "" && len(rule.AllowedClasses) > 0 && !containsClass(rule.AllowedClasses, class) {
+		d.Allowed, d.Reason = false, fmt.Sprintf("CLASS_FORBIDDEN: %s operations not permitted for intent %s", class, env.Intent)
+		return d
+	}
+	if len(rule.UUIDScopes) > 0 {
+		for _, id := range env.Scope {
+			if !matchesAnyScope(rule.UUIDScopes, id) {
+				d.Allowed, d.Reason = false, fmt.Sprintf("SCOPE_FORBIDDEN: UUID %s outside rule %s", id, rule.ID)
+				return d
+			}
+		}
+	}
+	return d
+}
+
+func containsOpcode(ops []VibeOpcode, op VibeOpcode) bool {
+	for _, o := range ops { if o == op { return true } }
+	return false
+}
+
+func containsClass(classes []IntentClass, c IntentClass) bool {
+	for _, k := range classes { if k == c { return true } }
+	return false
+}
+
+func matchesAnyScope(patterns []string, id string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, id); ok { return true }
+	}
+	return false
+}
+
+// journalPolicyDecision records which rule produced a decision, so every
+// admission or approval requirement is traceable to the policy text.
+func journalPolicyDecision(intentID, agent, stage string, d PolicyDecision) {
+	journalOperation(map[string]interface{}{
+		"type":               "policy_decision",
+		"intent":             intentID,
+		"agent":              agent,
+		"stage":              stage,
+		"rule_id":            d.RuleID,
+		"policy_version":     d.PolicyVersion,
+		"allowed":            d.Allowed,
+		"required_approvals": d.RequiredApprovals,
+		"reason":             d.Reason,
+	})
+}
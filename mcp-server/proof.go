@@ -0,0 +1,95 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// Cross-Layer Echo Invariance (SECOND_ORDER_INVARIANTS.md §5)
+//
+// A commit is only accepted when three independent observers agree on the
+// resulting state: the hash Blender computes over what it would export, the
+// hash Unity computes while validating its imported scene, and the state/get
+// hash the bridge referee observed while verifying the transaction. The
+// binding over the triplet is an HMAC under a key that never leaves the
+// orchestrator, so only a proof issued by get_bridge_commit_requirements can
+// commit.
+var (
+	refereeHashes = make(map[string]string) // engine -> last hash seen by verifyEngineState
+	refereeMu     sync.Mutex
+
+	proofKey = func() []byte { k := make([]byte, 32); rand.Read(k); return k }()
+)
+
+func recordRefereeHash(target, hash string) {
+	refereeMu.Lock()
+	defer refereeMu.Unlock()
+	refereeHashes[target] = hash
+}
+
+// bridgeVerificationHash is the referee's own view of the converged state. It
+// only exists once both engines have been verified to the same hash.
+func bridgeVerificationHash() string {
+	refereeMu.Lock()
+	defer refereeMu.Unlock()
+	u, b := refereeHashes["unity"], refereeHashes["blender"]
+	if u == "" || u != b { return "" }
+	return u
+}
+
+// proofBinding ties an observed triplet to a single transaction so a proof
+// cannot be replayed to commit a different one, nor assembled without the
+// orchestrator.
+func proofBinding(tid, blender, unity, bridge string) string {
+	m := hmac.New(sha256.New, proofKey)
+	m.Write([]byte(tid + "|" + blender + "|" + unity + "|" + bridge))
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// verifyProofOfWork checks the proof against freshly observed engine state.
+func verifyProofOfWork(tx *VibeTransaction, p ProofOfWork, observed EchoTriplet) error {
+	if tx == nil { return fmt.Errorf("INVARIANT_VIOLATION: No open transaction for ProofOfWork") }
+	if tx.Status == TxQuarantined { return fmt.Errorf("TX_QUARANTINED: Transaction %s failed prefab depth verification", tx.ID) }
+	if p.TransactionID != tx.ID { return fmt.Errorf("PROOF_REJECTED: Proof bound to transaction %q, expected %q", p.TransactionID, tx.ID) }
+	if p.BlenderExportHash == "" || p.UnityImportHash == "" || p.BridgeVerificationHash == "" {
+		return fmt.Errorf("INVARIANT_VIOLATION: ProofOfWork requires blender, unity and bridge hashes")
+	}
+	if !hmac.Equal([]byte(p.Binding), []byte(proofBinding(tx.ID, p.BlenderExportHash, p.UnityImportHash, p.BridgeVerificationHash))) {
+		return fmt.Errorf("PROOF_REJECTED: Binding does not match transaction %s", tx.ID)
+	}
+	if p.BlenderExportHash != observed.BlenderExportHash { return fmt.Errorf("PROOF_STALE: Blender hash %s, observed %q", p.BlenderExportHash, observed.BlenderExportHash) }
+	if p.UnityImportHash != observed.UnityImportHash { return fmt.Errorf("PROOF_STALE: Unity hash %s, observed %q", p.UnityImportHash, observed.UnityImportHash) }
+	if p.BridgeVerificationHash != observed.BridgeVerificationHash { return fmt.Errorf("PROOF_STALE: Bridge hash %s, referee observed %q", p.BridgeVerificationHash, observed.BridgeVerificationHash) }
+	if p.BlenderExportHash != p.UnityImportHash || p.UnityImportHash != p.BridgeVerificationHash {
+		return fmt.Errorf("ECHO_MISMATCH: Blender, Unity and Bridge hashes must be identical")
+	}
+	return nil
+}
+
+// observeCommitState reads each layer through its own channel: Blender's
+// export hash, Unity's post-import validation hash and the referee cache.
+func observeCommitState(ctx context.Context) EchoTriplet {
+	obs := EchoTriplet{BridgeVerificationHash: bridgeVerificationHash()}
+	if b, err := sendToEngine(ctx, "blender", "export", "POST", map[string]interface{}{"hash_only": true}); err == nil && engineRefusal(b) == nil && b["hash"] != nil { obs.BlenderExportHash = fmt.Sprintf("%v", b["hash"]) }
+	if u, err := sendToEngine(ctx, "unity", "validate", "POST", map[string]interface{}{}); err == nil && engineRefusal(u) == nil && u["hash"] != nil { obs.UnityImportHash = fmt.Sprintf("%v", u["hash"]) }
+	return obs
+}
@@ -0,0 +1,386 @@
+// VibeSync: Zero-Trust Unity ↔ Blender Orchestrator
+// Copyright (C) 2026 B-A-M-N
+//
+// This project is distributed under a DUAL-LICENSING MODEL:
+// 1. Open-Source Path: GNU Affero General Public License v3
+// 2. Commercial Path: "Work-or-Pay" Model
+//
+// See the LICENSE file in the project root for the full terms and conditions
+// of both licensing paths.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Cross-Engine Undo/Redo
+//
+// Every committed mutation is recorded as an UndoRecord holding one step per
+// engine call together with the engine's undo token (or an inverse operation
+// when the adapter cannot issue tokens). Mutations issued inside an atomic
+// transaction accumulate into a single record that is only pushed when the
+// transaction commits, so one undo rewinds the whole sync on both engines.
+// Undo and redo are mutations too: they need a grant for the record's intent
+// and pass the perimeter and conflict gates over the scope its claims covered.
+var (
+	undoStack   []*UndoRecord
+	redoStack   []*UndoRecord
+	pendingUndo = make(map[string]*UndoRecord) // tid -> record being assembled
+	undoMu      sync.Mutex
+	undoRunMu   sync.Mutex // Serializes undo/redo; never held with undoMu across engine calls
+)
+
+// newUndoRecord returns the record that mutations for op should be captured
+// into. While a transaction is open, all mutations share its record. agent is
+// the verified holder of the grant that authorized op, and opcode the opcode
+// it was checked for.
+func newUndoRecord(op string, opcode VibeOpcode, intentID, agent string) *UndoRecord {
+	txMu.Lock()
+	tid := ""
+	if activeTransaction != nil {
+		tid = activeTransaction.ID
+		if intentID == "" { intentID = activeTransaction.IntentID }
+		if agent == "" { agent = activeTransaction.Agent }
+	}
+	txMu.Unlock()
+
+	undoMu.Lock()
+	defer undoMu.Unlock()
+	rec := pendingUndo[tid]
+	if rec == nil {
+		rec = &UndoRecord{ID: uuid.New().String(), Op: op, IntentID: intentID, Agent: agent, TransactionID: tid}
+		if tid != "" { pendingUndo[tid] = rec }
+	}
+	if !containsOpcode(rec.Opcodes, opcode) { rec.Opcodes = append(rec.Opcodes, opcode) }
+	return rec
+}
+
+// newDetachedUndoRecord returns a standalone record that never joins the open
+// transaction's record. Batches are sent by the coalescing loop on its own
+// schedule, so whatever transaction happens to be open has no claim on them.
+func newDetachedUndoRecord(op string, opcode VibeOpcode, intentID, agent string) *UndoRecord {
+	return &UndoRecord{ID: uuid.New().String(), Op: op, IntentID: intentID, Agent: agent, Opcodes: []VibeOpcode{opcode}}
+}
+
+// recordClaimLocked folds a claim's scope and class into rec, so an undo or
+// redo is admitted over what the mutation claimed. Caller holds undoMu.
+func recordClaimLocked(rec *UndoRecord, class IntentClass, uuids []string) {
+	for _, id := range uuids {
+		if !containsString(rec.Scope, id) { rec.Scope = append(rec.Scope, id) }
+	}
+	if rec.Class == "" || rec.Class == ClassCosmetic || class == ClassDestructive { rec.Class = class }
+}
+
+// sendMutation forwards a mutating call to an engine and captures how to
+// reverse it into rec.
+func sendMutation(ctx context.Context, rec *UndoRecord, target, endpoint string, data interface{}) (map[string]interface{}, error) {
+	payload, _ := data.(map[string]interface{})
+	targets, err := mutationIdentities(target, payload)
+	if err != nil { return nil, err }
+	res, err := sendToEngine(ctx, target, endpoint, "POST", data)
+	if err == nil { err = engineRefusal(res) }
+	if err != nil { return nil, err }
+
+	step := UndoStep{Engine: target, Endpoint: endpoint, Payload: data, Targets: targets}
+	captureReverse(&step, res)
+	observeHierarchyMutation(target, endpoint, data)
+
+	undoMu.Lock()
+	rec.Steps = append(rec.Steps, step)
+	agent, intentID, tid := rec.Agent, rec.IntentID, rec.TransactionID
+	undoMu.Unlock()
+	journalOperation(map[string]interface{}{
+		"type":      "mutation",
+		"tid":       tid,
+		"agent":     agent,
+		"intent":    intentID,
+		"engine":    target,
+		"endpoint":  endpoint,
+		"record_id": rec.ID,
+		"targets":   step.Targets,
+		"rollback":  WalRoll{UndoToken: step.UndoToken, SnapshotRef: rec.SnapshotRef},
+	})
+	return res, nil
+}
+
+// engineRefusal turns an adapter's {"error": ...} reply into an error. Adapters
+// answer refusals with a JSON body, which attemptSend hands back as a result.
+func engineRefusal(res map[string]interface{}) error {
+	if res != nil && res["error"] != nil { return fmt.Errorf("ENGINE_REFUSED: %v", res["error"]) }
+	return nil
+}
+
+// captureReverse reads the adapter's reversal hint from a mutation response:
+// either an opaque "undo_token" or an explicit "inverse" operation.
+func captureReverse(step *UndoStep, res map[string]interface{}) {
+	if res == nil { return }
+	if tok, ok := res["undo_token"].(string); ok { step.UndoToken = tok }
+	if inv, ok := res["inverse"].(map[string]interface{}); ok {
+		ep, _ := inv["endpoint"].(string)
+		payload, _ := inv["payload"].(map[string]interface{})
+		if ep != "" { step.Inverse = &EngineOp{Endpoint: ep, Payload: payload} }
+	}
+}
+
+// sealUndoRecord pushes a standalone record onto the undo stack. Records that
+// belong to a transaction are sealed by commitTransactionUndo instead.
+func sealUndoRecord(rec *UndoRecord) {
+	if rec.TransactionID != "" { return }
+	undoMu.Lock()
+	defer undoMu.Unlock()
+	pushUndoLocked(rec)
+}
+
+func commitTransactionUndo(tid string) {
+	undoMu.Lock()
+	defer undoMu.Unlock()
+	rec, ok := pendingUndo[tid]
+	if !ok { return }
+	delete(pendingUndo, tid)
+	pushUndoLocked(rec)
+}
+
+func discardTransactionUndo(tid string) {
+	undoMu.Lock()
+	defer undoMu.Unlock()
+	delete(pendingUndo, tid)
+}
+
+func pushUndoLocked(rec *UndoRecord) {
+	if len(rec.Steps) == 0 { return }
+	rec.CommittedAt = time.Now()
+	undoStack = append(undoStack, rec)
+	redoStack = nil // A fresh mutation invalidates the redo branch
+}
+
+func isReversible(rec *UndoRecord) error {
+	for _, s := range rec.Steps {
+		if s.UndoToken == "" && s.Inverse == nil {
+			return fmt.Errorf("NON_REVERSIBLE: %s on %s returned no undo token or inverse (Symmetry Invariance requires human bypass)", s.Endpoint, s.Engine)
+		}
+	}
+	return nil
+}
+
+func applyInverse(ctx context.Context, s UndoStep) error {
+	var res map[string]interface{}
+	var err error
+	if s.UndoToken != "" {
+		res, err = sendToEngine(ctx, s.Engine, "undo", "POST", map[string]interface{}{"undo_token": s.UndoToken})
+	} else {
+		res, err = sendToEngine(ctx, s.Engine, s.Inverse.Endpoint, "POST", s.Inverse.Payload)
+	}
+	markHierarchyStale(s.Engine)
+	if err != nil { return err }
+	return engineRefusal(res)
+}
+
+// rewindRecord applies every inverse in reverse order. If any engine fails,
+// the steps already rewound are replayed so both engines stay consistent; the
+// compensation ignores ctx's deadline, since stopping half-way is worse.
+func rewindRecord(ctx context.Context, rec *UndoRecord) error {
+	if err := isReversible(rec); err != nil { return err }
+	var done []UndoStep
+	for i := len(rec.Steps) - 1; i >= 0; i-- {
+		s := rec.Steps[i]
+		if err := applyInverse(ctx, s); err != nil {
+			for j := len(done) - 1; j >= 0; j-- { sendToEngine(context.WithoutCancel(ctx), done[j].Engine, done[j].Endpoint, "POST", done[j].Payload) }
+			return fmt.Errorf("UNDO_FAILED: %s on %s: %v", s.Endpoint, s.Engine, err)
+		}
+		done = append(done, s)
+	}
+	return nil
+}
+
+// replayRecord re-issues the forward operations, refreshing each step's
+// reversal hint from the new engine response.
+func replayRecord(ctx context.Context, rec *UndoRecord) error {
+	var done []UndoStep
+	for i := range rec.Steps {
+		s := &rec.Steps[i]
+		res, err := sendToEngine(ctx, s.Engine, s.Endpoint, "POST", s.Payload)
+		if err == nil { err = engineRefusal(res) }
+		if err != nil {
+			for j := len(done) - 1; j >= 0; j-- { applyInverse(context.WithoutCancel(ctx), done[j]) }
+			return fmt.Errorf("REDO_FAILED: %s on %s: %v", s.Endpoint, s.Engine, err)
+		}
+		s.UndoToken, s.Inverse = "", nil
+		captureReverse(s, res)
+		done = append(done, *s)
+	}
+	return nil
+}
+
+// popRecord removes rec from stack by identity; new mutations may have been
+// pushed while an undo/redo was talking to the engines.
+func popRecord(stack []*UndoRecord, rec *UndoRecord) []*UndoRecord {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i] == rec { return append(stack[:i], stack[i+1:]...) }
+	}
+	return stack
+}
+
+// authorizeReplay gates an undo or redo of rec like the mutation that
+// produced it: a grant minted for the record's intent that covers its engines,
+// opcodes and scope (or a Human approver naming the record), the creation
+// perimeter, and a conflict claim over the record's scope.
+func authorizeReplay(ctx context.Context, rec *UndoRecord, args UndoArgs, action string) error {
+	undoMu.Lock()
+	id, intentID, class := rec.ID, rec.IntentID, rec.Class
+	scope, opcodes := append([]string(nil), rec.Scope...), append([]VibeOpcode(nil), rec.Opcodes...)
+	var engines []string
+	for _, s := range rec.Steps {
+		if !containsString(engines, s.Engine) { engines = append(engines, s.Engine) }
+	}
+	undoMu.Unlock()
+	if args.RecordID != "" && args.RecordID != id { return fmt.Errorf("RECORD_MISMATCH: %s would replay record %s, not %s", action, id, args.RecordID) }
+	if class == "" { class = ClassStructural } // No claim was recorded; assume the worst
+
+	holder := ""
+	if args.Approver != "" {
+		if args.RecordID == "" { return fmt.Errorf("RECORD_MISMATCH: a human %s must name the record it approves", action) }
+		if err := verifyHuman(args.Approver, args.Signature, action, args); err != nil { return err }
+		holder = args.Approver
+	} else {
+		if intentID == "" || len(opcodes) == 0 { return fmt.Errorf("CAPABILITY_DENIED: record %s belongs to no intent; only a Human approver may %s it", id, action) }
+		for _, op := range opcodes {
+			g, err := checkGrant(args.Grant, engines, op, scope)
+			if err != nil { return err }
+			if g.IntentID != intentID { return fmt.Errorf("CAPABILITY_DENIED: Grant %s was minted for intent %q, not %s", g.ID, g.IntentID, intentID) }
+			holder = g.Holder
+		}
+	}
+	if err := checkPerimeter(class, action); err != nil { return err }
+	return admitMutation(ctx, newConflictClaim(intentID, holder, class, scope, nil, nil))
+}
+
+// topRecord returns the record on top of stack, or nil. Caller holds undoMu.
+func topRecord(stack []*UndoRecord) *UndoRecord {
+	if len(stack) == 0 { return nil }
+	return stack[len(stack)-1]
+}
+
+// The gate runs before undoRunMu is taken: admitting the replay's claim may
+// rewind a losing mutation, which needs undoRunMu itself.
+func undo(ctx context.Context, req *mcp.CallToolRequest, args UndoArgs) (*mcp.CallToolResult, any, error) {
+	undoMu.Lock(); rec := topRecord(undoStack); undoMu.Unlock()
+	if rec == nil { return nil, nil, fmt.Errorf("NOTHING_TO_UNDO") }
+	if err := authorizeReplay(ctx, rec, args, "undo"); err != nil { return nil, nil, err }
+
+	undoRunMu.Lock()
+	defer undoRunMu.Unlock()
+	undoMu.Lock(); moved := topRecord(undoStack) != rec; undoMu.Unlock()
+	if moved { return nil, nil, fmt.Errorf("UNDO_STACK_MOVED: record %s is no longer the newest", rec.ID) }
+
+	updateBridgeActivity("KERNEL: UNDOING_" + rec.Op)
+	defer updateBridgeActivity("KERNEL: READY")
+	if err := rewindRecord(ctx, rec); err != nil {
+		dispatchVibeEvent(LevelError, "undo_failed", rec.IntentID, "HUMAN_REVIEW", map[string]interface{}{"record_id": rec.ID, "error": err.Error()})
+		return nil, nil, err
+	}
+
+	undoMu.Lock()
+	undoStack = popRecord(undoStack, rec)
+	redoStack = append(redoStack, rec)
+	undoMu.Unlock()
+
+	journalOperation(map[string]interface{}{"type": "undo", "record_id": rec.ID, "op": rec.Op, "intent": rec.IntentID, "phase": PhaseRolledBack, "steps": len(rec.Steps)})
+	log.Printf("⏪ Undo: Rewound %s (%d engine steps)", rec.Op, len(rec.Steps))
+	return wrapForensicResult(rec), nil, nil
+}
+
+func redo(ctx context.Context, req *mcp.CallToolRequest, args UndoArgs) (*mcp.CallToolResult, any, error) {
+	undoMu.Lock(); rec := topRecord(redoStack); undoMu.Unlock()
+	if rec == nil { return nil, nil, fmt.Errorf("NOTHING_TO_REDO") }
+	if err := authorizeReplay(ctx, rec, args, "redo"); err != nil { return nil, nil, err }
+
+	undoRunMu.Lock()
+	defer undoRunMu.Unlock()
+	undoMu.Lock(); moved := topRecord(redoStack) != rec; undoMu.Unlock()
+	if moved { return nil, nil, fmt.Errorf("UNDO_STACK_MOVED: record %s is no longer the newest", rec.ID) }
+
+	updateBridgeActivity("KERNEL: REDOING_" + rec.Op)
+	defer updateBridgeActivity("KERNEL: READY")
+	if err := replayRecord(ctx, rec); err != nil {
+		dispatchVibeEvent(LevelError, "redo_failed", rec.IntentID, "HUMAN_REVIEW", map[string]interface{}{"record_id": rec.ID, "error": err.Error()})
+		return nil, nil, err
+	}
+
+	undoMu.Lock()
+	redoStack = popRecord(redoStack, rec)
+	undoStack = append(undoStack, rec)
+	undoMu.Unlock()
+
+	journalOperation(map[string]interface{}{"type": "redo", "record_id": rec.ID, "op": rec.Op, "intent": rec.IntentID, "phase": PhaseFinal, "steps": len(rec.Steps)})
+	log.Printf("⏩ Redo: Reapplied %s (%d engine steps)", rec.Op, len(rec.Steps))
+	return wrapForensicResult(rec), nil, nil
+}
+
+// rewindIntent reverses every undo record an intent produced, newest first,
+// and drops them from the undo stack. Used when a larger unit of work (a
+// strategic plan) is abandoned, so the records are not offered for redo.
+func rewindIntent(ctx context.Context, intentID, reason string) error {
+	undoRunMu.Lock()
+	defer undoRunMu.Unlock()
+
+	undoMu.Lock()
+	var recs []*UndoRecord
+	for i := len(undoStack) - 1; i >= 0; i-- {
+		if undoStack[i].IntentID == intentID { recs = append(recs, undoStack[i]) }
+	}
+	undoMu.Unlock()
+
+	for _, rec := range recs {
+		if err := rewindRecord(ctx, rec); err != nil { return err }
+		undoMu.Lock()
+		undoStack = popRecord(undoStack, rec)
+		undoMu.Unlock()
+		journalOperation(map[string]interface{}{"type": "undo", "record_id": rec.ID, "op": rec.Op, "intent": intentID, "phase": PhaseRolledBack, "steps": len(rec.Steps), "reason": reason})
+	}
+	return nil
+}
+
+// hasUndoRecords reports whether the intent left any record on the undo stack.
+func hasUndoRecords(intentID string) bool {
+	undoMu.Lock()
+	defer undoMu.Unlock()
+	for _, rec := range undoStack {
+		if rec.IntentID == intentID { return true }
+	}
+	return false
+}
+
+// rewindUndoRecord reverses a single committed record by ID and drops it from
+// the undo stack. A record that was never pushed (its mutation had no steps or
+// has not finished) is not an error: there is nothing to rewind yet.
+func rewindUndoRecord(ctx context.Context, recordID, reason string) error {
+	undoRunMu.Lock()
+	defer undoRunMu.Unlock()
+
+	undoMu.Lock()
+	var rec *UndoRecord
+	for i := len(undoStack) - 1; i >= 0 && rec == nil; i-- {
+		if undoStack[i].ID == recordID { rec = undoStack[i] }
+	}
+	undoMu.Unlock()
+	if rec == nil { return nil }
+
+	if err := rewindRecord(ctx, rec); err != nil { return err }
+	undoMu.Lock()
+	undoStack = popRecord(undoStack, rec)
+	undoMu.Unlock()
+	journalOperation(map[string]interface{}{"type": "undo", "record_id": rec.ID, "op": rec.Op, "intent": rec.IntentID, "phase": PhaseRolledBack, "steps": len(rec.Steps), "reason": reason})
+	return nil
+}
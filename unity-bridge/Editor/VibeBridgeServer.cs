@@ -41,17 +41,85 @@ public static class VibeBridgeServer
 
     private static readonly HashSet<string> _pathWhitelist = new HashSet<string> {
         "/health", "/handshake", "/metrics", "/object/lock", "/panic", 
-        "/validate", "/state/get", "/commit", "/rollback", 
+        "/validate", "/preflight/run", "/state/get", "/commit", "/rollback", 
         "/transform/set", "/material/update", "/object/mutate",
-        "/selection/set", "/camera/set", "/camera/get"
+        "/selection/set", "/camera/set", "/camera/get", "/batch/apply", "/locks/sync", "/hierarchy/get",
+        "/object/delete", "/undo"
     };
 
     [Serializable]
     private class HandshakePayload { public string new_token; public string challenge; }
 
+    [Serializable]
+    private class PreflightPayload { public string[] uuids; }
+
+    [Serializable]
+    private class TransformData { public float[] pos; public float[] rot; public float[] sca; }
+
+    [Serializable]
+    private class MaterialProps { public float[] color; public float roughness = -1f; public float metallic = -1f; }
+
+    [Serializable]
+    private class MutationPayload { public string id; public TransformData transform; public MaterialProps properties; }
+
+    [Serializable]
+    private class BatchOp { public string endpoint; public MutationPayload payload; }
+
+    [Serializable]
+    private class BatchPayload { public BatchOp[] ops; }
+
+    [Serializable]
+    private class UndoPayload { public string undo_token; }
+
+    [Serializable]
+    private class DeletePayload { public string[] uuids; public bool atomic; }
+
+    [Serializable]
+    private class LockFlag { public string uuid; public string type; public string owner; public string label; public string via; }
+
+    [Serializable]
+    private class LockSyncPayload { public string digest; public LockFlag[] locks; }
+
+    // Lock flags mirrored from the orchestrator's lock table. Each /locks/sync
+    // replaces them whole; the digest is echoed on /health so the orchestrator
+    // can tell when they are stale. Guarded by _stateLock.
+    private static Dictionary<string, LockFlag> _lockFlags = new Dictionary<string, LockFlag>();
+    private static string _lockDigest = "";
+
+    // Undo journal: token -> how to put the scene back. Only touched on the
+    // main thread.
+    private static readonly Dictionary<string, Action> _undoJournal = new Dictionary<string, Action>();
+    private static int _undoSeq = 0;
+
+    // Mutations answered synchronously with an undo token. Each applies its
+    // payload on the main thread and returns the action that reverses it.
+    private static readonly Dictionary<string, Func<string, Action>> _mutations = new Dictionary<string, Func<string, Action>> {
+        { "/transform/set", body => ApplyTransform(JsonUtility.FromJson<MutationPayload>(body)) },
+        { "/material/update", body => ApplyMaterial(JsonUtility.FromJson<MutationPayload>(body)) },
+        { "/batch/apply", body => ApplyBatch(JsonUtility.FromJson<BatchPayload>(body)) },
+        { "/object/delete", body => ApplyDelete(JsonUtility.FromJson<DeletePayload>(body)) },
+    };
+
+    // Editor activity (CONFLICT_RESOLUTION_POLICY.md §3.D): what the human is
+    // doing is reported to the orchestrator's control plane, which holds a 5s
+    // HUMAN_ACTIVE lease per report. Ongoing activity is re-reported inside
+    // that lease; once the human has been quiet for ActivityIdle the objects
+    // are reported idle. Reports go out in order from one worker thread.
+    private const string ActivityUrl = "http://127.0.0.1:8080/editor/activity";
+    private const double ActivityRefresh = 2.0;
+    private const double ActivityIdle = 2.0;
+    private static string _activityKind = "idle";
+    private static string[] _activityIds = new string[0];
+    private static double _activitySent, _activitySeen;
+    private static readonly BlockingCollection<string> _activityOutbox = new BlockingCollection<string>();
+
     static VibeBridgeServer()
     {
         EditorApplication.update += OnUpdate;
+        EditorApplication.hierarchyWindowItemOnGUI += DrawLockFlag;
+        Selection.selectionChanged += OnSelectionChanged;
+        Undo.postprocessModifications += OnPropertyModifications;
+        new Thread(ActivityWorker) { IsBackground = true }.Start();
         StartServer();
     }
 
@@ -146,8 +214,8 @@ public static class VibeBridgeServer
         if (request.Url.AbsolutePath == "/health")
         {
             string status = (EditorApplication.isCompiling || EditorApplication.isUpdating) ? "busy" : "ok";
-            int gen; lock(_stateLock) { gen = _currentGeneration; }
-            string responseJson = "{\"status\":\"" + status + "\", \"generation\":" + gen + "}";
+            int gen; string digest; lock(_stateLock) { gen = _currentGeneration; digest = _lockDigest; }
+            string responseJson = "{\"status\":\"" + status + "\", \"generation\":" + gen + ", \"lock_digest\":" + JsonString(digest) + "}";
             SendResponse(response, responseJson, HttpStatusCode.OK);
             return;
         }
@@ -271,16 +339,37 @@ public static class VibeBridgeServer
         // Atomic Sync Endpoints
         if (request.Url.AbsolutePath == "/validate")
         {
-            string sceneState = "UnityState_" + UnityEngine.SceneManagement.SceneManager.GetActiveScene().name; 
-            string hash = "UNITY_HASH_" + sceneState.GetHashCode();
-            string responseJson = "{\"status\":\"OK\", \"hash\":\"" + hash + "\"}";
-            SendResponse(response, responseJson, HttpStatusCode.OK);
+            SendResponse(response, RunOnMainThread(() => "{\"status\":\"OK\", \"hash\":\"" + SceneHash() + "\"}"), HttpStatusCode.OK);
             return;
         }
         
+        if (request.Url.AbsolutePath == "/preflight/run")
+        {
+            // Dry run only: confirm every UUID resolves and report the scene hash
+            // the real run would start from. Nothing is mutated.
+            var payload = JsonUtility.FromJson<PreflightPayload>(body);
+            SendResponse(response, RunOnMainThread(() => {
+                foreach (var id in payload?.uuids ?? new string[0])
+                {
+                    if (FindByVibeId(id) == null) return "{\"error\":" + JsonString("Unknown UUID: " + id) + "}";
+                }
+                return "{\"status\":\"OK\", \"hash\":\"" + SceneHash() + "\"}";
+            }), HttpStatusCode.OK);
+            return;
+        }
+
         if (request.Url.AbsolutePath == "/state/get")
         {
-            SendResponse(response, "{\"hash\":\"SCENE_HASH_STABLE\"}", HttpStatusCode.OK);
+            SendResponse(response, RunOnMainThread(() => {
+                var identities = SceneObjects().Select(go => "{" + IdentityFields(go) + "}");
+                return "{\"hash\":\"" + SceneHash() + "\", \"identities\":[" + string.Join(",", identities) + "]}";
+            }), HttpStatusCode.OK);
+            return;
+        }
+
+        if (request.Url.AbsolutePath == "/hierarchy/get")
+        {
+            SendResponse(response, RunOnMainThread(HierarchyJson), HttpStatusCode.OK);
             return;
         }
 
@@ -296,27 +385,434 @@ public static class VibeBridgeServer
             return;
         }
 
+        if (request.Url.AbsolutePath == "/locks/sync")
+        {
+            var payload = JsonUtility.FromJson<LockSyncPayload>(body);
+            var flags = new Dictionary<string, LockFlag>();
+            foreach (var f in payload?.locks ?? new LockFlag[0])
+            {
+                // A direct lock outranks the same object covered through an ancestor
+                if (!flags.ContainsKey(f.uuid) || string.IsNullOrEmpty(f.via)) flags[f.uuid] = f;
+            }
+            string digest = payload?.digest ?? "";
+            lock (_stateLock) { _lockFlags = flags; _lockDigest = digest; }
+            _mainThreadQueue.Enqueue(EditorApplication.RepaintHierarchyWindow);
+            SendResponse(response, "{\"status\":\"ok\", \"digest\":" + JsonString(digest) + "}", HttpStatusCode.OK);
+            return;
+        }
+
+        if (request.Url.AbsolutePath == "/undo")
+        {
+            var payload = JsonUtility.FromJson<UndoPayload>(body);
+            SendResponse(response, RunOnMainThread(() => {
+                string token = payload?.undo_token ?? "";
+                if (!_undoJournal.TryGetValue(token, out var restore)) return "{\"error\":" + JsonString("Unknown undo token: " + token) + "}";
+                restore();
+                _undoJournal.Remove(token);
+                return "{\"status\":\"undone\"}";
+            }), HttpStatusCode.OK);
+            return;
+        }
+
+        if (_mutations.TryGetValue(request.Url.AbsolutePath, out var mutate))
+        {
+            string path = request.Url.AbsolutePath;
+            SendResponse(response, RunOnMainThread(() => {
+                Debug.Log($"VibeSync Command received on Main Thread: {path}");
+                string token = "unity-" + (++_undoSeq);
+                _undoJournal[token] = mutate(body);
+                return "{\"status\":\"ok\", \"undo_token\":\"" + token + "\"}";
+            }), HttpStatusCode.OK);
+            return;
+        }
+
         // Marshal other requests to the main thread
         _mainThreadQueue.Enqueue(() => HandleEngineCommand(request.Url.AbsolutePath, body));
 
         SendResponse(response, "{\"status\":\"queued\"}", HttpStatusCode.Accepted);
     }
 
+    // Runs fn on the editor main thread and waits for its JSON reply; the
+    // listener thread never touches the scene itself.
+    private static string RunOnMainThread(Func<string> fn, int timeoutMs = 5000)
+    {
+        string result = null;
+        Exception error = null;
+        var done = new ManualResetEventSlim(false);
+        _mainThreadQueue.Enqueue(() => {
+            try { result = fn(); }
+            catch (Exception e) { error = e; }
+            finally { done.Set(); }
+        });
+        if (!done.Wait(timeoutMs)) return "{\"error\":\"Engine Busy: Main Thread Timeout\"}";
+        if (error != null) return "{\"error\":" + JsonString(error.Message) + "}";
+        return result;
+    }
+
+    private static string JsonString(string s)
+    {
+        var sb = new StringBuilder("\"");
+        foreach (char c in s ?? "")
+        {
+            switch (c)
+            {
+                case '"': sb.Append("\\\""); break;
+                case '\\': sb.Append("\\\\"); break;
+                case '\n': sb.Append("\\n"); break;
+                case '\r': sb.Append("\\r"); break;
+                case '\t': sb.Append("\\t"); break;
+                default:
+                    if (c < 0x20) sb.Append("\\u").Append(((int)c).ToString("x4"));
+                    else sb.Append(c);
+                    break;
+            }
+        }
+        return sb.Append('"').ToString();
+    }
+
+    // Every GameObject in the active scene, inactive ones included. Objects are
+    // addressed by name, matching the Blender adapter's UUIDs. Hidden roots are
+    // deleted objects kept for undo and are not part of the scene.
+    private static IEnumerable<GameObject> SceneObjects()
+    {
+        var scene = UnityEngine.SceneManagement.SceneManager.GetActiveScene();
+        foreach (var root in scene.GetRootGameObjects())
+            if ((root.hideFlags & HideFlags.HideInHierarchy) == 0)
+                foreach (var t in root.GetComponentsInChildren<Transform>(true))
+                yield return t.gameObject;
+    }
+
+    private static GameObject FindByVibeId(string id)
+    {
+        return SceneObjects().FirstOrDefault(g => g.name == id);
+    }
+
+    // The canonical scene hash both adapters compute: sha256 over the sorted
+    // "uuid|parent" lines. Transforms are left out; their bases differ.
+    private static string SceneHash()
+    {
+        var lines = SceneObjects().Select(g => g.name + "|" + (g.transform.parent != null ? g.transform.parent.name : "") + "\n");
+        var sb = new StringBuilder();
+        foreach (var line in lines.OrderBy(l => l, StringComparer.Ordinal)) sb.Append(line);
+        using (var sha = SHA256.Create())
+        {
+            return BitConverter.ToString(sha.ComputeHash(Encoding.UTF8.GetBytes(sb.ToString()))).Replace("-", "").ToLower();
+        }
+    }
+
+    // An object's prefab identity as the orchestrator's ObjectKind: objects of
+    // a prefab asset are PREFAB_DEF, objects of a prefab instance in the scene
+    // are PREFAB_INSTANCE at the depth of their source chain (1 for a plain
+    // instance, one more per nested prefab), anything else OBJECT at depth 0.
+    private static KeyValuePair<string, int> PrefabIdentity(GameObject go)
+    {
+        if (PrefabUtility.IsPartOfPrefabAsset(go)) return new KeyValuePair<string, int>("PREFAB_DEF", 0);
+        if (!PrefabUtility.IsPartOfPrefabInstance(go)) return new KeyValuePair<string, int>("OBJECT", 0);
+        int depth = 0;
+        for (var src = PrefabUtility.GetCorrespondingObjectFromSource(go); src != null; src = PrefabUtility.GetCorrespondingObjectFromSource(src)) depth++;
+        return new KeyValuePair<string, int>("PREFAB_INSTANCE", depth);
+    }
+
+    // The identity fields every report carries for an object: its uuid, kind
+    // and prefab depth.
+    private static string IdentityFields(GameObject go)
+    {
+        var id = PrefabIdentity(go);
+        return "\"uuid\":" + JsonString(go.name) + ",\"kind\":" + JsonString(id.Key) + ",\"prefab_depth\":" + id.Value;
+    }
+
+    // Objects a component depends on: constraint sources and joint bodies.
+    private static IEnumerable<GameObject> ComponentTargets(Component c)
+    {
+        if (c is UnityEngine.Animations.IConstraint constraint)
+        {
+            for (int i = 0; i < constraint.sourceCount; i++)
+            {
+                var src = constraint.GetSource(i).sourceTransform;
+                if (src != null) yield return src.gameObject;
+            }
+        }
+        else if (c is Joint joint && joint.connectedBody != null)
+        {
+            yield return joint.connectedBody.gameObject;
+        }
+    }
+
+    // Parent edges for the orchestrator's central DAG mirror, with each
+    // object's prefab identity and the constraints and joints elsewhere in the
+    // scene that target it ("<owner>/constraint/<Type>", "<owner>/joint/<Type>").
+    private static string HierarchyJson()
+    {
+        var objects = SceneObjects().ToList();
+        var dependents = new Dictionary<string, SortedSet<string>>();
+        foreach (var go in objects)
+        {
+            foreach (var c in go.GetComponents<Component>())
+            {
+                if (c == null) continue; // Missing script
+                string kind = c is Joint ? "joint" : "constraint";
+                foreach (var target in ComponentTargets(c))
+                {
+                    if (target == go) continue;
+                    if (!dependents.TryGetValue(target.name, out var set)) dependents[target.name] = set = new SortedSet<string>(StringComparer.Ordinal);
+                    set.Add(go.name + "/" + kind + "/" + c.GetType().Name);
+                }
+            }
+        }
+        var nodes = objects.Select(go => {
+            var sb = new StringBuilder("{").Append(IdentityFields(go))
+                .Append(",\"parent\":").Append(JsonString(go.transform.parent != null ? go.transform.parent.name : ""));
+            if (dependents.TryGetValue(go.name, out var deps)) sb.Append(",\"dependents\":[").Append(string.Join(",", deps.Select(JsonString))).Append("]");
+            return sb.Append("}").ToString();
+        });
+        return "{\"nodes\":[" + string.Join(",", nodes) + "]}";
+    }
+
+    private static GameObject RequireObject(string id)
+    {
+        var go = FindByVibeId(id);
+        if (go == null) throw new InvalidOperationException("Unknown UUID: " + id);
+        return go;
+    }
+
+    private static Vector3 ToVector3(float[] v) { return new Vector3(v[0], v[1], v[2]); }
+
+    private static Action ApplyTransform(MutationPayload p)
+    {
+        var t = RequireObject(p?.id).transform;
+        Vector3 pos = t.localPosition, rot = t.localEulerAngles, sca = t.localScale;
+        Undo.RecordObject(t, "VibeSync Transform");
+        var d = p.transform;
+        if (d?.pos != null && d.pos.Length == 3) t.localPosition = ToVector3(d.pos);
+        if (d?.rot != null && d.rot.Length == 3) t.localEulerAngles = ToVector3(d.rot);
+        if (d?.sca != null && d.sca.Length == 3) t.localScale = ToVector3(d.sca);
+        return () => {
+            Undo.RecordObject(t, "VibeSync Undo");
+            t.localPosition = pos; t.localEulerAngles = rot; t.localScale = sca;
+        };
+    }
+
+    private static Action ApplyMaterial(MutationPayload p)
+    {
+        var renderer = RequireObject(p?.id).GetComponent<Renderer>();
+        if (renderer == null || renderer.sharedMaterial == null) throw new InvalidOperationException("No material on " + p.id);
+        var mat = renderer.sharedMaterial;
+        var before = new Material(mat);
+        Undo.RecordObject(mat, "VibeSync Material");
+        var props = p.properties;
+        if (props?.color != null && props.color.Length >= 3 && mat.HasProperty("_Color"))
+            mat.color = new Color(props.color[0], props.color[1], props.color[2], props.color.Length > 3 ? props.color[3] : 1f);
+        if (props != null && props.roughness >= 0 && mat.HasProperty("_Glossiness")) mat.SetFloat("_Glossiness", 1f - props.roughness);
+        if (props != null && props.metallic >= 0 && mat.HasProperty("_Metallic")) mat.SetFloat("_Metallic", props.metallic);
+        return () => {
+            Undo.RecordObject(mat, "VibeSync Undo");
+            mat.CopyPropertiesFromMaterial(before);
+        };
+    }
+
+    // A batch is all-or-nothing: if one op fails, the ops already applied are
+    // reversed before the error is reported.
+    private static Action ApplyBatch(BatchPayload batch)
+    {
+        var applied = new List<Action>();
+        Action rewind = () => { for (int i = applied.Count - 1; i >= 0; i--) applied[i](); };
+        try
+        {
+            foreach (var op in batch?.ops ?? new BatchOp[0])
+            {
+                string path = "/" + (op.endpoint ?? "").TrimStart('/');
+                if (path == "/batch/apply" || path == "/object/delete" || !_mutations.TryGetValue(path, out var mutate))
+                    throw new InvalidOperationException("Not batchable: " + op.endpoint);
+                applied.Add(mutate(JsonUtility.ToJson(op.payload)));
+            }
+        }
+        catch
+        {
+            rewind();
+            throw;
+        }
+        return rewind;
+    }
+
+    // Deletes a closure atomically (PATHOLOGICAL_EDGE_CASES.md §3). Every id is
+    // resolved before anything changes: objects by name, dependents as
+    // "<owner>/constraint|joint/<Type>". Dependent components are copied onto
+    // a hidden holder and destroyed; objects are detached to the scene root,
+    // deactivated and hidden, so undo puts back the same objects, prefab links
+    // and references included. Hidden objects are never saved with the scene.
+    private static Action ApplyDelete(DeletePayload p)
+    {
+        var ids = p?.uuids ?? new string[0];
+        var components = new List<KeyValuePair<string, Component>>();
+        var objects = new List<GameObject>();
+        foreach (var id in ids)
+        {
+            var parts = id.Split('/');
+            if (parts.Length == 3) components.Add(new KeyValuePair<string, Component>(id, RequireDependent(parts[0], parts[1], parts[2])));
+            else objects.Add(RequireObject(id));
+        }
+        // Children go with their parent; only the topmost members are detached.
+        var roots = objects.Where(go => !objects.Any(o => o != go && go.transform.IsChildOf(o.transform))).ToList();
+
+        var applied = new List<Action>();
+        Action rewind = () => { for (int i = applied.Count - 1; i >= 0; i--) applied[i](); };
+        try
+        {
+            foreach (var c in components) applied.Add(DeleteComponent(c.Key, c.Value));
+            foreach (var go in roots) applied.Add(HideObject(go));
+        }
+        catch
+        {
+            rewind();
+            throw;
+        }
+        return rewind;
+    }
+
+    private static Component RequireDependent(string owner, string kind, string type)
+    {
+        var c = RequireObject(owner).GetComponents<Component>().FirstOrDefault(x => x != null && x.GetType().Name == type
+            && (kind == "joint" ? x is Joint : x is UnityEngine.Animations.IConstraint));
+        if (c == null) throw new InvalidOperationException("Unknown dependent: " + owner + "/" + kind + "/" + type);
+        return c;
+    }
+
+    private static Action DeleteComponent(string id, Component c)
+    {
+        string owner = c.gameObject.name;
+        var type = c.GetType();
+        var holder = new GameObject("VibeSync Deleted " + id) { hideFlags = HideFlags.HideAndDontSave };
+        var copy = holder.AddComponent(type);
+        EditorUtility.CopySerialized(c, copy);
+        UnityEngine.Object.DestroyImmediate(c);
+        return () => {
+            // The owner is looked up again: it may itself have been restored.
+            var back = RequireObject(owner).AddComponent(type);
+            EditorUtility.CopySerialized(copy, back);
+            UnityEngine.Object.DestroyImmediate(holder);
+        };
+    }
+
+    private static Action HideObject(GameObject go)
+    {
+        var t = go.transform;
+        var parent = t.parent;
+        int sibling = t.GetSiblingIndex();
+        bool active = go.activeSelf;
+        t.SetParent(null, false);
+        go.SetActive(false);
+        go.hideFlags = HideFlags.HideAndDontSave;
+        return () => {
+            go.hideFlags = HideFlags.None;
+            t.SetParent(parent, false);
+            t.SetSiblingIndex(sibling);
+            go.SetActive(active);
+        };
+    }
+
     private static void HandleEngineCommand(string path, string json)
     {
         Debug.Log($"VibeSync Command received on Main Thread: {path}");
         
-        if (path == "/transform/set")
+        if (path == "/object/mutate")
         {
-            Debug.Log("🛡️ VibeSync: Applying Transform Sync...");
+            Debug.Log("🛡️ VibeSync: Executing Mutation...");
         }
-        else if (path == "/material/update")
+    }
+
+    // Shows the mirrored lock next to the object in the Hierarchy window.
+    private static void DrawLockFlag(int instanceId, Rect row)
+    {
+        var go = EditorUtility.InstanceIDToObject(instanceId) as GameObject;
+        if (go == null) return;
+        LockFlag flag;
+        lock (_stateLock) { if (!_lockFlags.TryGetValue(go.name, out flag)) return; }
+        string text = "🔒 " + flag.label + (string.IsNullOrEmpty(flag.owner) ? "" : " (" + flag.owner + ")");
+        var style = EditorStyles.miniLabel;
+        float width = style.CalcSize(new GUIContent(text)).x;
+        GUI.Label(new Rect(row.xMax - width, row.y, width, row.height), text, style);
+    }
+
+    private static void OnSelectionChanged()
+    {
+        var ids = Selection.gameObjects.Select(g => g.name).ToArray();
+        if (ids.Length > 0) NoteActivity("selection", ids);
+    }
+
+    // Inspector edits and scene gizmos both land here; script assignments
+    // (the bridge's own mutations) do not. Transform changes are gizmo drags,
+    // anything else on an object or its material is a property edit.
+    private static UndoPropertyModification[] OnPropertyModifications(UndoPropertyModification[] mods)
+    {
+        var dragged = new HashSet<string>();
+        var edited = new HashSet<string>();
+        foreach (var mod in mods)
         {
-            Debug.Log("🛡️ VibeSync: Applying Material Sync...");
+            var target = mod.currentValue?.target;
+            if (target is Transform t) dragged.Add(t.gameObject.name);
+            else if (target is Component c) edited.Add(c.gameObject.name);
+            else if (target is GameObject g) edited.Add(g.name);
+            else if (target is Material m)
+            {
+                foreach (var go in SceneObjects())
+                {
+                    var r = go.GetComponent<Renderer>();
+                    if (r != null && r.sharedMaterials.Contains(m)) edited.Add(go.name);
+                }
+            }
         }
-        else if (path == "/object/mutate")
+        if (dragged.Count > 0 || edited.Count > 0)
+            NoteActivity(dragged.Count > 0 ? "gizmo_drag" : "property_edit", dragged.Union(edited).ToArray());
+        return mods;
+    }
+
+    // Reports activity on ids, re-reporting unchanged activity only once per
+    // ActivityRefresh. Objects the human has moved away from are released.
+    private static void NoteActivity(string kind, string[] ids)
+    {
+        double now = EditorApplication.timeSinceStartup;
+        ids = ids.Distinct().OrderBy(id => id, StringComparer.Ordinal).ToArray();
+        var left = _activityIds.Except(ids).ToArray();
+        if (_activityKind != "idle" && left.Length > 0) PostActivity("idle", left);
+        if (kind != _activityKind || !ids.SequenceEqual(_activityIds) || now - _activitySent >= ActivityRefresh)
         {
-            Debug.Log("🛡️ VibeSync: Executing Mutation...");
+            PostActivity(kind, ids);
+            _activitySent = now;
+        }
+        _activityKind = kind; _activityIds = ids; _activitySeen = now;
+    }
+
+    private static void TickActivity()
+    {
+        if (_activityKind == "idle" || EditorApplication.timeSinceStartup - _activitySeen < ActivityIdle) return;
+        PostActivity("idle", _activityIds);
+        _activityKind = "idle"; _activityIds = new string[0];
+    }
+
+    private static void PostActivity(string kind, string[] ids)
+    {
+        _activityOutbox.Add("{\"engine\":\"unity\",\"kind\":" + JsonString(kind) + ",\"uuids\":[" + string.Join(",", ids.Select(JsonString)) + "],\"user\":" + JsonString(Environment.UserName) + "}");
+    }
+
+    // The control plane authenticates the adapter by its current session token.
+    private static void ActivityWorker()
+    {
+        foreach (var body in _activityOutbox.GetConsumingEnumerable())
+        {
+            string token;
+            lock (_stateLock) { token = _sessionToken != "" ? _sessionToken : BOOTSTRAP_TOKEN; }
+            try
+            {
+                var req = (HttpWebRequest)WebRequest.Create(ActivityUrl);
+                req.Method = "POST";
+                req.ContentType = "application/json";
+                req.Timeout = 2000;
+                req.Headers["X-Vibe-Token"] = token;
+                byte[] data = Encoding.UTF8.GetBytes(body);
+                using (var stream = req.GetRequestStream()) stream.Write(data, 0, data.Length);
+                using (req.GetResponse()) { }
+            }
+            catch (Exception e) { Debug.LogWarning($"VibeSync: Editor activity not reported: {e.Message}"); }
         }
     }
 
@@ -339,6 +835,7 @@ public static class VibeBridgeServer
             try { action(); } 
             catch (Exception e) { Debug.LogError($"VibeSync Main Thread Error: {e}"); }
         }
+        TickActivity();
     }
 }
 #endif
\ No newline at end of file